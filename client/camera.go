@@ -0,0 +1,66 @@
+package main
+
+import (
+	"CircleWar/config"
+	"CircleWar/core/geom"
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const tileSize = 100
+
+// camera with target in the middle of the window
+func newCamera(target rl.Vector2) rl.Camera2D {
+	return rl.Camera2D{
+		Offset: rl.NewVector2(float32(cfg.CameraWidth)/2, float32(cfg.CameraHeight)/2),
+		Target: target,
+		Zoom:   1,
+	}
+}
+
+// keeps the view inside the world, a world smaller than the view is centered
+func clampCamera(cam *rl.Camera2D, worldWidth, worldHeight float32) {
+	cam.Target.X = clampCameraAxis(cam.Target.X, cam.Offset.X/cam.Zoom, worldWidth)
+	cam.Target.Y = clampCameraAxis(cam.Target.Y, cam.Offset.Y/cam.Zoom, worldHeight)
+}
+
+func clampCameraAxis(target, halfView, worldSize float32) float32 {
+	if 2*halfView >= worldSize {
+		return worldSize / 2
+	}
+	return max(halfView, min(target, worldSize-halfView))
+}
+
+func followCamera(cam *rl.Camera2D, pos geom.Vector2) {
+	cam.Target = rl.Vector2(pos)
+	clampCamera(cam, config.Current().WorldWidth, config.Current().WorldHeight)
+}
+
+// the part of the world the camera shows
+func visibleRect(cam rl.Camera2D) rl.Rectangle {
+	topLeft := rl.GetScreenToWorld2D(rl.Vector2{}, cam)
+	return rl.Rectangle{
+		X: topLeft.X, Y: topLeft.Y,
+		Width: float32(cfg.CameraWidth) / cam.Zoom, Height: float32(cfg.CameraHeight) / cam.Zoom,
+	}
+}
+
+// draws the checkerboard tiles of the world that are inside visible
+func drawFloor(visible rl.Rectangle) {
+	gameplay := config.Current()
+	lastX := int32(math.Ceil(float64(gameplay.WorldWidth/tileSize))) - 1
+	lastY := int32(math.Ceil(float64(gameplay.WorldHeight/tileSize))) - 1
+	fromX := max(0, int32(visible.X/tileSize))
+	fromY := max(0, int32(visible.Y/tileSize))
+	toX := min(lastX, int32((visible.X+visible.Width)/tileSize))
+	toY := min(lastY, int32((visible.Y+visible.Height)/tileSize))
+
+	for x := fromX; x <= toX; x++ {
+		for y := fromY; y <= toY; y++ {
+			if (x+y)%2 == 0 {
+				rl.DrawRectangle(x*tileSize, y*tileSize, tileSize, tileSize, rl.Brown)
+			}
+		}
+	}
+}
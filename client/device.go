@@ -0,0 +1,57 @@
+package main
+
+import (
+	"CircleWar/client/input"
+	"CircleWar/core/geom"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// input.Device reading raylib, the first gamepad and the mouse through camera
+type raylibDevice struct {
+	camera *rl.Camera2D
+}
+
+func (d raylibDevice) KeyDown(key input.Key) bool {
+	return rl.IsKeyDown(int32(key))
+}
+
+func (d raylibDevice) MouseButtonDown(button input.MouseButton) bool {
+	return rl.IsMouseButtonDown(rl.MouseButton(button))
+}
+
+func (d raylibDevice) Cursor() geom.Vector2 {
+	return geom.Vector2(rl.GetScreenToWorld2D(rl.GetMousePosition(), *d.camera))
+}
+
+func (d raylibDevice) MouseDelta() geom.Vector2 {
+	return geom.Vector2(rl.GetMouseDelta())
+}
+
+// raylib's triggers rest at -1, input wants them at 0
+func trigger(gamepad, axis int32) float32 {
+	return (rl.GetGamepadAxisMovement(gamepad, axis) + 1) / 2
+}
+
+func (d raylibDevice) Gamepad() (input.GamepadState, bool) {
+	const gamepad = 0
+	if !rl.IsGamepadAvailable(gamepad) {
+		return input.GamepadState{}, false
+	}
+	return input.GamepadState{
+		LeftStick: geom.NewVector(
+			rl.GetGamepadAxisMovement(gamepad, rl.GamepadAxisLeftX),
+			rl.GetGamepadAxisMovement(gamepad, rl.GamepadAxisLeftY),
+		),
+		RightStick: geom.NewVector(
+			rl.GetGamepadAxisMovement(gamepad, rl.GamepadAxisRightX),
+			rl.GetGamepadAxisMovement(gamepad, rl.GamepadAxisRightY),
+		),
+		LeftTrigger:  trigger(gamepad, rl.GamepadAxisLeftTrigger),
+		RightTrigger: trigger(gamepad, rl.GamepadAxisRightTrigger),
+	}, true
+}
+
+func (d raylibDevice) GamepadButtonDown(button input.GamepadButton) bool {
+	return rl.IsGamepadButtonDown(0, int32(button))
+}
@@ -0,0 +1,301 @@
+package input
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type Action int
+
+const (
+	MoveUp Action = iota
+	MoveDown
+	MoveLeft
+	MoveRight
+	Shoot
+	Scoreboard
+	Chat
+	Ability
+	NextWeapon
+	Weapon1
+	Weapon2
+	Weapon3
+	Weapon4
+	// spectators watch the next player
+	FollowNext
+)
+
+// every action in the order the settings screen lists them
+var Actions = []Action{
+	MoveUp, MoveDown, MoveLeft, MoveRight, Shoot, Scoreboard, Chat, Ability,
+	NextWeapon, Weapon1, Weapon2, Weapon3, Weapon4, FollowNext,
+}
+
+// actions that pick a slot of the loadout
+var weaponSlots = map[Action]uint32{Weapon1: 0, Weapon2: 1, Weapon3: 2, Weapon4: 3}
+
+var actionNames = map[Action]string{
+	MoveUp:     "move_up",
+	MoveDown:   "move_down",
+	MoveLeft:   "move_left",
+	MoveRight:  "move_right",
+	Shoot:      "shoot",
+	Scoreboard: "scoreboard",
+	Chat:       "chat",
+	Ability:    "ability",
+	NextWeapon: "next_weapon",
+	Weapon1:    "weapon_1",
+	Weapon2:    "weapon_2",
+	Weapon3:    "weapon_3",
+	Weapon4:    "weapon_4",
+	FollowNext: "follow_next",
+}
+
+func (a Action) String() string {
+	return actionNames[a]
+}
+
+type GamepadButton int32
+
+// raylib's button codes
+const (
+	PadDpadUp GamepadButton = iota + 1
+	PadDpadRight
+	PadDpadDown
+	PadDpadLeft
+	PadY
+	PadB
+	PadA
+	PadX
+	PadLeftBumper
+	PadLeftTrigger
+	PadRightBumper
+	PadRightTrigger
+	PadBack
+	PadGuide
+	PadStart
+	PadLeftStick
+	PadRightStick
+)
+
+const (
+	MouseRight  MouseButton = 1
+	MouseMiddle MouseButton = 2
+)
+
+type BindingKind int
+
+const (
+	KeyBinding BindingKind = iota
+	MouseBinding
+	PadBinding
+)
+
+var kindNames = map[BindingKind]string{
+	KeyBinding:   "key",
+	MouseBinding: "mouse",
+	PadBinding:   "pad",
+}
+
+// a key, mouse button or gamepad button, Code is the raylib code of it
+type Binding struct {
+	Kind BindingKind
+	Code int32
+}
+
+func KeyOf(key Key) Binding {
+	return Binding{KeyBinding, int32(key)}
+}
+
+func MouseOf(button MouseButton) Binding {
+	return Binding{MouseBinding, int32(button)}
+}
+
+func PadOf(button GamepadButton) Binding {
+	return Binding{PadBinding, int32(button)}
+}
+
+// names of the codes that have one, the rest are written as numbers
+var codeNames = map[BindingKind]map[int32]string{
+	KeyBinding: {
+		32: "space", 39: "apostrophe", 44: "comma", 45: "minus", 46: "period",
+		47: "slash", 59: "semicolon", 61: "equal", 91: "left_bracket",
+		92: "backslash", 93: "right_bracket", 96: "grave",
+		256: "escape", 257: "enter", 258: "tab", 259: "backspace",
+		260: "insert", 261: "delete", 262: "right", 263: "left", 264: "down",
+		265: "up", 266: "page_up", 267: "page_down", 268: "home", 269: "end",
+		280: "caps_lock", 340: "left_shift", 341: "left_control",
+		342: "left_alt", 344: "right_shift", 345: "right_control", 346: "right_alt",
+	},
+	MouseBinding: {
+		int32(MouseLeft): "left", int32(MouseRight): "right", int32(MouseMiddle): "middle",
+	},
+	PadBinding: {
+		int32(PadDpadUp): "dpad_up", int32(PadDpadRight): "dpad_right",
+		int32(PadDpadDown): "dpad_down", int32(PadDpadLeft): "dpad_left",
+		int32(PadY): "y", int32(PadB): "b", int32(PadA): "a", int32(PadX): "x",
+		int32(PadLeftBumper): "left_bumper", int32(PadLeftTrigger): "left_trigger",
+		int32(PadRightBumper): "right_bumper", int32(PadRightTrigger): "right_trigger",
+		int32(PadBack): "back", int32(PadGuide): "guide", int32(PadStart): "start",
+		int32(PadLeftStick): "left_stick", int32(PadRightStick): "right_stick",
+	},
+}
+
+func init() {
+	keys := codeNames[KeyBinding]
+	for c := int32('0'); c <= '9'; c++ {
+		keys[c] = string(rune(c))
+	}
+	for c := int32('A'); c <= 'Z'; c++ {
+		keys[c] = string(rune(c))
+	}
+	for i := range int32(12) {
+		keys[290+i] = fmt.Sprintf("f%d", i+1)
+	}
+}
+
+// kind:name, like key:W, mouse:left or pad:right_trigger
+func (b Binding) String() string {
+	name, ok := codeNames[b.Kind][b.Code]
+	if !ok {
+		name = strconv.Itoa(int(b.Code))
+	}
+	return kindNames[b.Kind] + ":" + name
+}
+
+func ParseBinding(s string) (Binding, error) {
+	kindName, name, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return Binding{}, fmt.Errorf("binding %q is not kind:name", s)
+	}
+	for kind, kn := range kindNames {
+		if kn != kindName {
+			continue
+		}
+		for code, n := range codeNames[kind] {
+			if strings.EqualFold(n, name) {
+				return Binding{kind, code}, nil
+			}
+		}
+		code, err := strconv.Atoi(name)
+		if err != nil {
+			return Binding{}, fmt.Errorf("unknown %s %q", kindName, name)
+		}
+		return Binding{kind, int32(code)}, nil
+	}
+	return Binding{}, fmt.Errorf("unknown binding kind %q", kindName)
+}
+
+// what triggers each action, an action can have several bindings or none
+type Bindings map[Action][]Binding
+
+func DefaultBindings() Bindings {
+	return Bindings{
+		MoveUp:     {KeyOf(KeyW), PadOf(PadDpadUp)},
+		MoveDown:   {KeyOf(KeyS), PadOf(PadDpadDown)},
+		MoveLeft:   {KeyOf(KeyA), PadOf(PadDpadLeft)},
+		MoveRight:  {KeyOf(KeyD), PadOf(PadDpadRight)},
+		Shoot:      {MouseOf(MouseLeft), PadOf(PadRightTrigger)},
+		Scoreboard: {KeyOf(KeyTab), PadOf(PadBack)},
+		Chat:       {KeyOf(KeyEnter)},
+		Ability:    {KeyOf(KeySpace), PadOf(PadA)},
+		NextWeapon: {KeyOf('Q'), PadOf(PadY)},
+		Weapon1:    {KeyOf('1')},
+		Weapon2:    {KeyOf('2')},
+		Weapon3:    {KeyOf('3')},
+		Weapon4:    {KeyOf('4')},
+		FollowNext: {KeyOf('E'), PadOf(PadRightBumper)},
+	}
+}
+
+// adds b to action unless it's already bound to it
+func (bs Bindings) Add(action Action, b Binding) {
+	if !slices.Contains(bs[action], b) {
+		bs[action] = append(bs[action], b)
+	}
+}
+
+// reads lines of action = binding, binding, ... over the defaults, actions
+// missing from r keep their default bindings
+func ParseBindings(r io.Reader) (Bindings, error) {
+	bindings := DefaultBindings()
+	names := make(map[string]Action)
+	for _, action := range Actions {
+		names[action.String()] = action
+	}
+
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, list, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: %q is not action = bindings", lineNum, line)
+		}
+		action, ok := names[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("line %d: unknown action %q", lineNum, strings.TrimSpace(name))
+		}
+		bindings[action] = []Binding{}
+		for _, s := range strings.Split(list, ",") {
+			if strings.TrimSpace(s) == "" {
+				continue
+			}
+			b, err := ParseBinding(s)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			bindings.Add(action, b)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return bindings, nil
+}
+
+func (bs Bindings) WriteTo(w io.Writer) (int64, error) {
+	var sb strings.Builder
+	for _, action := range Actions {
+		names := []string{}
+		for _, b := range bs[action] {
+			names = append(names, b.String())
+		}
+		fmt.Fprintf(&sb, "%s = %s\n", action, strings.Join(names, ", "))
+	}
+	n, err := io.WriteString(w, sb.String())
+	return int64(n), err
+}
+
+// the bindings in path, the defaults when there is no such file
+func LoadBindings(path string) (Bindings, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return DefaultBindings(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ParseBindings(file)
+}
+
+func (bs Bindings) Save(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := bs.WriteTo(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
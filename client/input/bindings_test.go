@@ -0,0 +1,129 @@
+package input
+
+import (
+	"CircleWar/core/geom"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// every binding of the defaults does one thing
+func TestDefaultsDontOverlap(t *testing.T) {
+	owners := make(map[Binding]Action)
+	for _, action := range Actions {
+		for _, b := range DefaultBindings()[action] {
+			if other, ok := owners[b]; ok {
+				t.Errorf("%s is bound to %s and %s", b, other, action)
+			}
+			owners[b] = action
+		}
+	}
+}
+
+func TestParseBindings(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		want    Bindings // only the actions the test cares about
+		wantErr string
+	}{
+		{
+			name: "rebinds and keeps the rest",
+			file: "# mine\n\nshoot = key:space, mouse:right\nmove_up = key:up\n",
+			want: Bindings{
+				Shoot:    {KeyOf(KeySpace), MouseOf(MouseRight)},
+				MoveUp:   {KeyOf(265)},
+				MoveDown: DefaultBindings()[MoveDown],
+			},
+		},
+		{
+			name: "names ignore case and codes are numbers",
+			file: "ability = key:q, pad:LEFT_BUMPER, key:999",
+			want: Bindings{Ability: {KeyOf('Q'), PadOf(PadLeftBumper), KeyOf(999)}},
+		},
+		{name: "unbound", file: "chat =", want: Bindings{Chat: {}}},
+		{name: "duplicates dropped", file: "chat = key:T, key:t", want: Bindings{Chat: {KeyOf('T')}}},
+		{name: "no equals", file: "shoot\n", wantErr: "line 1:"},
+		{name: "unknown action", file: "\n\njump = key:space", wantErr: "line 3: unknown action"},
+		{name: "unknown kind", file: "shoot = foot:left", wantErr: "line 1: unknown binding kind"},
+		{name: "unknown name", file: "shoot = pad:z", wantErr: `line 1: unknown pad "z"`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseBindings(strings.NewReader(test.file))
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("got error %v want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for action, want := range test.want {
+				if !slices.Equal(got[action], want) {
+					t.Errorf("%s: got %v want %v", action, got[action], want)
+				}
+			}
+		})
+	}
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keybinds.txt")
+	bindings := DefaultBindings()
+	bindings[Shoot] = []Binding{KeyOf(KeySpace), PadOf(PadRightTrigger)}
+	bindings[Chat] = nil
+	bindings.Add(MoveUp, KeyOf(1234))
+
+	if err := bindings.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadBindings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !maps.EqualFunc(loaded, bindings, func(a, b []Binding) bool {
+		return slices.Equal(a, b) || len(a)+len(b) == 0
+	}) {
+		t.Errorf("got %v want %v", loaded, bindings)
+	}
+
+	missing, err := LoadBindings(filepath.Join(t.TempDir(), "nope"))
+	if err != nil || !slices.Equal(missing[Shoot], DefaultBindings()[Shoot]) {
+		t.Errorf("missing file: got %v, %v want the defaults", missing, err)
+	}
+}
+
+func TestReboundActions(t *testing.T) {
+	bindings := DefaultBindings()
+	bindings[Shoot] = []Binding{KeyOf(KeySpace), PadOf(PadX)}
+	bindings[MoveLeft] = []Binding{PadOf(PadLeftTrigger)}
+
+	tests := []struct {
+		name      string
+		device    fakeDevice
+		wantShot  bool
+		wantMoveX float32
+	}{
+		{"old binding does nothing", fakeDevice{mouseDown: true, keys: map[Key]bool{KeyA: true}}, false, 0},
+		{"key", fakeDevice{keys: map[Key]bool{KeySpace: true}}, true, 0},
+		{"pad button", fakeDevice{padButtons: map[GamepadButton]bool{PadX: true}, pad: &GamepadState{}}, true, 0},
+		{"pad button without pad", fakeDevice{padButtons: map[GamepadButton]bool{PadX: true}}, false, 0},
+		{"trigger as button", fakeDevice{pad: &GamepadState{LeftTrigger: 1}}, false, -1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.device.cursor = geom.NewVector(10, 0)
+			analog, target := actions(t, NewMapper(&test.device, bindings).PlayerInput(1, geom.Vector2{}))
+			if (target != nil) != test.wantShot {
+				t.Errorf("got shot %t want %t", target != nil, test.wantShot)
+			}
+			if analog.Move.X != test.wantMoveX {
+				t.Errorf("got move x %f want %f", analog.Move.X, test.wantMoveX)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+// turns keyboard, mouse and gamepad state into player input. the devices are
+// read through Device so the mapping works without a window
+package input
+
+import (
+	"CircleWar/core/geom"
+	"CircleWar/core/netmsg"
+	"CircleWar/core/weapons"
+	"math"
+	"slices"
+)
+
+// the codes are raylib's, a raylib device passes them straight through
+type Key int32
+type MouseButton int32
+
+const (
+	KeySpace Key = 32
+	KeyA     Key = 65
+	KeyD     Key = 68
+	KeyS     Key = 83
+	KeyW     Key = 87
+	KeyEnter Key = 257
+	KeyTab   Key = 258
+
+	MouseLeft MouseButton = 0
+)
+
+const (
+	// stick deflection that is ignored, worn sticks don't rest at 0
+	StickDeadzone = 0.2
+	// how far a trigger is pulled before it shoots
+	TriggerThreshold = 0.5
+	// a stick shot aims this far in front of the player
+	stickAimDistance = 300
+)
+
+// sticks go from -1 to 1 with y pointing down, triggers from 0 (released) to 1
+type GamepadState struct {
+	LeftStick, RightStick     geom.Vector2
+	LeftTrigger, RightTrigger float32
+}
+
+type Device interface {
+	KeyDown(key Key) bool
+	MouseButtonDown(button MouseButton) bool
+	// the mouse in world coordinates
+	Cursor() geom.Vector2
+	// how far the mouse moved on screen since the last frame, the cursor
+	// also moves in the world when the camera scrolls
+	MouseDelta() geom.Vector2
+	// false when no gamepad is connected
+	Gamepad() (GamepadState, bool)
+	GamepadButtonDown(button GamepadButton) bool
+}
+
+// merges the devices into one PlayerInput per frame. aim follows whichever
+// of the mouse and the right stick was used last
+type Mapper struct {
+	Device   Device
+	Bindings Bindings
+	// loadout slot the player shoots with
+	Slot     uint32
+	aim      float32
+	stickAim bool
+	wasDown  map[Action]bool
+}
+
+func NewMapper(device Device, bindings Bindings) *Mapper {
+	return &Mapper{Device: device, Bindings: bindings, wasDown: make(map[Action]bool)}
+}
+
+func (m *Mapper) bindingDown(b Binding) bool {
+	switch b.Kind {
+	case KeyBinding:
+		return m.Device.KeyDown(Key(b.Code))
+	case MouseBinding:
+		return m.Device.MouseButtonDown(MouseButton(b.Code))
+	case PadBinding:
+		pad, ok := m.Device.Gamepad()
+		switch {
+		case !ok:
+			return false
+		// triggers are analog, they count as down once pulled far enough
+		case GamepadButton(b.Code) == PadLeftTrigger:
+			return pad.LeftTrigger >= TriggerThreshold
+		case GamepadButton(b.Code) == PadRightTrigger:
+			return pad.RightTrigger >= TriggerThreshold
+		}
+		return m.Device.GamepadButtonDown(GamepadButton(b.Code))
+	}
+	return false
+}
+
+// whether any binding of the action is held down
+func (m *Mapper) Down(action Action) bool {
+	return slices.ContainsFunc(m.Bindings[action], m.bindingDown)
+}
+
+func length(v geom.Vector2) float32 {
+	return float32(math.Hypot(float64(v.X), float64(v.Y)))
+}
+
+func scaled(v geom.Vector2, by float32) geom.Vector2 {
+	return geom.NewVector(v.X*by, v.Y*by)
+}
+
+// the stick with the deadzone cut out, so movement starts at 0 right past it
+func applyDeadzone(stick geom.Vector2) geom.Vector2 {
+	l := length(stick)
+	if l <= StickDeadzone {
+		return geom.Vector2{}
+	}
+	return scaled(stick, min(1, (l-StickDeadzone)/(1-StickDeadzone))/l)
+}
+
+func angle(v geom.Vector2) float32 {
+	return float32(math.Atan2(float64(v.Y), float64(v.X)))
+}
+
+func (m *Mapper) buttonMove() geom.Vector2 {
+	move := geom.Vector2{}
+	if m.Down(MoveUp) {
+		move.Y -= 1
+	}
+	if m.Down(MoveDown) {
+		move.Y += 1
+	}
+	if m.Down(MoveLeft) {
+		move.X -= 1
+	}
+	if m.Down(MoveRight) {
+		move.X += 1
+	}
+	return move
+}
+
+// whether the action went down since the last time this was asked
+func (m *Mapper) Pressed(action Action) bool {
+	down := m.Down(action)
+	pressed := down && !m.wasDown[action]
+	m.wasDown[action] = down
+	return pressed
+}
+
+func (m *Mapper) switchWeapon() {
+	if m.Pressed(NextWeapon) {
+		m.Slot = (m.Slot + 1) % uint32(len(weapons.Loadout))
+	}
+	for _, action := range Actions {
+		if slot, ok := weaponSlots[action]; ok && m.Pressed(action) && int(slot) < len(weapons.Loadout) {
+			m.Slot = slot
+		}
+	}
+}
+
+// input of the player at pos for this frame
+func (m *Mapper) PlayerInput(playerId uint32, pos geom.Vector2) *netmsg.PlayerInput {
+	m.switchWeapon()
+	pad, hasPad := m.Device.Gamepad()
+	move := m.buttonMove()
+	if hasPad {
+		move = move.Add(applyDeadzone(pad.LeftStick))
+	}
+	if l := length(move); l > 1 {
+		move = scaled(move, 1/l)
+	}
+
+	cursor := m.Device.Cursor()
+	if m.Device.MouseDelta() != (geom.Vector2{}) {
+		m.stickAim = false
+	}
+	if aimStick := applyDeadzone(pad.RightStick); hasPad && aimStick != (geom.Vector2{}) {
+		m.stickAim = true
+		m.aim = angle(aimStick)
+	}
+	if !m.stickAim {
+		m.aim = angle(cursor.Sub(pos))
+	}
+
+	playerInput := &netmsg.PlayerInput{PlayerId: playerId}
+	playerInput.Actions = append(playerInput.Actions, &netmsg.AnalogAction{Move: move, Aim: m.aim})
+
+	// shots go where the player aims, whatever they are bound to
+	if m.Down(Shoot) {
+		target := cursor
+		if m.stickAim {
+			aimDir := geom.NewVector(float32(math.Cos(float64(m.aim))), float32(math.Sin(float64(m.aim))))
+			target = pos.Add(scaled(aimDir, stickAimDistance))
+		}
+		playerInput.Actions = append(playerInput.Actions, &netmsg.ShootAction{Target: target, Slot: m.Slot})
+	}
+	// the ability is a dash, once per press
+	if m.Pressed(Ability) {
+		playerInput.Actions = append(playerInput.Actions, &netmsg.DashAction{})
+	}
+	return playerInput
+}
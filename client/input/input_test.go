@@ -0,0 +1,245 @@
+package input
+
+import (
+	"CircleWar/core/geom"
+	"CircleWar/core/netmsg"
+	"math"
+	"testing"
+)
+
+type fakeDevice struct {
+	keys       map[Key]bool
+	padButtons map[GamepadButton]bool
+	mouseDown  bool
+	cursor     geom.Vector2
+	mouseDelta geom.Vector2
+	pad        *GamepadState
+}
+
+func (d *fakeDevice) KeyDown(key Key) bool {
+	return d.keys[key]
+}
+
+func (d *fakeDevice) MouseButtonDown(button MouseButton) bool {
+	return button == MouseLeft && d.mouseDown
+}
+
+func (d *fakeDevice) Cursor() geom.Vector2 {
+	return d.cursor
+}
+
+func (d *fakeDevice) MouseDelta() geom.Vector2 {
+	return d.mouseDelta
+}
+
+func (d *fakeDevice) GamepadButtonDown(button GamepadButton) bool {
+	return d.padButtons[button]
+}
+
+func (d *fakeDevice) Gamepad() (GamepadState, bool) {
+	if d.pad == nil {
+		return GamepadState{}, false
+	}
+	return *d.pad, true
+}
+
+func near(a, b geom.Vector2) bool {
+	return a.DistTo(b) < 1e-4
+}
+
+// splits the input into its analog action and shot target, if any. dashes
+// are left out, TestDash checks them
+func actions(t *testing.T, pi *netmsg.PlayerInput) (*netmsg.AnalogAction, *geom.Vector2) {
+	var analog *netmsg.AnalogAction
+	var target *geom.Vector2
+	for _, action := range pi.Actions {
+		switch act := action.(type) {
+		case *netmsg.AnalogAction:
+			analog = act
+		case *netmsg.ShootAction:
+			target = &act.Target
+		case *netmsg.DashAction:
+		default:
+			t.Fatalf("unexpected action %T", act)
+		}
+	}
+	if analog == nil {
+		t.Fatal("input has no analog action")
+	}
+	return analog, target
+}
+
+func TestPlayerInput(t *testing.T) {
+	pos := geom.NewVector(100, 100)
+	diag := float32(1 / math.Sqrt2)
+	tests := []struct {
+		name       string
+		device     fakeDevice
+		wantMove   geom.Vector2
+		wantAim    float32
+		wantTarget *geom.Vector2
+	}{
+		{
+			name:     "keyboard",
+			device:   fakeDevice{keys: map[Key]bool{KeyD: true}, cursor: geom.NewVector(100, 200)},
+			wantMove: geom.NewVector(1, 0),
+			wantAim:  math.Pi / 2,
+		},
+		{
+			name:     "keyboard diagonal",
+			device:   fakeDevice{keys: map[Key]bool{KeyW: true, KeyA: true}, cursor: geom.NewVector(200, 100)},
+			wantMove: geom.NewVector(-diag, -diag),
+		},
+		{
+			name:       "mouse shoots at cursor",
+			device:     fakeDevice{mouseDown: true, cursor: geom.NewVector(200, 100)},
+			wantTarget: &geom.Vector2{X: 200, Y: 100},
+		},
+		{
+			name:     "stick inside deadzone",
+			device:   fakeDevice{cursor: geom.NewVector(200, 100), pad: &GamepadState{LeftStick: geom.NewVector(0.1, 0.1)}},
+			wantMove: geom.NewVector(0, 0),
+		},
+		{
+			name:     "stick past deadzone",
+			device:   fakeDevice{cursor: geom.NewVector(200, 100), pad: &GamepadState{LeftStick: geom.NewVector(0, 0.6)}},
+			wantMove: geom.NewVector(0, 0.5),
+		},
+		{
+			name: "stick and keys clamped",
+			device: fakeDevice{
+				keys:   map[Key]bool{KeyD: true},
+				cursor: geom.NewVector(200, 100),
+				pad:    &GamepadState{LeftStick: geom.NewVector(1, 0)},
+			},
+			wantMove: geom.NewVector(1, 0),
+		},
+		{
+			name: "right stick aims and trigger shoots",
+			device: fakeDevice{
+				cursor: geom.NewVector(200, 100),
+				pad:    &GamepadState{RightStick: geom.NewVector(0, -1), RightTrigger: 1},
+			},
+			wantAim:    -math.Pi / 2,
+			wantTarget: &geom.Vector2{X: 100, Y: 100 - stickAimDistance},
+		},
+		{
+			name: "half pulled trigger",
+			device: fakeDevice{
+				cursor: geom.NewVector(200, 100),
+				pad:    &GamepadState{RightTrigger: TriggerThreshold / 2},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			analog, target := actions(t, NewMapper(&test.device, DefaultBindings()).PlayerInput(1, pos))
+			if !near(analog.Move, test.wantMove) {
+				t.Errorf("got move %s want %s", analog.Move, test.wantMove)
+			}
+			if math.Abs(float64(analog.Aim-test.wantAim)) > 1e-4 {
+				t.Errorf("got aim %f want %f", analog.Aim, test.wantAim)
+			}
+			switch {
+			case test.wantTarget == nil && target != nil:
+				t.Errorf("shot at %s, want no shot", *target)
+			case test.wantTarget != nil && target == nil:
+				t.Errorf("didn't shoot, want shot at %s", *test.wantTarget)
+			case test.wantTarget != nil && !near(*target, *test.wantTarget):
+				t.Errorf("shot at %s want %s", *target, *test.wantTarget)
+			}
+		})
+	}
+}
+
+// aim stays with the stick after it's released, until the mouse moves
+func TestAimSource(t *testing.T) {
+	pos := geom.NewVector(0, 0)
+	device := &fakeDevice{cursor: geom.NewVector(10, 0), pad: &GamepadState{}}
+	mapper := NewMapper(device, DefaultBindings())
+
+	steps := []struct {
+		name       string
+		rightStick geom.Vector2
+		cursor     geom.Vector2
+		mouseDelta geom.Vector2
+		wantAim    float32
+	}{
+		{"mouse", geom.Vector2{}, geom.NewVector(10, 0), geom.Vector2{}, 0},
+		{"stick takes over", geom.NewVector(-1, 0), geom.NewVector(10, 0), geom.Vector2{}, math.Pi},
+		{"stick released", geom.Vector2{}, geom.NewVector(10, 0), geom.Vector2{}, math.Pi},
+		{"camera scrolled", geom.Vector2{}, geom.NewVector(0, 10), geom.Vector2{}, math.Pi},
+		{"mouse moved", geom.Vector2{}, geom.NewVector(0, 10), geom.NewVector(3, 0), math.Pi / 2},
+	}
+	for _, step := range steps {
+		device.pad.RightStick = step.rightStick
+		device.cursor = step.cursor
+		device.mouseDelta = step.mouseDelta
+		analog, _ := actions(t, mapper.PlayerInput(1, pos))
+		if math.Abs(float64(analog.Aim-step.wantAim)) > 1e-4 {
+			t.Errorf("%s: got aim %f want %f", step.name, analog.Aim, step.wantAim)
+		}
+	}
+}
+
+func TestSwitchWeapon(t *testing.T) {
+	device := &fakeDevice{mouseDown: true}
+	mapper := NewMapper(device, DefaultBindings())
+
+	steps := []struct {
+		name     string
+		keys     map[Key]bool
+		wantSlot uint32
+	}{
+		{"starts with the first", nil, 0},
+		{"pick third", map[Key]bool{'3': true}, 2},
+		{"next", map[Key]bool{'Q': true}, 3},
+		{"held next doesn't repeat", map[Key]bool{'Q': true}, 3},
+		{"released", nil, 3},
+		{"next wraps around", map[Key]bool{'Q': true}, 0},
+	}
+	for _, step := range steps {
+		device.keys = step.keys
+		var shot *netmsg.ShootAction
+		for _, action := range mapper.PlayerInput(1, geom.Vector2{}).Actions {
+			if act, ok := action.(*netmsg.ShootAction); ok {
+				shot = act
+			}
+		}
+		if shot == nil {
+			t.Fatalf("%s: didn't shoot", step.name)
+		}
+		if shot.Slot != step.wantSlot {
+			t.Errorf("%s: shot with slot %d want %d", step.name, shot.Slot, step.wantSlot)
+		}
+	}
+}
+
+func TestDash(t *testing.T) {
+	device := &fakeDevice{}
+	mapper := NewMapper(device, DefaultBindings())
+
+	steps := []struct {
+		name     string
+		keys     map[Key]bool
+		wantDash bool
+	}{
+		{"nothing pressed", nil, false},
+		{"pressed", map[Key]bool{KeySpace: true}, true},
+		{"held doesn't repeat", map[Key]bool{KeySpace: true}, false},
+		{"released", nil, false},
+		{"pressed again", map[Key]bool{KeySpace: true}, true},
+	}
+	for _, step := range steps {
+		device.keys = step.keys
+		dashed := false
+		for _, action := range mapper.PlayerInput(1, geom.Vector2{}).Actions {
+			if _, ok := action.(*netmsg.DashAction); ok {
+				dashed = true
+			}
+		}
+		if dashed != step.wantDash {
+			t.Errorf("%s: got dash %t want %t", step.name, dashed, step.wantDash)
+		}
+	}
+}
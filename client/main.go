@@ -1,41 +1,123 @@
 package main
 
 import (
+	"CircleWar/client/input"
 	"CircleWar/config"
 	"CircleWar/core/geom"
 	"CircleWar/core/hitboxes"
+	"CircleWar/core/logging"
 	"CircleWar/core/netmsg"
 	conn "CircleWar/core/network/gameConn"
+	"CircleWar/core/pickups"
+	"CircleWar/core/weapons"
 	envdata "CircleWar/env/env_data"
 	envloader "CircleWar/env/env_loader"
+	"cmp"
 	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"io/fs"
+	"math"
 	"net"
+	"os"
+	"slices"
 	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	gui "github.com/gen2brain/raylib-go/raygui"
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
-const (
-	port = config.Port
+// the client's own settings, the gameplay ones are replaced by the server's
+// when we join
+var cfg = config.Default()
+
+// how often we ask for the server's gameplay while ours doesn't match it
+const gameplayRequestEvery = time.Second
+
+var (
+	netLog   = logging.For(logging.Net)
+	matchLog = logging.For(logging.Match)
+	// for what could come with every frame
+	frameLog = logging.Limited(netLog, 5*time.Second)
 )
 
-func drawWorld(world *netmsg.WorldState, myId uint32) {
-	for i := int32(0); i < config.WorldWidth/100+1; i++ {
-		for j := int32(0); j < config.WorldHeight/100+1; j++ {
-			if i%2 == 1 {
-				continue
-			}
-			if j%2 == 0 {
-				rl.DrawRectangle(i*100, j*100, 100, 100, rl.Brown)
-			} else {
-				rl.DrawRectangle((i+1)*100, j*100, 100, 100, rl.Brown)
-			}
-		}
+func fatal(msg string, err error) {
+	matchLog.Error(msg, logging.Err(err))
+	os.Exit(1)
+}
+
+// every weapon's bullets look a bit different
+func drawBullet(bullet *netmsg.BulletState, color rl.Color) {
+	x, y := int32(bullet.Pos.X), int32(bullet.Pos.Y)
+	switch bullet.Weapon {
+	case weapons.Shotgun:
+		rl.DrawCircle(x, y, bullet.Size, rl.ColorBrightness(color, -0.4))
+	case weapons.Sniper:
+		rl.DrawCircle(x, y, bullet.Size, color)
+		rl.DrawCircleLines(x, y, bullet.Size*1.8, color)
+	case weapons.SMG:
+		rl.DrawCircle(x, y, bullet.Size, rl.ColorBrightness(color, 0.3))
+	default:
+		rl.DrawCircle(x, y, bullet.Size, color)
+	}
+}
+
+var pickupColors = map[pickups.Kind]rl.Color{
+	pickups.Health:     rl.Green,
+	pickups.SpeedBoost: rl.Gold,
+	pickups.RapidFire:  rl.Orange,
+	pickups.Shield:     rl.SkyBlue,
+	pickups.BigBullets: rl.Purple,
+	pickups.Armor:      rl.Gray,
+}
+
+func drawPickup(pickup *netmsg.PickupState) {
+	x, y := int32(pickup.Pos.X), int32(pickup.Pos.Y)
+	rl.DrawCircle(x, y, pickups.Radius, pickupColors[pickup.Kind])
+	rl.DrawCircleLines(x, y, pickups.Radius, rl.Black)
+	letter := strings.ToUpper(pickups.Get(pickup.Kind).Name[:1])
+	rl.DrawText(letter, x-rl.MeasureText(letter, 20)/2, y-10, 20, rl.Black)
+}
+
+func hasEffect(player *netmsg.PlayerState, kind pickups.Kind) bool {
+	return slices.ContainsFunc(player.Effects, func(effect netmsg.Effect) bool {
+		return effect.Kind == kind
+	})
+}
+
+// the effects running on us and how long they have left
+func drawEffectTimers(world *netmsg.WorldState, myId uint32) {
+	me, ok := findPlayer(world, myId)
+	if !ok {
+		return
+	}
+	for i, effect := range me.Effects {
+		text := fmt.Sprintf("%s %.1fs", pickups.Get(effect.Kind).Name, effect.Remaining.Seconds())
+		rl.DrawText(text, 10, 80+int32(i)*26, 22, pickupColors[effect.Kind])
 	}
+}
+
+// arc under the player that fills up while the dash cools down
+func drawDashIndicator(player *netmsg.PlayerState, size float32) {
+	dashCooldown := config.Current().DashCooldown()
+	center := rl.NewVector2(player.Pos.X, player.Pos.Y)
+	if player.DashCooldown <= 0 {
+		rl.DrawRing(center, size+12, size+15, 0, 360, 32, rl.Fade(rl.DarkGray, 0.6))
+		return
+	}
+	done := 1 - float32(player.DashCooldown)/float32(dashCooldown)
+	rl.DrawRing(center, size+12, size+15, -90, -90+360*done, 32, rl.Fade(rl.Gray, 0.4))
+}
+
+func drawWorld(world *netmsg.WorldState, myId uint32, visible rl.Rectangle) {
+	drawFloor(visible)
+	for _, pickup := range world.Pickups {
+		drawPickup(pickup)
+	}
+
 	var color rl.Color
 	sort.Slice(world.Players, func(i, j int) bool {
 		return world.Players[i].Id < world.Players[j].Id
@@ -45,12 +127,23 @@ func drawWorld(world *netmsg.WorldState, myId uint32) {
 		if player.Id == myId {
 			color = rl.Blue
 		}
+		size := hitboxes.PlayerSize(netmsg.PlayerHealth(player.Health))
 		rl.DrawCircle(
 			int32(player.Pos.X),
 			int32(player.Pos.Y),
-			hitboxes.PlayerSize(netmsg.PlayerHealth(player.Health)),
+			size,
 			color,
 		)
+		// barrel pointing where the player aims
+		aimDir := rl.NewVector2(float32(math.Cos(float64(player.Aim))), float32(math.Sin(float64(player.Aim))))
+		barrelEnd := rl.Vector2Add(rl.Vector2(player.Pos), rl.Vector2Scale(aimDir, size+12))
+		rl.DrawLineEx(rl.Vector2(player.Pos), barrelEnd, 8, color)
+		if player.Id == myId {
+			drawDashIndicator(player, size)
+		}
+		if hasEffect(player, pickups.Shield) {
+			rl.DrawRing(rl.Vector2(player.Pos), size+4, size+9, 0, 360, 32, rl.Fade(pickupColors[pickups.Shield], 0.8))
+		}
 	}
 
 	for _, bullet := range world.Bullets {
@@ -58,58 +151,56 @@ func drawWorld(world *netmsg.WorldState, myId uint32) {
 		if bullet.OwnerId == myId {
 			color = rl.Blue
 		}
-		rl.DrawCircle(
-			int32(bullet.Pos.X),
-			int32(bullet.Pos.Y),
-			bullet.Size,
-			color,
-		)
+		drawBullet(bullet, color)
 	}
 }
 
-func addPlayerDirAction(pi *netmsg.PlayerInput, dir netmsg.Direction) {
-	pi.Actions = append(pi.Actions, &netmsg.MoveAction{Dir: dir})
-}
-
-func addPlayerShootAction(pi *netmsg.PlayerInput, target geom.Vector2) {
-	pi.Actions = append(pi.Actions, &netmsg.ShootAction{Target: target})
+// reads from the server until conn is closed or done, a handler stuck on a
+// full channel stops with done
+func serverInputHandler(conn *conn.ClientConn, serverInput chan netmsg.GameMessage, done <-chan struct{}) {
+	for {
+		servMsg, err := conn.Recieve()
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			frameLog.Warn("can't receive from server", logging.Err(err))
+			continue
+		}
+		select {
+		case serverInput <- servMsg:
+		case <-done:
+			return
+		}
+	}
 }
 
-func getPlayerInput() *netmsg.PlayerInput {
-	playerInput := &netmsg.PlayerInput{}
-
-	// ### WASD ###
-	if rl.IsKeyDown(rl.KeyW) {
-		addPlayerDirAction(playerInput, netmsg.UP)
-	}
-	if rl.IsKeyDown(rl.KeyS) {
-		addPlayerDirAction(playerInput, netmsg.DOWN)
-	}
-	if rl.IsKeyDown(rl.KeyA) {
-		addPlayerDirAction(playerInput, netmsg.LEFT)
+// connects to the server at addr, its messages come in on the channel.
+// hangUp closes the connection and stops reading from it
+func dial(addr string) (serverConn *conn.ClientConn, serverInput chan netmsg.GameMessage, hangUp func(), err error) {
+	serverAddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, nil, nil, err
 	}
-	if rl.IsKeyDown(rl.KeyD) {
-		addPlayerDirAction(playerInput, netmsg.RIGHT)
+	serverConn, err = conn.NewClientConn(serverAddr)
+	if err != nil {
+		return nil, nil, nil, err
 	}
-
-	// ### Shoot with mouse ###
-	if rl.IsMouseButtonDown(rl.MouseButtonLeft) {
-		mousePos := rl.GetMousePosition()
-		addPlayerShootAction(playerInput, geom.Vector2(mousePos))
+	serverInput = make(chan netmsg.GameMessage, 100)
+	done := make(chan struct{})
+	go serverInputHandler(serverConn, serverInput, done)
+	hangUp = func() {
+		close(done)
+		serverConn.Close()
 	}
-
-	return playerInput
+	return serverConn, serverInput, hangUp, nil
 }
 
-func serverInputHandler(conn *conn.ClientConn, serverInput chan netmsg.GameMessage) {
-	for {
-		servMsg, err := conn.Recieve()
-		if err != nil {
-			fmt.Println("error receiving from server:", err)
-			continue
-		}
-		serverInput <- servMsg
+func joinRequest(spectate bool) netmsg.GameMessage {
+	if spectate {
+		return netmsg.NewSpectateRequest("default")
 	}
+	return netmsg.NewConnectRequest("default")
 }
 
 func getMyHealth(ws *netmsg.WorldState, myId uint32) (float32, error) {
@@ -128,7 +219,7 @@ func gatherServerMsgs(serverInput chan netmsg.GameMessage) []netmsg.GameMessage
 	for range limit {
 		select {
 		case msg := <-serverInput:
-			fmt.Println("input from server")
+			frameLog.Debug("message from server", "type", netmsg.Name(msg))
 			msgs = append(msgs, msg)
 		default:
 			return msgs
@@ -138,93 +229,276 @@ func gatherServerMsgs(serverInput chan netmsg.GameMessage) []netmsg.GameMessage
 	return msgs
 }
 
+// players of the world by health, we are highlighted
+func drawScoreboard(world *netmsg.WorldState, myId uint32) {
+	players := slices.Clone(world.Players)
+	slices.SortStableFunc(players, func(a, b *netmsg.PlayerState) int {
+		return cmp.Compare(b.Health, a.Health)
+	})
+
+	const rowHeight = 28
+	x, y := int32(cfg.CameraWidth/2-200), int32(80)
+	rl.DrawRectangle(x-20, y-20, 440, int32(len(players))*rowHeight+70, rl.Fade(rl.Black, 0.7))
+	rl.DrawText("PLAYER        HP", x, y, 24, rl.White)
+	for i, player := range players {
+		color := rl.LightGray
+		if player.Id == myId {
+			color = rl.SkyBlue
+		}
+		rl.DrawText(fmt.Sprintf("%-12d  %.0f", player.Id, player.Health), x, y+int32(i+1)*rowHeight+10, 24, color)
+	}
+}
+
+// how long messages from the server stay on screen
+const serverMessageTime = 5 * time.Second
+
+// banner at the top of the screen
+func drawServerMessage(text string) {
+	const size = 28
+	width := rl.MeasureText(text, size)
+	x := int32(cfg.CameraWidth)/2 - width/2
+	rl.DrawRectangle(x-16, 6, width+32, size+16, rl.Fade(rl.Black, 0.7))
+	rl.DrawText(text, x, 14, size, rl.Gold)
+}
+
 type Status uint
 
 const (
 	NONE = iota
 	ALIVE
 	DEAD
+	SPECTATING
+	REJECTED
+	SHUTDOWN
 )
 
+// button in the middle of the screen
+func centerButton(text string) bool {
+	bx, by := float32(180), float32(60)
+	return gui.Button(rl.Rectangle{
+		X: (float32(cfg.CameraWidth) - bx) / 2, Y: (float32(cfg.CameraHeight) - by) / 2,
+		Width: bx, Height: by,
+	}, text)
+}
+
 func main() {
-	envloader.LoadFile(envdata.EnvfilePath())
+	replayPath := flag.String("replay", "", "replay file to watch instead of joining a server")
+	spectate := flag.Bool("spectate", false, "watch the game without joining it")
+	envOverride := flag.Bool("env-override", false, ".env values replace variables already set in the environment")
+	configFlags := config.AddFlags(flag.CommandLine)
+	flag.Parse()
+
+	envErr := envloader.Load(envdata.EnvfilePath(), envloader.Options{Override: *envOverride})
+	if err := logging.Setup(logging.ConfigFromEnv(envloader.GetEnv)); err != nil {
+		fatal("bad log config", err)
+	}
+	if errors.Is(envErr, fs.ErrNotExist) {
+		matchLog.Info("no .env file, using the defaults", "path", envdata.EnvfilePath())
+	} else if envErr != nil {
+		fatal("bad .env file", envErr)
+	}
+	var err error
+	if cfg, err = configFlags.Load(envdata.ConfigPath(), envloader.GetEnv); err != nil {
+		fatal("bad config", err)
+	}
+	config.SetCurrent(cfg.Gameplay)
+
+	if *replayPath != "" {
+		rl.InitWindow(int32(cfg.CameraWidth), int32(cfg.CameraHeight), "CircleWar Replay")
+		defer rl.CloseWindow()
+		rl.SetTargetFPS(int32(cfg.ClientFPS))
+		if err := runReplayViewer(*replayPath); err != nil {
+			fatal("can't play replay", err)
+		}
+		return
+	}
+
 	serverIp := envloader.GetEnv("SERVER_IP", "127.0.0.1")
 
-	serverAddr, _ := net.ResolveUDPAddr("udp", fmt.Sprintf("%s:%d", serverIp, port))
-	conn, err := conn.NewClientConn(serverAddr)
+	serverAddr := fmt.Sprintf("%s:%d", serverIp, cfg.Port)
+	conn, serverInput, hangUp, err := dial(serverAddr)
 	if err != nil {
-		log.Fatal(err)
+		fatal("can't connect", err)
 	}
-	defer conn.Close()
+	// conn changes when we follow a shutdown's reconnect hint
+	defer func() { hangUp() }()
 
-	rl.InitWindow(config.WorldWidth, config.WorldHeight, "CircleWar Client")
+	rl.InitWindow(int32(cfg.CameraWidth), int32(cfg.CameraHeight), "CircleWar Client")
 	defer rl.CloseWindow()
-	rl.SetTargetFPS(60)
-
-	serverInput := make(chan netmsg.GameMessage, 100)
-	go serverInputHandler(conn, serverInput)
+	rl.SetTargetFPS(int32(cfg.ClientFPS))
 
 	curWorld := &netmsg.WorldState{}
 	var playerId uint32
 	var lastServerTick uint32 = 0
 	status := NONE
-
-	err = conn.Send(netmsg.NewConnectRequest("default"))
+	camera := newCamera(rl.NewVector2(cfg.WorldWidth/2, cfg.WorldHeight/2))
+	bindings, err := input.LoadBindings(envdata.KeybindsPath())
 	if err != nil {
-		fmt.Println("error sending connect request:", err)
+		matchLog.Warn("can't read keybinds, using the defaults", logging.Err(err))
+		bindings = input.DefaultBindings()
+	}
+	mapper := input.NewMapper(raylibDevice{&camera}, bindings)
+	settings := newSettingsScreen(mapper, envdata.KeybindsPath())
+	spec := newSpectator()
+	var rejectReason string
+	var lastView geom.Vector2
+	var serverMsg string
+	var serverMsgUntil time.Time
+	var lastGameplayRequest time.Time
+	var shutdownNote *netmsg.ServerShutdown
+
+	if err := conn.Send(joinRequest(*spectate)); err != nil {
+		netLog.Error("can't send connect request", logging.Err(err))
 	}
 
 	for !rl.WindowShouldClose() {
-		if status == ALIVE {
-			playerInput := getPlayerInput()
-			playerInput.PlayerId = playerId
+		if status == ALIVE && !settings.Open {
+			var myPos geom.Vector2
+			if me, ok := findPlayer(curWorld, playerId); ok {
+				myPos = me.Pos
+			}
+			playerInput := mapper.PlayerInput(playerId, myPos)
 			err := conn.Send(playerInput)
 			if err != nil {
-				fmt.Println("error sending player input:", err)
+				frameLog.Warn("can't send player input", logging.Err(err))
 			}
 		}
 
 		servMsgs := gatherServerMsgs(serverInput)
-		// fmt.Println("#servmsgs:", len(servMsgs))
 
 		for _, msg := range servMsgs {
 			switch payload := msg.(type) {
 			case *netmsg.WorldState:
-				// fmt.Println("world from server:", *payload, "tick:", payload.TickNum)
 				if payload.TickNum >= lastServerTick {
 					lastServerTick = payload.TickNum
 					curWorld = payload
 				}
+				// the GameplayUpdate got lost, ask again but not every frame
+				if payload.GameplayChecksum != 0 && payload.GameplayChecksum != config.Current().Checksum() &&
+					time.Since(lastGameplayRequest) > gameplayRequestEvery {
+					lastGameplayRequest = time.Now()
+					if err := conn.Send(netmsg.NewGameplayRequest()); err != nil {
+						netLog.Warn("can't ask for the gameplay", logging.Err(err))
+					}
+				}
 			case *netmsg.ConnectAck:
-				fmt.Println("got ack")
+				matchLog.Info("joined", logging.Player(payload.PlayerId))
+				config.SetCurrent(payload.Gameplay)
 				playerId = payload.PlayerId
 				status = ALIVE
+			case *netmsg.SpectateAck:
+				config.SetCurrent(payload.Gameplay)
+				status = SPECTATING
+			case *netmsg.ConnectReject:
+				matchLog.Info("rejected", "reason", payload.Reason)
+				rejectReason = payload.Reason
+				status = REJECTED
 			case *netmsg.DeathNote:
+				matchLog.Info("died", logging.Player(payload.PlayerId), logging.Tick(lastServerTick))
 				status = DEAD
+				if payload.Spectating {
+					status = SPECTATING
+				}
+			case *netmsg.ServerShutdown:
+				matchLog.Info("server shut down", "reason", payload.Reason, "reconnect_hint", payload.ReconnectHint)
+				shutdownNote = payload
+				status = SHUTDOWN
+			case *netmsg.GameplayUpdate:
+				matchLog.Info("gameplay changed")
+				config.SetCurrent(payload.Gameplay)
+			case *netmsg.ServerMessage:
+				matchLog.Info("server message", "text", payload.Text)
+				serverMsg = payload.Text
+				serverMsgUntil = time.Now().Add(serverMessageTime)
+			case *netmsg.Ping:
+				// the server measures our ping
+				if err := conn.Send(netmsg.NewPong(payload.Seq, payload.SentNano)); err != nil {
+					netLog.Warn("can't send pong", logging.Err(err))
+				}
 			}
 		}
 
+		if status == SPECTATING {
+			spec.update(curWorld, mapper, rl.GetFrameTime())
+			// the server only sends what's around where we look
+			if view := geom.Vector2(spec.camera.Target); view != lastView {
+				lastView = view
+				if err := conn.Send(netmsg.NewViewUpdate(view)); err != nil {
+					frameLog.Warn("can't send view update", logging.Err(err))
+				}
+			}
+		}
+		if me, ok := findPlayer(curWorld, playerId); ok && status == ALIVE {
+			followCamera(&camera, me.Pos)
+		}
 		myHealth, _ := getMyHealth(curWorld, playerId)
 
 		rl.BeginDrawing()
 		rl.ClearBackground(rl.NewColor(253, 245, 203, 100))
-		drawWorld(curWorld, playerId)
-		rl.DrawText("HP : "+strconv.FormatInt(int64(myHealth), 10), 10, 10, 32, rl.Black)
-
-		if status == DEAD {
-			bx, by := float32(180), float32(60)
-			if gui.Button(rl.Rectangle{
-				X: (config.CameraWidth - bx) / 2, Y: (config.CameraHeight - by) / 2,
-				Width: bx, Height: by,
-			}, "Reconnect") {
-				err := conn.Send(netmsg.NewReconnectRequest(playerId))
+
+		switch status {
+		case SPECTATING:
+			rl.BeginMode2D(spec.camera)
+			drawWorld(curWorld, spec.following, visibleRect(spec.camera))
+			rl.EndMode2D()
+			spec.drawHud(mapper.Bindings)
+		case SHUTDOWN:
+			rl.DrawText(shutdownNote.Reason, 10, 10, 32, rl.Black)
+			// back to joining, on the server the hint points at or the same one
+			target, button := serverAddr, "Reconnect"
+			if shutdownNote.ReconnectHint != "" {
+				target, button = shutdownNote.ReconnectHint, "Join "+shutdownNote.ReconnectHint
+			}
+			if centerButton(button) {
+				newConn, newInput, newHangUp, err := dial(target)
 				if err != nil {
-					fmt.Println("failed to send reconnect request:", err)
+					netLog.Error("can't connect", "addr", target, logging.Err(err))
+					break
+				}
+				hangUp()
+				conn, serverInput, hangUp, serverAddr = newConn, newInput, newHangUp, target
+				curWorld, lastServerTick, playerId = &netmsg.WorldState{}, 0, 0
+				if err := conn.Send(joinRequest(*spectate)); err != nil {
+					netLog.Error("can't send connect request", logging.Err(err))
 				}
 				status = NONE
 			}
+		case REJECTED:
+			rl.DrawText("can't join: "+rejectReason, 10, 10, 32, rl.Black)
+			if centerButton("Spectate") {
+				err := conn.Send(netmsg.NewSpectateRequest("default"))
+				if err != nil {
+					netLog.Error("can't send spectate request", logging.Err(err))
+				}
+				status = NONE
+			}
+		default:
+			rl.BeginMode2D(camera)
+			drawWorld(curWorld, playerId, visibleRect(camera))
+			rl.EndMode2D()
+			rl.DrawText("HP : "+strconv.FormatInt(int64(myHealth), 10), 10, 10, 32, rl.Black)
+			weapon, _ := weapons.InSlot(mapper.Slot)
+			rl.DrawText(fmt.Sprintf("%s [%d]", weapon.Name, mapper.Slot+1), 10, 48, 24, rl.DarkGray)
+			drawEffectTimers(curWorld, playerId)
+		}
+
+		if mapper.Down(input.Scoreboard) && !settings.Open {
+			drawScoreboard(curWorld, playerId)
+		}
+
+		if status == DEAD && centerButton("Reconnect") {
+			err := conn.Send(netmsg.NewReconnectRequest(playerId))
+			if err != nil {
+				netLog.Error("can't send reconnect request", logging.Err(err))
+			}
+			status = NONE
+		}
+
+		if time.Now().Before(serverMsgUntil) {
+			drawServerMessage(serverMsg)
 		}
 
+		settings.Update()
 		rl.EndDrawing()
 	}
 }
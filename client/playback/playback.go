@@ -0,0 +1,191 @@
+package playback
+
+import (
+	"CircleWar/core/netmsg"
+	"CircleWar/core/replay"
+	"CircleWar/server/sim"
+	"errors"
+	"io"
+	"os"
+	"slices"
+	"time"
+)
+
+// ticks between keyframes, a seek simulates at most this many ticks
+const keyframeInterval = 300
+
+const (
+	MinSpeed = 0.25
+	MaxSpeed = 4.0
+)
+
+type keyframe struct {
+	pos      int
+	replayer *sim.Replayer
+	world    *netmsg.WorldState
+}
+
+// plays a replay back by re-simulating it. Pos is the number of ticks applied,
+// 0 is the world the replay starts from and Len is the end of the match
+type Playback struct {
+	Header    replay.Header
+	Paused    bool
+	ticks     []replay.Tick
+	playerIds []uint32
+	keyframes []keyframe
+	replayer  *sim.Replayer
+	pos       int
+	world     *netmsg.WorldState
+	speed     float64
+	acc       time.Duration
+}
+
+func Open(path string) (*Playback, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Load(file)
+}
+
+// reads the whole replay, a recording cut short by a killed server
+// plays up to its last complete tick
+func Load(r io.Reader) (*Playback, error) {
+	rd, err := replay.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	replayer, err := sim.NewReplayer(rd.Header)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Playback{
+		Header:   rd.Header,
+		replayer: replayer,
+		world:    rd.Header.InitialWorld,
+		speed:    1,
+	}
+	for _, player := range rd.Header.InitialWorld.Players {
+		p.addPlayerId(player.Id)
+	}
+	for {
+		tick, err := rd.Next()
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, ev := range tick.Events {
+			p.addPlayerId(ev.PlayerId)
+		}
+		p.ticks = append(p.ticks, tick)
+	}
+	p.keyframes = []keyframe{{0, replayer.Clone(), p.world}}
+
+	return p, nil
+}
+
+func (p *Playback) addPlayerId(id uint32) {
+	if !slices.Contains(p.playerIds, id) {
+		p.playerIds = append(p.playerIds, id)
+		slices.Sort(p.playerIds)
+	}
+}
+
+// every player that is in the replay at some point
+func (p *Playback) PlayerIds() []uint32 {
+	return p.playerIds
+}
+
+func (p *Playback) Len() int {
+	return len(p.ticks)
+}
+
+func (p *Playback) Pos() int {
+	return p.pos
+}
+
+func (p *Playback) World() *netmsg.WorldState {
+	return p.world
+}
+
+func (p *Playback) TickDuration() time.Duration {
+	return time.Second / time.Duration(p.Header.TicksPerSecond)
+}
+
+// applies the next tick, returns io.EOF at the end of the replay
+func (p *Playback) StepForward() error {
+	if p.pos >= len(p.ticks) {
+		return io.EOF
+	}
+	_, world, err := p.replayer.Apply(p.ticks[p.pos])
+	if err != nil {
+		return err
+	}
+	p.pos++
+	p.world = world
+
+	last := p.keyframes[len(p.keyframes)-1]
+	if p.pos%keyframeInterval == 0 && p.pos > last.pos {
+		p.keyframes = append(p.keyframes, keyframe{p.pos, p.replayer.Clone(), world})
+	}
+	return nil
+}
+
+// jumps to pos by restoring the closest keyframe before it and simulating
+// the rest, pos is clamped to the replay
+func (p *Playback) Seek(pos int) error {
+	pos = max(0, min(pos, len(p.ticks)))
+
+	i, found := slices.BinarySearchFunc(p.keyframes, pos, func(kf keyframe, pos int) int {
+		return kf.pos - pos
+	})
+	if !found {
+		i--
+	}
+	kf := p.keyframes[i]
+	if pos < p.pos || kf.pos > p.pos {
+		p.replayer = kf.replayer.Clone()
+		p.pos = kf.pos
+		p.world = kf.world
+	}
+
+	for p.pos < pos {
+		if err := p.StepForward(); err != nil {
+			return err
+		}
+	}
+	p.acc = 0
+	return nil
+}
+
+func (p *Playback) Speed() float64 {
+	return p.speed
+}
+
+func (p *Playback) SetSpeed(speed float64) {
+	p.speed = max(MinSpeed, min(speed, MaxSpeed))
+}
+
+// plays dt of real time at the current speed, pausing at the end
+func (p *Playback) Update(dt time.Duration) error {
+	if p.Paused {
+		return nil
+	}
+	p.acc += time.Duration(float64(dt) * p.speed)
+	for p.acc >= p.TickDuration() {
+		if p.pos >= len(p.ticks) {
+			p.Paused = true
+			p.acc = 0
+			return nil
+		}
+		if err := p.StepForward(); err != nil {
+			return err
+		}
+		p.acc -= p.TickDuration()
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package playback
+
+import (
+	"CircleWar/config"
+	"CircleWar/core/geom"
+	"CircleWar/core/netmsg"
+	"CircleWar/core/replay"
+	"CircleWar/server/sim"
+	wstate "CircleWar/server/world_state"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// the step at the default tick rate
+var fixedStep = time.Second / time.Duration(config.Default().TicksPerSecond)
+
+// records a match of two players where player 1 strafes and shoots at player 2
+func recordMatch(t *testing.T, ticks int) string {
+	path := filepath.Join(t.TempDir(), "match.replay")
+	cfg := config.Default()
+	sw := wstate.NewServerWorld(cfg.WorldWidth, cfg.WorldHeight, sim.NewStepClock(0))
+	rec, err := replay.NewRecorder(path, replay.Header{
+		TicksPerSecond: uint32(cfg.TicksPerSecond),
+		Width:          sw.Width(),
+		Height:         sw.Height(),
+		InitialWorld:   sim.NetworkWorldState(&sw),
+		Gameplay:       cfg.Gameplay,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr := net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 4000}
+	for i := range ticks {
+		if i == 0 {
+			for _, id := range []uint{1, 2} {
+				sim.ConnectPlayer(&sw, id, addr)
+				rec.RecordEvent(replay.PlayerEvent{Kind: replay.Connect, PlayerId: uint32(id), Addr: addr.String()})
+			}
+		}
+		inputs := map[uint]netmsg.PlayerInput{1: {PlayerId: 1, Actions: []netmsg.PlayerAction{
+			&netmsg.MoveAction{Dir: []netmsg.Direction{netmsg.LEFT, netmsg.RIGHT}[i/40%2]},
+			&netmsg.ShootAction{Target: geom.NewVector(500, 0)},
+		}}}
+
+		sim.Step(&sw, inputs, fixedStep)
+		netWorld := sim.NetworkWorldState(&sw)
+		rec.RecordTick(netWorld.TickNum, inputs, replay.Checksum(netWorld))
+		sw.NextTick()
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSeekMatchesPlaying(t *testing.T) {
+	const ticks = 2*keyframeInterval + 50
+	p, err := Open(recordMatch(t, ticks))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Len() != ticks {
+		t.Fatalf("got %d ticks want %d", p.Len(), ticks)
+	}
+
+	// checksums of every world while playing straight through
+	want := []uint64{replay.Checksum(p.World())}
+	for p.StepForward() == nil {
+		want = append(want, replay.Checksum(p.World()))
+	}
+
+	tests := []struct {
+		name string
+		pos  int
+	}{
+		{"back to start", 0},
+		{"forward past keyframes", ticks - 10},
+		{"back onto keyframe", keyframeInterval},
+		{"back before keyframe", keyframeInterval - 1},
+		{"small step forward", keyframeInterval + 3},
+		{"end", ticks},
+		{"clamped", ticks + 100},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := p.Seek(test.pos); err != nil {
+				t.Fatalf("Seek: %s", err)
+			}
+			wantPos := min(test.pos, ticks)
+			if p.Pos() != wantPos {
+				t.Fatalf("got pos %d want %d", p.Pos(), wantPos)
+			}
+			if got := replay.Checksum(p.World()); got != want[wantPos] {
+				t.Errorf("world at %d differs from playing straight through", wantPos)
+			}
+		})
+	}
+}
+
+func TestUpdateSpeed(t *testing.T) {
+	tests := []struct {
+		name      string
+		speed     float64
+		wantSpeed float64
+		frame     time.Duration
+		wantTicks int
+	}{
+		{"normal", 1, 1, 10 * fixedStep, 10},
+		{"double", 2, 2, 10 * fixedStep, 20},
+		{"quarter", 0.25, 0.25, 8 * fixedStep, 2},
+		{"too fast", 10, MaxSpeed, 10 * fixedStep, 40},
+		{"too slow", 0, MinSpeed, 8 * fixedStep, 2},
+	}
+
+	path := recordMatch(t, 100)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p.SetSpeed(test.speed)
+			if p.Speed() != test.wantSpeed {
+				t.Errorf("got speed %f want %f", p.Speed(), test.wantSpeed)
+			}
+			p.Update(test.frame)
+			if p.Pos() != test.wantTicks {
+				t.Errorf("got %d ticks want %d", p.Pos(), test.wantTicks)
+			}
+		})
+	}
+}
+
+func TestUpdatePausesAtEnd(t *testing.T) {
+	p, err := Open(recordMatch(t, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Update(time.Second)
+	if p.Pos() != 10 || !p.Paused {
+		t.Errorf("got pos %d paused %t want 10 paused", p.Pos(), p.Paused)
+	}
+	if ids := p.PlayerIds(); len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Errorf("got player ids %v", ids)
+	}
+}
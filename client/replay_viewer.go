@@ -0,0 +1,128 @@
+package main
+
+import (
+	"CircleWar/client/playback"
+	"fmt"
+	"slices"
+	"time"
+
+	gui "github.com/gen2brain/raylib-go/raygui"
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	// how far the arrow keys seek
+	replaySeekStep = 5 * time.Second
+	timelineHeight = 24
+)
+
+type replayViewer struct {
+	playback  *playback.Playback
+	highlight uint32 // player drawn as if it was us, the camera follows them
+	camera    rl.Camera2D
+}
+
+func (rv *replayViewer) seekBy(d time.Duration) error {
+	p := rv.playback
+	return p.Seek(p.Pos() + int(d/p.TickDuration()))
+}
+
+func (rv *replayViewer) nextHighlight() {
+	ids := rv.playback.PlayerIds()
+	if len(ids) == 0 {
+		return
+	}
+	i := slices.Index(ids, rv.highlight)
+	rv.highlight = ids[(i+1)%len(ids)]
+}
+
+func (rv *replayViewer) handleKeys() error {
+	p := rv.playback
+	switch {
+	case rl.IsKeyPressed(rl.KeySpace):
+		p.Paused = !p.Paused
+	case rl.IsKeyPressed(rl.KeyUp):
+		p.SetSpeed(p.Speed() * 2)
+	case rl.IsKeyPressed(rl.KeyDown):
+		p.SetSpeed(p.Speed() / 2)
+	case rl.IsKeyPressed(rl.KeyRight):
+		return rv.seekBy(replaySeekStep)
+	case rl.IsKeyPressed(rl.KeyLeft):
+		return rv.seekBy(-replaySeekStep)
+	case rl.IsKeyPressed(rl.KeyTab):
+		rv.nextHighlight()
+	}
+	return nil
+}
+
+func formatReplayTime(ticks int, tickDur time.Duration) string {
+	d := time.Duration(ticks) * tickDur
+	return fmt.Sprintf("%02d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
+
+// draws the timeline and seeks when it is dragged
+func (rv *replayViewer) timeline() error {
+	p := rv.playback
+	bounds := rl.Rectangle{
+		X: 70, Y: float32(cfg.CameraHeight) - timelineHeight - 8,
+		Width: float32(cfg.CameraWidth) - 140, Height: timelineHeight,
+	}
+	pos := gui.SliderBar(bounds,
+		formatReplayTime(p.Pos(), p.TickDuration()),
+		formatReplayTime(p.Len(), p.TickDuration()),
+		float32(p.Pos()), 0, float32(p.Len()),
+	)
+	if int(pos) != p.Pos() {
+		return p.Seek(int(pos))
+	}
+	return nil
+}
+
+func (rv *replayViewer) drawHud() {
+	p := rv.playback
+	status := fmt.Sprintf("%gx", p.Speed())
+	if p.Paused {
+		status = "paused"
+	}
+	rl.DrawText(fmt.Sprintf("REPLAY  %s  player %d", status, rv.highlight), 10, 10, 32, rl.Black)
+	rl.DrawText("space pause  up/down speed  left/right seek  tab switch player", 10, 48, 20, rl.DarkGray)
+}
+
+func runReplayViewer(path string) error {
+	p, err := playback.Open(path)
+	if err != nil {
+		return err
+	}
+	rv := &replayViewer{
+		playback: p,
+		camera:   newCamera(rl.NewVector2(p.Header.Width/2, p.Header.Height/2)),
+	}
+	rv.nextHighlight()
+
+	for !rl.WindowShouldClose() {
+		if err := rv.handleKeys(); err != nil {
+			return err
+		}
+		frameTime := time.Duration(float64(rl.GetFrameTime()) * float64(time.Second))
+		if err := p.Update(frameTime); err != nil {
+			return err
+		}
+
+		if player, ok := findPlayer(p.World(), rv.highlight); ok {
+			followCamera(&rv.camera, player.Pos)
+		}
+
+		rl.BeginDrawing()
+		rl.ClearBackground(rl.NewColor(253, 245, 203, 100))
+		rl.BeginMode2D(rv.camera)
+		drawWorld(p.World(), rv.highlight, visibleRect(rv.camera))
+		rl.EndMode2D()
+		rv.drawHud()
+		err := rv.timeline()
+		rl.EndDrawing()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"CircleWar/client/input"
+	"strings"
+
+	gui "github.com/gen2brain/raylib-go/raygui"
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	// every action fits above the buttons
+	settingsRowHeight = 34
+	settingsTop       = 70
+)
+
+// screen to rebind the controls of mapper and save them to path
+type settingsScreen struct {
+	Open      bool
+	mapper    *input.Mapper
+	path      string
+	capturing bool
+	action    input.Action // the one the next button press is added to
+	status    string
+}
+
+func newSettingsScreen(mapper *input.Mapper, path string) *settingsScreen {
+	return &settingsScreen{mapper: mapper, path: path}
+}
+
+// the key, mouse button or gamepad button pressed this frame
+func pressedBinding() (input.Binding, bool) {
+	if key := rl.GetKeyPressed(); key != rl.KeyNull {
+		return input.KeyOf(input.Key(key)), true
+	}
+	for button := rl.MouseButtonLeft; button <= rl.MouseButtonMiddle; button++ {
+		if rl.IsMouseButtonPressed(button) {
+			return input.MouseOf(input.MouseButton(button)), true
+		}
+	}
+	for button := int32(rl.GamepadButtonLeftFaceUp); button <= rl.GamepadButtonRightThumb; button++ {
+		if rl.IsGamepadButtonPressed(0, button) {
+			return input.PadOf(input.GamepadButton(button)), true
+		}
+	}
+	return input.Binding{}, false
+}
+
+func (s *settingsScreen) startCapture(action input.Action) {
+	s.capturing = true
+	s.action = action
+	s.status = "press a key or button for " + action.String() + ", escape cancels"
+	// escape has to cancel instead of closing the window
+	rl.SetExitKey(rl.KeyNull)
+}
+
+func (s *settingsScreen) stopCapture() {
+	s.capturing = false
+	s.status = ""
+	rl.SetExitKey(rl.KeyEscape)
+}
+
+func (s *settingsScreen) capture() {
+	b, ok := pressedBinding()
+	if !ok {
+		return
+	}
+	if b != input.KeyOf(input.Key(rl.KeyEscape)) {
+		s.mapper.Bindings.Add(s.action, b)
+	}
+	s.stopCapture()
+}
+
+func bindingsText(bindings []input.Binding) string {
+	names := []string{}
+	for _, b := range bindings {
+		names = append(names, b.String())
+	}
+	if len(names) == 0 {
+		return "unbound"
+	}
+	return strings.Join(names, ", ")
+}
+
+// draws the screen and handles its buttons, f1 opens and closes it
+func (s *settingsScreen) Update() {
+	if rl.IsKeyPressed(rl.KeyF1) && !s.capturing {
+		s.Open = !s.Open
+	}
+	if !s.Open {
+		if gui.Button(rl.Rectangle{X: float32(cfg.CameraWidth) - 130, Y: 10, Width: 120, Height: 30}, "Controls") {
+			s.Open = true
+		}
+		return
+	}
+	if s.capturing {
+		s.capture()
+	}
+
+	rl.DrawRectangle(0, 0, int32(cfg.CameraWidth), int32(cfg.CameraHeight), rl.Fade(rl.RayWhite, 0.92))
+	rl.DrawText("CONTROLS", 40, 24, 32, rl.Black)
+	for i, action := range input.Actions {
+		y := float32(settingsTop + i*settingsRowHeight)
+		rl.DrawText(action.String(), 40, int32(y)+6, 20, rl.Black)
+		rl.DrawText(bindingsText(s.mapper.Bindings[action]), 220, int32(y)+6, 20, rl.DarkGray)
+		if gui.Button(rl.Rectangle{X: 700, Y: y, Width: 130, Height: 30}, "Add") && !s.capturing {
+			s.startCapture(action)
+		}
+		if gui.Button(rl.Rectangle{X: 840, Y: y, Width: 130, Height: 30}, "Clear") && !s.capturing {
+			s.mapper.Bindings[action] = nil
+		}
+	}
+
+	buttonsY := float32(cfg.CameraHeight - 80)
+	if gui.Button(rl.Rectangle{X: 40, Y: buttonsY, Width: 150, Height: 40}, "Save") {
+		s.status = "saved to " + s.path
+		if err := s.mapper.Bindings.Save(s.path); err != nil {
+			s.status = "can't save: " + err.Error()
+		}
+	}
+	if gui.Button(rl.Rectangle{X: 200, Y: buttonsY, Width: 150, Height: 40}, "Defaults") {
+		s.mapper.Bindings = input.DefaultBindings()
+	}
+	if gui.Button(rl.Rectangle{X: 360, Y: buttonsY, Width: 150, Height: 40}, "Back") {
+		if s.capturing {
+			s.stopCapture()
+		}
+		s.Open = false
+	}
+	rl.DrawText(s.status, 40, int32(buttonsY)-36, 20, rl.DarkGray)
+}
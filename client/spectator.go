@@ -0,0 +1,101 @@
+package main
+
+import (
+	"CircleWar/client/input"
+	"CircleWar/config"
+	"CircleWar/core/netmsg"
+	"fmt"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	// world units per second at zoom 1
+	spectatorPanSpeed = 900
+	spectatorMinZoom  = 1
+	spectatorMaxZoom  = 3
+	spectatorZoomStep = 0.25
+)
+
+// camera of someone watching, free roaming or following a player
+type spectator struct {
+	camera    rl.Camera2D
+	following uint32 // 0 when free roaming
+}
+
+func newSpectator() *spectator {
+	return &spectator{camera: newCamera(rl.NewVector2(config.Current().WorldWidth/2, config.Current().WorldHeight/2))}
+}
+
+func findPlayer(world *netmsg.WorldState, id uint32) (*netmsg.PlayerState, bool) {
+	for _, player := range world.Players {
+		if player.Id == id {
+			return player, true
+		}
+	}
+	return nil, false
+}
+
+// follows the player with the next higher id, wrapping around
+func (s *spectator) followNext(world *netmsg.WorldState) {
+	var lowest, next uint32
+	for _, player := range world.Players {
+		if lowest == 0 || player.Id < lowest {
+			lowest = player.Id
+		}
+		if player.Id > s.following && (next == 0 || player.Id < next) {
+			next = player.Id
+		}
+	}
+	if next == 0 {
+		next = lowest
+	}
+	s.following = next
+}
+
+func (s *spectator) update(world *netmsg.WorldState, mapper *input.Mapper, frameTime float32) {
+	if mapper.Pressed(input.FollowNext) {
+		s.followNext(world)
+	}
+	if rl.IsKeyPressed(rl.KeyF) {
+		s.following = 0
+	}
+
+	zoom := s.camera.Zoom + rl.GetMouseWheelMove()*spectatorZoomStep
+	s.camera.Zoom = max(spectatorMinZoom, min(zoom, spectatorMaxZoom))
+
+	pan := rl.Vector2{}
+	if rl.IsKeyDown(rl.KeyW) {
+		pan.Y -= 1
+	}
+	if rl.IsKeyDown(rl.KeyS) {
+		pan.Y += 1
+	}
+	if rl.IsKeyDown(rl.KeyA) {
+		pan.X -= 1
+	}
+	if rl.IsKeyDown(rl.KeyD) {
+		pan.X += 1
+	}
+	if pan.X != 0 || pan.Y != 0 {
+		s.following = 0
+		pan = rl.Vector2Scale(rl.Vector2Normalize(pan), spectatorPanSpeed*frameTime/s.camera.Zoom)
+		s.camera.Target = rl.Vector2Add(s.camera.Target, pan)
+	}
+
+	// a dead player keeps being followed, the camera waits where they died
+	if player, ok := findPlayer(world, s.following); ok {
+		s.camera.Target = rl.NewVector2(player.Pos.X, player.Pos.Y)
+	}
+	clampCamera(&s.camera, config.Current().WorldWidth, config.Current().WorldHeight)
+}
+
+func (s *spectator) drawHud(bindings input.Bindings) {
+	watching := "free roam"
+	if s.following != 0 {
+		watching = fmt.Sprintf("following player %d", s.following)
+	}
+	rl.DrawText("SPECTATING  "+watching, 10, 10, 32, rl.Black)
+	help := "follow next player (" + bindingsText(bindings[input.FollowNext]) + ")  f free roam  wasd move  wheel zoom"
+	rl.DrawText(help, 10, 48, 20, rl.DarkGray)
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"CircleWar/config"
+	"CircleWar/core/geom"
+	"CircleWar/core/netmsg"
+	"CircleWar/core/network/gameConn"
+	"CircleWar/core/weapons"
+	"errors"
+	"math"
+	"math/rand"
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	handshakeTimeout = 5 * time.Second
+	pingInterval     = 250 * time.Millisecond
+	// how long a bot keeps walking in the same directions
+	moveHoldTime = 500 * time.Millisecond
+	shootChance  = 0.3
+	dashChance   = 0.02
+)
+
+type BotReport struct {
+	Bot             int     `json:"bot"`
+	PlayerId        uint32  `json:"player_id"`
+	Connected       bool    `json:"connected"`
+	Deaths          int     `json:"deaths"`
+	Reconnects      int     `json:"reconnects"`
+	RttAvgMs        float64 `json:"rtt_avg_ms"`
+	RttMinMs        float64 `json:"rtt_min_ms"`
+	RttMaxMs        float64 `json:"rtt_max_ms"`
+	PingsSent       int     `json:"pings_sent"`
+	PongsReceived   int     `json:"pongs_received"`
+	Snapshots       int     `json:"snapshots"`
+	SnapshotsPerSec float64 `json:"snapshots_per_sec"`
+	PacketLoss      float64 `json:"packet_loss"`
+	RecvErrors      uint64  `json:"recv_errors"`
+	BytesInPerSec   float64 `json:"bytes_in_per_sec"`
+	BytesOutPerSec  float64 `json:"bytes_out_per_sec"`
+}
+
+type bot struct {
+	idx        int
+	conn       *gameConn.ClientConn
+	rng        *rand.Rand
+	msgs       chan netmsg.GameMessage
+	recvErrors atomic.Uint64
+
+	playerId             uint32
+	gameplay             config.Gameplay // the server's, from the handshake
+	alive                bool
+	connected, stoppedAt time.Time
+	moveDirs             []netmsg.Direction
+	moveTill             time.Time
+
+	deaths, reconnects int
+	pingSeq            uint32
+	pongs              int
+	rtts               []time.Duration
+
+	// snapshot ticks, used for packet loss
+	snapshots          int
+	firstTick, maxTick uint32
+}
+
+func newBot(idx int, servAddr *net.UDPAddr, seed int64) (*bot, error) {
+	conn, err := gameConn.NewClientConn(servAddr)
+	if err != nil {
+		return nil, err
+	}
+	return &bot{
+		idx:  idx,
+		conn: conn,
+		rng:  rand.New(rand.NewSource(seed)),
+		msgs: make(chan netmsg.GameMessage, 256),
+	}, nil
+}
+
+func (b *bot) receive() {
+	for {
+		msg, err := b.conn.Recieve()
+		if errors.Is(err, net.ErrClosed) {
+			close(b.msgs)
+			return
+		}
+		if err != nil {
+			b.recvErrors.Add(1)
+			continue
+		}
+		select {
+		case b.msgs <- msg:
+		default: // bot fell behind, count it like a lost packet
+			b.recvErrors.Add(1)
+		}
+	}
+}
+
+func (b *bot) handshake() error {
+	// not resent on timeout, every ConnectRequest spawns a new player
+	deadline := time.After(handshakeTimeout)
+	if err := b.conn.Send(netmsg.NewConnectRequest("default")); err != nil {
+		return err
+	}
+	for {
+		select {
+		case msg, ok := <-b.msgs:
+			if !ok {
+				return net.ErrClosed
+			}
+			if ack, ok := msg.(*netmsg.ConnectAck); ok {
+				b.playerId = ack.PlayerId
+				b.gameplay = ack.Gameplay
+				b.alive = true
+				b.connected = time.Now()
+				return nil
+			}
+		case <-deadline:
+			return errors.New("no connect ack from server")
+		}
+	}
+}
+
+func (b *bot) randomInput(now time.Time) *netmsg.PlayerInput {
+	if now.After(b.moveTill) {
+		b.moveDirs = b.moveDirs[:0]
+		if b.rng.Intn(2) == 0 {
+			b.moveDirs = append(b.moveDirs, []netmsg.Direction{netmsg.LEFT, netmsg.RIGHT}[b.rng.Intn(2)])
+		}
+		if b.rng.Intn(2) == 0 {
+			b.moveDirs = append(b.moveDirs, []netmsg.Direction{netmsg.UP, netmsg.DOWN}[b.rng.Intn(2)])
+		}
+		b.moveTill = now.Add(moveHoldTime)
+	}
+
+	input := &netmsg.PlayerInput{PlayerId: b.playerId}
+	for _, dir := range b.moveDirs {
+		input.Actions = append(input.Actions, &netmsg.MoveAction{Dir: dir})
+	}
+	if b.rng.Float64() < shootChance {
+		target := geom.NewVector(
+			b.rng.Float32()*b.gameplay.WorldWidth,
+			b.rng.Float32()*b.gameplay.WorldHeight,
+		)
+		slot := uint32(b.rng.Intn(len(weapons.Loadout)))
+		input.Actions = append(input.Actions, &netmsg.ShootAction{Target: target, Slot: slot})
+	}
+	if b.rng.Float64() < dashChance {
+		input.Actions = append(input.Actions, &netmsg.DashAction{})
+	}
+	return input
+}
+
+func (b *bot) handleMsg(msg netmsg.GameMessage) {
+	switch m := msg.(type) {
+	case *netmsg.WorldState:
+		if b.snapshots == 0 {
+			b.firstTick, b.maxTick = m.TickNum, m.TickNum
+		}
+		b.snapshots++
+		b.firstTick = min(b.firstTick, m.TickNum)
+		b.maxTick = max(b.maxTick, m.TickNum)
+	case *netmsg.Ping:
+		// the server measures our ping too
+		b.conn.Send(netmsg.NewPong(m.Seq, m.SentNano))
+	case *netmsg.Pong:
+		b.pongs++
+		b.rtts = append(b.rtts, time.Since(time.Unix(0, m.SentNano)))
+	case *netmsg.DeathNote:
+		if m.PlayerId != b.playerId {
+			break
+		}
+		b.deaths++
+		b.alive = false
+		b.conn.Send(netmsg.NewReconnectRequest(b.playerId))
+	case *netmsg.GameplayUpdate:
+		b.gameplay = m.Gameplay
+	case *netmsg.ServerShutdown:
+		// nothing to send input to anymore
+		b.alive = false
+	case *netmsg.ConnectAck:
+		if !b.alive {
+			b.reconnects++
+		}
+		b.playerId = m.PlayerId
+		b.alive = true
+	}
+}
+
+// sends input at the client frame rate until stop is closed
+func (b *bot) run(stop <-chan struct{}) {
+	frame := time.NewTicker(time.Second / time.Duration(config.Default().ClientFPS))
+	defer frame.Stop()
+	ping := time.NewTicker(pingInterval)
+	defer ping.Stop()
+	defer func() { b.stoppedAt = time.Now() }()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case msg, ok := <-b.msgs:
+			if !ok {
+				return
+			}
+			b.handleMsg(msg)
+		case now := <-frame.C:
+			if b.alive {
+				b.conn.Send(b.randomInput(now))
+			}
+		case now := <-ping.C:
+			b.pingSeq++
+			b.conn.Send(netmsg.NewPing(b.pingSeq, now.UnixNano()))
+		}
+	}
+}
+
+// rates are per second of the bot being connected
+func (b *bot) report() BotReport {
+	report := BotReport{Bot: b.idx}
+	if b.connected.IsZero() {
+		return report
+	}
+
+	secs := b.stoppedAt.Sub(b.connected).Seconds()
+	stats := b.conn.Stats()
+	report = BotReport{
+		Bot:             b.idx,
+		PlayerId:        b.playerId,
+		Connected:       true,
+		Deaths:          b.deaths,
+		Reconnects:      b.reconnects,
+		PingsSent:       int(b.pingSeq),
+		PongsReceived:   b.pongs,
+		Snapshots:       b.snapshots,
+		SnapshotsPerSec: float64(b.snapshots) / secs,
+		RecvErrors:      b.recvErrors.Load(),
+		BytesInPerSec:   float64(stats.BytesIn) / secs,
+		BytesOutPerSec:  float64(stats.BytesOut) / secs,
+	}
+
+	if b.snapshots > 0 {
+		expected := float64(b.maxTick-b.firstTick) + 1
+		report.PacketLoss = math.Max(0, 1-float64(b.snapshots)/expected)
+	}
+
+	if len(b.rtts) > 0 {
+		var total time.Duration
+		lo, hi := b.rtts[0], b.rtts[0]
+		for _, rtt := range b.rtts {
+			total += rtt
+			lo, hi = min(lo, rtt), max(hi, rtt)
+		}
+		report.RttAvgMs = msec(total / time.Duration(len(b.rtts)))
+		report.RttMinMs = msec(lo)
+		report.RttMaxMs = msec(hi)
+	}
+
+	return report
+}
+
+func msec(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"CircleWar/config"
+	envdata "CircleWar/env/env_data"
+	envloader "CircleWar/env/env_loader"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+type RunReport struct {
+	Server      string      `json:"server"`
+	Bots        int         `json:"bots"`
+	Started     time.Time   `json:"started"`
+	DurationSec float64     `json:"duration_sec"`
+	Summary     BotReport   `json:"summary"`
+	Results     []BotReport `json:"results"`
+}
+
+// averages of every connected bot, Bot is set to -1
+func summarize(results []BotReport) BotReport {
+	sum := BotReport{Bot: -1}
+	n := 0
+	for _, r := range results {
+		if !r.Connected {
+			continue
+		}
+		if n == 0 || r.RttMinMs < sum.RttMinMs {
+			sum.RttMinMs = r.RttMinMs
+		}
+		sum.RttMaxMs = max(sum.RttMaxMs, r.RttMaxMs)
+		sum.RttAvgMs += r.RttAvgMs
+		sum.SnapshotsPerSec += r.SnapshotsPerSec
+		sum.PacketLoss += r.PacketLoss
+		sum.BytesInPerSec += r.BytesInPerSec
+		sum.BytesOutPerSec += r.BytesOutPerSec
+		sum.Deaths += r.Deaths
+		sum.Reconnects += r.Reconnects
+		sum.PingsSent += r.PingsSent
+		sum.PongsReceived += r.PongsReceived
+		sum.Snapshots += r.Snapshots
+		sum.RecvErrors += r.RecvErrors
+		n++
+	}
+	if n > 0 {
+		sum.Connected = true
+		sum.RttAvgMs /= float64(n)
+		sum.SnapshotsPerSec /= float64(n)
+		sum.PacketLoss /= float64(n)
+		sum.BytesInPerSec /= float64(n)
+		sum.BytesOutPerSec /= float64(n)
+	}
+	return sum
+}
+
+func main() {
+	numBots := flag.Int("bots", 50, "number of bots to connect")
+	duration := flag.Duration("duration", 30*time.Second, "how long to run after connecting")
+	ramp := flag.Duration("ramp", 20*time.Millisecond, "delay between bot connects")
+	server := flag.String("server", "", "server host:port (default SERVER_IP from .env)")
+	out := flag.String("out", "-", "report file, - for stdout")
+	seed := flag.Int64("seed", time.Now().UnixNano(), "random seed for bot inputs")
+	envOverride := flag.Bool("env-override", false, ".env values replace variables already set in the environment")
+	flag.Parse()
+
+	if err := envloader.Load(envdata.EnvfilePath(), envloader.Options{Override: *envOverride}); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Fatal(err)
+	}
+
+	if *server == "" {
+		*server = fmt.Sprintf("%s:%d", envloader.GetEnv("SERVER_IP", "127.0.0.1"), config.Default().Port)
+	}
+	servAddr, err := net.ResolveUDPAddr("udp", *server)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	bots := []*bot{}
+	for i := range *numBots {
+		b, err := newBot(i, servAddr, *seed+int64(i))
+		if err != nil {
+			log.Fatal(err)
+		}
+		go b.receive()
+		bots = append(bots, b)
+	}
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	started := time.Now()
+	for _, b := range bots {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := b.handshake(); err != nil {
+				log.Printf("bot %d: %s", b.idx, err)
+				return
+			}
+			b.run(stop)
+		}()
+		time.Sleep(*ramp)
+	}
+
+	time.Sleep(*duration)
+	close(stop)
+	wg.Wait()
+	elapsed := time.Since(started)
+
+	report := RunReport{
+		Server:      servAddr.String(),
+		Bots:        *numBots,
+		Started:     started,
+		DurationSec: elapsed.Seconds(),
+	}
+	for _, b := range bots {
+		b.conn.Close()
+		report.Results = append(report.Results, b.report())
+	}
+	report.Summary = summarize(report.Results)
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *out == "-" {
+		fmt.Println(string(data))
+		return
+	}
+	if err := os.WriteFile(*out, data, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"CircleWar/core/netmsg"
+	"CircleWar/core/pickups"
+	"CircleWar/core/replay"
+	"CircleWar/core/weapons"
+	"CircleWar/server/sim"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+)
+
+func printWorld(ws *netmsg.WorldState) {
+	for _, player := range ws.Players {
+		fmt.Printf("  player %d at %s health %.1f\n", player.Id, player.Pos, player.Health)
+	}
+	for _, bullet := range ws.Bullets {
+		fmt.Printf("  bullet of %d at %s size %.1f\n", bullet.OwnerId, bullet.Pos, bullet.Size)
+	}
+	for _, pickup := range ws.Pickups {
+		fmt.Printf("  %s pickup %d at %s\n", pickups.Get(pickup.Kind).Name, pickup.Id, pickup.Pos)
+	}
+}
+
+// re-simulates the replay and stops at the first tick whose world does not
+// match the recorded checksum
+func verify(rd *replay.Reader, verbose bool) (int, error) {
+	replayer, err := sim.NewReplayer(rd.Header)
+	if err != nil {
+		return 0, err
+	}
+
+	ticks := 0
+	for {
+		tick, err := rd.Next()
+		if errors.Is(err, io.EOF) {
+			return ticks, nil
+		}
+		if err != nil {
+			return ticks, err
+		}
+
+		results, netWorld, err := replayer.Apply(tick)
+		if err != nil {
+			return ticks, err
+		}
+		checksum := replay.Checksum(netWorld)
+		if verbose {
+			fmt.Printf("tick %d: %d players %d bullets checksum %016x\n",
+				tick.TickNum, len(netWorld.Players), len(netWorld.Bullets), checksum)
+			for _, hit := range results.Hits {
+				if hit.Blocked {
+					fmt.Printf("  player %d blocked the %s of %d\n", hit.TargetId, weapons.Get(hit.Weapon).Name, hit.ShooterId)
+					continue
+				}
+				fmt.Printf("  player %d hit %d with the %s for %d\n", hit.ShooterId, hit.TargetId, weapons.Get(hit.Weapon).Name, hit.Damage)
+			}
+		}
+		if checksum != tick.Checksum {
+			fmt.Printf("simulated world at tick %d:\n", tick.TickNum)
+			printWorld(netWorld)
+			return ticks, fmt.Errorf("diverged at tick %d: recorded checksum %016x, simulated %016x",
+				tick.TickNum, tick.Checksum, checksum)
+		}
+
+		ticks++
+	}
+}
+
+func main() {
+	verbose := flag.Bool("v", false, "print every tick")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [-v] <replay file>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	file, err := os.Open(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+
+	rd, err := replay.NewReader(file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("replay recorded %s, %d ticks per second, world %.0fx%.0f\n",
+		rd.Header.Started.Format("2006-01-02 15:04:05"), rd.Header.TicksPerSecond, rd.Header.Width, rd.Header.Height)
+
+	ticks, err := verify(rd, *verbose)
+	if err != nil {
+		fmt.Printf("after %d matching ticks: %s\n", ticks, err)
+		os.Exit(1)
+	}
+	fmt.Printf("verified %d ticks, no divergence\n", ticks)
+}
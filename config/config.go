@@ -0,0 +1,194 @@
+// the game's settings. the server and the client load a GameConfig at
+// startup, see Load, and the server sends its Gameplay to clients when they
+// join so both sides agree on sizes and speeds
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync/atomic"
+	"time"
+)
+
+// what the simulation and the hitboxes depend on, the same on the server
+// and its clients
+type Gameplay struct {
+	WorldWidth  float32 `json:"world_width"`
+	WorldHeight float32 `json:"world_height"`
+
+	PlayerSpeed float32 `json:"player_speed"`
+
+	// a dash moves at DashSpeed for DashDurationMS, bullets pass through the
+	// dashing player for the first DashInvulnerableMS of it
+	DashSpeed          float32 `json:"dash_speed"`
+	DashDurationMS     int     `json:"dash_duration_ms"`
+	DashInvulnerableMS int     `json:"dash_invulnerable_ms"`
+	DashCooldownMS     int     `json:"dash_cooldown_ms"`
+
+	// overlapping players are pushed apart, with body block bigger players push
+	// smaller ones further than they get pushed, otherwise both move the same
+	BodyBlock bool `json:"body_block"`
+
+	// the pistol, the other weapons are in core/weapons
+	BulletSpeed         float32 `json:"bullet_speed"`
+	BulletTimeToLiveSec float32 `json:"bullet_time_to_live_sec"`
+	BulletCooldownMS    int     `json:"bullet_cooldown_ms"`
+
+	// players and bullets shrink as the player losses hp
+	InitialPlayerHealth float32 `json:"initial_player_health"`
+	InitialPlayerSize   float32 `json:"initial_player_size"`
+	PlayerShrinkStep    float32 `json:"player_shrink_step"`
+
+	InitialBulletSize float32 `json:"initial_bullet_size"`
+	BulletShrinkStep  float32 `json:"bullet_shrink_step"`
+}
+
+type GameConfig struct {
+	Gameplay
+
+	Port int `json:"port"`
+
+	// rate at which clients render and send input
+	ClientFPS int `json:"client_fps"`
+
+	TicksPerSecond int `json:"ticks_per_second"`
+
+	// players that can join a game, spectators don't count
+	MaxPlayers int `json:"max_players"`
+
+	CameraWidth  int `json:"camera_width"`
+	CameraHeight int `json:"camera_height"`
+
+	// clients get snapshots of what their camera sees plus this margin, and at
+	// most SnapshotBudget bytes of it, under the 1024 byte receive buffer
+	InterestMargin float32 `json:"interest_margin"`
+	SnapshotBudget int     `json:"snapshot_budget"`
+}
+
+func Default() GameConfig {
+	return GameConfig{
+		Gameplay: Gameplay{
+			WorldWidth:  3060,
+			WorldHeight: 2040,
+
+			PlayerSpeed: 1100,
+
+			DashSpeed:          3600,
+			DashDurationMS:     150,
+			DashInvulnerableMS: 120,
+			DashCooldownMS:     1500,
+
+			BodyBlock: true,
+
+			BulletSpeed:         1800,
+			BulletTimeToLiveSec: 1.5,
+			BulletCooldownMS:    180,
+
+			InitialPlayerHealth: 20,
+			InitialPlayerSize:   48,
+			PlayerShrinkStep:    1,
+
+			InitialBulletSize: 20,
+			BulletShrinkStep:  0.5,
+		},
+		Port:           23532,
+		ClientFPS:      60,
+		TicksPerSecond: 60,
+		MaxPlayers:     64,
+		CameraWidth:    1020,
+		CameraHeight:   680,
+		InterestMargin: 200,
+		SnapshotBudget: 1000,
+	}
+}
+
+func (g Gameplay) DashDuration() time.Duration {
+	return time.Duration(g.DashDurationMS) * time.Millisecond
+}
+
+func (g Gameplay) DashInvulnerable() time.Duration {
+	return time.Duration(g.DashInvulnerableMS) * time.Millisecond
+}
+
+func (g Gameplay) DashCooldown() time.Duration {
+	return time.Duration(g.DashCooldownMS) * time.Millisecond
+}
+
+func (g Gameplay) BulletCooldown() time.Duration {
+	return time.Duration(g.BulletCooldownMS) * time.Millisecond
+}
+
+// every problem with the values joined into one error, nil when they are fine
+func (g Gameplay) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+	check(g.PlayerSpeed > 0, "player_speed must be positive, got %g", g.PlayerSpeed)
+	check(g.DashSpeed > 0, "dash_speed must be positive, got %g", g.DashSpeed)
+	check(g.DashDurationMS >= 0, "dash_duration_ms can't be negative, got %d", g.DashDurationMS)
+	check(g.DashInvulnerableMS >= 0 && g.DashInvulnerableMS <= g.DashDurationMS,
+		"dash_invulnerable_ms must be between 0 and dash_duration_ms, got %d", g.DashInvulnerableMS)
+	check(g.DashCooldownMS >= 0, "dash_cooldown_ms can't be negative, got %d", g.DashCooldownMS)
+	check(g.BulletSpeed > 0, "bullet_speed must be positive, got %g", g.BulletSpeed)
+	check(g.BulletTimeToLiveSec > 0, "bullet_time_to_live_sec must be positive, got %g", g.BulletTimeToLiveSec)
+	check(g.BulletCooldownMS >= 0, "bullet_cooldown_ms can't be negative, got %d", g.BulletCooldownMS)
+	check(g.InitialPlayerHealth >= 1, "initial_player_health must be at least 1, got %g", g.InitialPlayerHealth)
+	check(g.PlayerShrinkStep >= 0, "player_shrink_step can't be negative, got %g", g.PlayerShrinkStep)
+	check(g.BulletShrinkStep >= 0, "bullet_shrink_step can't be negative, got %g", g.BulletShrinkStep)
+	// a player on its last hp still has a body and shoots something
+	lost := g.InitialPlayerHealth - 1
+	check(g.InitialPlayerSize-lost*g.PlayerShrinkStep > 0,
+		"players shrink to nothing, initial_player_size %g is too small for the shrink step", g.InitialPlayerSize)
+	check(g.InitialBulletSize-lost*g.BulletShrinkStep > 0,
+		"bullets shrink to nothing, initial_bullet_size %g is too small for the shrink step", g.InitialBulletSize)
+	check(g.WorldWidth >= 2*g.InitialPlayerSize && g.WorldHeight >= 2*g.InitialPlayerSize,
+		"world %gx%g is too small for players of size %g", g.WorldWidth, g.WorldHeight, g.InitialPlayerSize)
+	return errors.Join(errs...)
+}
+
+// tells two gameplays apart without sending them, never 0
+func (g Gameplay) Checksum() uint32 {
+	data, _ := json.Marshal(g)
+	h := fnv.New32a()
+	h.Write(data)
+	return max(1, h.Sum32())
+}
+
+func (c GameConfig) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+	check(c.Port > 0 && c.Port <= 65535, "port must be between 1 and 65535, got %d", c.Port)
+	check(c.ClientFPS > 0, "client_fps must be positive, got %d", c.ClientFPS)
+	check(c.TicksPerSecond > 0 && c.TicksPerSecond <= 1000, "ticks_per_second must be between 1 and 1000, got %d", c.TicksPerSecond)
+	check(c.MaxPlayers > 0, "max_players must be positive, got %d", c.MaxPlayers)
+	check(c.CameraWidth > 0 && c.CameraHeight > 0, "camera %dx%d must have a positive size", c.CameraWidth, c.CameraHeight)
+	check(c.InterestMargin >= 0, "interest_margin can't be negative, got %g", c.InterestMargin)
+	check(c.SnapshotBudget > 0 && c.SnapshotBudget <= 1024, "snapshot_budget must be between 1 and 1024, got %d", c.SnapshotBudget)
+	return errors.Join(append([]error{c.Gameplay.Validate()}, errs...)...)
+}
+
+var current atomic.Pointer[Gameplay]
+
+func init() {
+	g := Default().Gameplay
+	current.Store(&g)
+}
+
+// the gameplay everything in this process plays by, the defaults until
+// SetCurrent. the server sets its config's, clients the one from the handshake
+func Current() Gameplay {
+	return *current.Load()
+}
+
+func SetCurrent(g Gameplay) {
+	current.Store(&g)
+}
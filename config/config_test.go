@@ -0,0 +1,184 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDefaultIsValid(t *testing.T) {
+	if err := Default().Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	a, b := Default().Gameplay, Default().Gameplay
+	if a.Checksum() != b.Checksum() {
+		t.Error("equal gameplays have different checksums")
+	}
+	b.BulletSpeed++
+	if a.Checksum() == b.Checksum() {
+		t.Error("changing the bullet speed kept the checksum")
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	os.WriteFile(path, []byte(`{"player_speed": 900, "bullet_speed": 1000, "port": 4000}`), 0o644)
+
+	tests := []struct {
+		name        string
+		env         map[string]string
+		args        []string
+		defaultPath string
+		want        func(*GameConfig)
+		wantErr     string
+	}{
+		{"defaults without a file", nil, nil, filepath.Join(dir, "missing.json"), func(*GameConfig) {}, ""},
+		{"file", nil, nil, path, func(c *GameConfig) {
+			c.PlayerSpeed, c.BulletSpeed, c.Port = 900, 1000, 4000
+		}, ""},
+		{"env beats file", map[string]string{"PLAYER_SPEED": "800", "BODY_BLOCK": "false"}, nil, path, func(c *GameConfig) {
+			c.PlayerSpeed, c.BulletSpeed, c.Port, c.BodyBlock = 800, 1000, 4000, false
+		}, ""},
+		{"flags beat env", map[string]string{"PLAYER_SPEED": "800"}, []string{"-player-speed", "700", "-dash-cooldown-ms=900"}, path, func(c *GameConfig) {
+			c.PlayerSpeed, c.BulletSpeed, c.Port, c.DashCooldownMS = 700, 1000, 4000, 900
+		}, ""},
+		{"file from env", map[string]string{"CONFIG_FILE": path}, nil, "", func(c *GameConfig) {
+			c.PlayerSpeed, c.BulletSpeed, c.Port = 900, 1000, 4000
+		}, ""},
+		{"missing file that was asked for", nil, []string{"-config", filepath.Join(dir, "missing.json")}, path, nil, "missing.json"},
+		{"bad env", map[string]string{"MAX_PLAYERS": "lots"}, nil, path, nil, "MAX_PLAYERS"},
+		{"bad flag", nil, []string{"-body-block", "maybe"}, path, nil, "-body-block"},
+		{"invalid", nil, []string{"-port", "0", "-bullet-speed", "-1"}, path, nil, "bullet_speed must be positive"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			flags := AddFlags(set)
+			if err := set.Parse(test.args); err != nil {
+				t.Fatal(err)
+			}
+			getEnv := func(name, def string) string {
+				if v, ok := test.env[name]; ok {
+					return v
+				}
+				return def
+			}
+
+			got, err := flags.Load(test.defaultPath, getEnv)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("got error %v want one about %s", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := Default()
+			test.want(&want)
+			if got != want {
+				t.Errorf("got %+v want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestUnknownFileField(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(path, []byte(`{"player_sped": 900}`), 0o644)
+	var c GameConfig
+	if err := loadFile(&c, path); err == nil || !strings.Contains(err.Error(), "player_sped") {
+		t.Errorf("got error %v want one about player_sped", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(*GameConfig)
+		wantErr string
+	}{
+		{"invulnerable longer than the dash", func(c *GameConfig) { c.DashInvulnerableMS = c.DashDurationMS + 1 }, "dash_invulnerable_ms"},
+		{"players shrink to nothing", func(c *GameConfig) { c.PlayerShrinkStep = 5 }, "players shrink"},
+		{"tiny world", func(c *GameConfig) { c.WorldWidth = 50 }, "too small"},
+		{"snapshots over the buffer", func(c *GameConfig) { c.SnapshotBudget = 2000 }, "snapshot_budget"},
+		{"no health", func(c *GameConfig) { c.InitialPlayerHealth = 0 }, "initial_player_health"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := Default()
+			test.change(&c)
+			if err := c.Validate(); err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("got error %v want one about %s", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestReload(t *testing.T) {
+	tests := []struct {
+		name      string
+		settings  string
+		wantFixed []string
+		wantErr   bool
+	}{
+		{"gameplay", `{"bullet_speed": 2000, "player_speed": 900, "bullet_cooldown_ms": 100}`, nil, false},
+		{"world size", `{"world_width": 4000}`, []string{"world_width"}, false},
+		{"port and speed", `{"port": 4000, "player_speed": 900}`, []string{"port"}, false},
+		{"unknown setting", `{"bulet_speed": 1}`, nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cur := Default()
+			next, err := cur.WithJSON([]byte(test.settings))
+			if (err != nil) != test.wantErr {
+				t.Fatalf("got error %v", err)
+			}
+			if err != nil {
+				return
+			}
+			reloaded, fixed := cur.Reload(next)
+			var fixedNames []string
+			for _, change := range fixed {
+				fixedNames = append(fixedNames, change.Name)
+			}
+			if strings.Join(fixedNames, ",") != strings.Join(test.wantFixed, ",") {
+				t.Errorf("got fixed %v want %v", fixedNames, test.wantFixed)
+			}
+			if reloaded.Port != cur.Port || reloaded.WorldWidth != cur.WorldWidth {
+				t.Errorf("reloaded a setting that needs a restart: %+v", reloaded)
+			}
+			if next.PlayerSpeed == 900 && reloaded.PlayerSpeed != 900 {
+				t.Errorf("player speed %f wasn't reloaded", reloaded.PlayerSpeed)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	before, after := Default(), Default()
+	after.BulletSpeed = 2000
+	after.BodyBlock = false
+	changes := Diff(before, after)
+	want := []string{"body_block: true -> false", "bullet_speed: 1800 -> 2000"}
+	if len(changes) != len(want) {
+		t.Fatalf("got changes %v want %v", changes, want)
+	}
+	for i := range want {
+		if changes[i].String() != want[i] {
+			t.Errorf("got change %s want %s", changes[i], want[i])
+		}
+	}
+	if got := Default().Apply(changes); got != after {
+		t.Errorf("applying the changes got %+v want %+v", got, after)
+	}
+	if changes := Diff(before, before); len(changes) != 0 {
+		t.Errorf("got changes %v between equal configs", changes)
+	}
+}
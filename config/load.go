@@ -0,0 +1,167 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// every field of GameConfig can be set in the config file, the environment
+// and on the command line, later ones win:
+//
+//	{"player_speed": 900}     in the json config file
+//	PLAYER_SPEED=900          in the environment or the .env file
+//	-player-speed 900         as a flag
+//
+// the file is -config, else CONFIG_FILE, else the default path of the binary
+
+// a field of GameConfig by its json name
+type field struct {
+	name  string
+	index []int
+}
+
+func fields() []field {
+	var out []field
+	var walk func(t reflect.Type, index []int)
+	walk = func(t reflect.Type, index []int) {
+		for i := range t.NumField() {
+			f := t.Field(i)
+			at := append(append([]int{}, index...), i)
+			if f.Anonymous {
+				walk(f.Type, at)
+				continue
+			}
+			out = append(out, field{strings.Split(f.Tag.Get("json"), ",")[0], at})
+		}
+	}
+	walk(reflect.TypeFor[GameConfig](), nil)
+	return out
+}
+
+func (f field) envName() string {
+	return strings.ToUpper(f.name)
+}
+
+func (f field) flagName() string {
+	return strings.ReplaceAll(f.name, "_", "-")
+}
+
+func (f field) set(c *GameConfig, text string) error {
+	v := reflect.ValueOf(c).Elem().FieldByIndex(f.index)
+	text = strings.TrimSpace(text)
+	switch v.Kind() {
+	case reflect.Int:
+		n, err := strconv.Atoi(text)
+		if err != nil {
+			return fmt.Errorf("'%s' isn't a whole number", text)
+		}
+		v.SetInt(int64(n))
+	case reflect.Float32:
+		x, err := strconv.ParseFloat(text, 32)
+		if err != nil {
+			return fmt.Errorf("'%s' isn't a number", text)
+		}
+		v.SetFloat(x)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return fmt.Errorf("'%s' isn't true or false", text)
+		}
+		v.SetBool(b)
+	default:
+		panic("config field " + f.name + " has an unsupported type")
+	}
+	return nil
+}
+
+// the flags Load reads, made by AddFlags
+type Flags struct {
+	set  *flag.FlagSet
+	path *string
+}
+
+// adds -config and a flag for every field to set, parse set before Load
+func AddFlags(set *flag.FlagSet) *Flags {
+	defaults := Default()
+	for _, f := range fields() {
+		value := reflect.ValueOf(defaults).FieldByIndex(f.index)
+		set.String(f.flagName(), "", fmt.Sprintf("%s (default %v)", f.name, value))
+	}
+	return &Flags{set, set.String("config", "", "json config file (default CONFIG_FILE)")}
+}
+
+// the config file Load reads, and whether it was asked for rather than the
+// default one
+func (fl *Flags) File(defaultPath string, getEnv func(name, def string) string) (string, bool) {
+	if *fl.path != "" {
+		return *fl.path, true
+	}
+	if env := getEnv("CONFIG_FILE", ""); env != "" {
+		return env, true
+	}
+	return defaultPath, false
+}
+
+// the defaults overridden by the config file, the environment and the flags
+// that were given, in that order. a missing file at defaultPath is fine, a
+// missing file that was asked for isn't. the result is validated
+func (fl *Flags) Load(defaultPath string, getEnv func(name, def string) string) (GameConfig, error) {
+	c := Default()
+	path, required := fl.File(defaultPath, getEnv)
+	if err := loadFile(&c, path); err != nil && (required || !errors.Is(err, fs.ErrNotExist)) {
+		return c, err
+	}
+
+	for _, f := range fields() {
+		if env := getEnv(f.envName(), ""); env != "" {
+			if err := f.set(&c, env); err != nil {
+				return c, fmt.Errorf("%s: %w", f.envName(), err)
+			}
+		}
+	}
+
+	var flagErr error
+	byFlag := make(map[string]field)
+	for _, f := range fields() {
+		byFlag[f.flagName()] = f
+	}
+	fl.set.Visit(func(fg *flag.Flag) {
+		f, ok := byFlag[fg.Name]
+		if !ok || flagErr != nil {
+			return
+		}
+		if err := f.set(&c, fg.Value.String()); err != nil {
+			flagErr = fmt.Errorf("-%s: %w", fg.Name, err)
+		}
+	})
+	if flagErr != nil {
+		return c, flagErr
+	}
+
+	if err := c.Validate(); err != nil {
+		return c, fmt.Errorf("invalid config: %w", err)
+	}
+	return c, nil
+}
+
+// sets the fields in the json file at path, the rest stay as they are
+func loadFile(c *GameConfig, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(c); err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	return nil
+}
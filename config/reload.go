@@ -0,0 +1,61 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// a setting that differs between two configs
+type Change struct {
+	Name     string
+	Old, New any
+	field    field
+}
+
+func (ch Change) String() string {
+	return fmt.Sprintf("%s: %v -> %v", ch.Name, ch.Old, ch.New)
+}
+
+// the settings that differ from before to after, in the order GameConfig has them
+func Diff(before, after GameConfig) []Change {
+	var changes []Change
+	oldValue, newValue := reflect.ValueOf(before), reflect.ValueOf(after)
+	for _, f := range fields() {
+		a, b := oldValue.FieldByIndex(f.index).Interface(), newValue.FieldByIndex(f.index).Interface()
+		if a != b {
+			changes = append(changes, Change{f.name, a, b, f})
+		}
+	}
+	return changes
+}
+
+// the config with the changes' new values
+func (c GameConfig) Apply(changes []Change) GameConfig {
+	v := reflect.ValueOf(&c).Elem()
+	for _, change := range changes {
+		v.FieldByIndex(change.field.index).Set(reflect.ValueOf(change.New))
+	}
+	return c
+}
+
+// what a running server can take from next: the gameplay but the world size.
+// also returns the settings next changes that need a restart
+func (c GameConfig) Reload(next GameConfig) (GameConfig, []Change) {
+	reloaded := c
+	reloaded.Gameplay = next.Gameplay
+	reloaded.WorldWidth, reloaded.WorldHeight = c.WorldWidth, c.WorldHeight
+	return reloaded, Diff(reloaded, next)
+}
+
+// the config with the settings in the json object changed, like
+// {"bullet_speed": 2000}. unknown settings are an error
+func (c GameConfig) WithJSON(data []byte) (GameConfig, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&c); err != nil {
+		return c, err
+	}
+	return c, nil
+}
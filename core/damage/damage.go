@@ -0,0 +1,74 @@
+// how much a bullet hurts and how close it has to get. the simulation asks
+// Resolve for every bullet near a player instead of doing the math inline
+package damage
+
+import (
+	"CircleWar/core/weapons"
+	"math"
+)
+
+// bullets hit once their circles overlap by a bit, grazes don't count
+const HitLeniency = 0.9
+
+type Shot struct {
+	Weapon weapons.Weapon
+	Size   float32 // radius of the bullet
+	// world units the bullet flew before reaching the target
+	Travelled float32
+}
+
+type Target struct {
+	Radius float32
+	// shielded targets stop bullets without losing health
+	Shielded bool
+	// taken off the damage of every hit, a hit that does damage always
+	// does at least 1
+	Armor int
+}
+
+type Result struct {
+	// the bullet hits when it's closer than this to the target's center
+	Radius float32
+	Damage int
+	// the target stopped the bullet without taking damage
+	Blocked bool
+}
+
+// a bullet hitting a player, the simulation reports them for every tick
+type Hit struct {
+	ShooterId uint
+	TargetId  uint
+	Weapon    weapons.Type
+	Damage    int
+	Blocked   bool
+}
+
+// damage of the weapon after falloff over the distance travelled
+func falloff(w weapons.Weapon, travelled float32) int {
+	if w.FalloffStart <= 0 || w.Range <= 0 {
+		return w.Damage
+	}
+	start := w.FalloffStart * w.Range
+	if travelled <= start {
+		return w.Damage
+	}
+	frac := min(1, (travelled-start)/(w.Range-start))
+	return int(math.Round(float64(float32(w.Damage) - frac*float32(w.Damage-w.MinDamage))))
+}
+
+func Resolve(shot Shot, target Target) Result {
+	result := Result{Radius: (target.Radius + shot.Size) * HitLeniency}
+	if target.Shielded {
+		result.Blocked = true
+		return result
+	}
+	result.Damage = falloff(shot.Weapon, shot.Travelled)
+	if result.Damage > 0 {
+		result.Damage = max(1, result.Damage-target.Armor)
+	}
+	return result
+}
+
+func (r Result) Hit(shooterId, targetId uint, weapon weapons.Type) Hit {
+	return Hit{shooterId, targetId, weapon, r.Damage, r.Blocked}
+}
@@ -0,0 +1,54 @@
+package damage
+
+import (
+	"CircleWar/core/weapons"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	pistol := weapons.Weapon{Damage: 1, Range: 1000}
+	shotgun := weapons.Weapon{Damage: 4, Range: 1000, FalloffStart: 0.5, MinDamage: 2}
+	tests := []struct {
+		name   string
+		shot   Shot
+		target Target
+		want   Result
+	}{
+		{"plain hit", Shot{pistol, 10, 300}, Target{Radius: 40}, Result{Radius: 45, Damage: 1}},
+		{"radius grows with the bullet", Shot{pistol, 30, 0}, Target{Radius: 40}, Result{Radius: 63, Damage: 1}},
+		{"before falloff", Shot{shotgun, 10, 500}, Target{Radius: 40}, Result{Radius: 45, Damage: 4}},
+		{"half way through falloff", Shot{shotgun, 10, 750}, Target{Radius: 40}, Result{Radius: 45, Damage: 3}},
+		{"end of range", Shot{shotgun, 10, 1000}, Target{Radius: 40}, Result{Radius: 45, Damage: 2}},
+		{"past the range", Shot{shotgun, 10, 5000}, Target{Radius: 40}, Result{Radius: 45, Damage: 2}},
+		{"armor", Shot{shotgun, 10, 0}, Target{Radius: 40, Armor: 1}, Result{Radius: 45, Damage: 3}},
+		{"armor leaves 1", Shot{shotgun, 10, 0}, Target{Radius: 40, Armor: 10}, Result{Radius: 45, Damage: 1}},
+		{"armor on a spent bullet", Shot{weapons.Weapon{Damage: 1, Range: 1000, FalloffStart: 0.5}, 10, 1000}, Target{Radius: 40, Armor: 1}, Result{Radius: 45}},
+		{"shield blocks", Shot{shotgun, 10, 0}, Target{Radius: 40, Shielded: true}, Result{Radius: 45, Blocked: true}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := Resolve(test.shot, test.target)
+			if got.Damage != test.want.Damage || got.Blocked != test.want.Blocked {
+				t.Errorf("got %+v want %+v", got, test.want)
+			}
+			if diff := got.Radius - test.want.Radius; diff > 1e-4 || diff < -1e-4 {
+				t.Errorf("got radius %f want %f", got.Radius, test.want.Radius)
+			}
+		})
+	}
+}
+
+func TestPresetFalloff(t *testing.T) {
+	for _, wt := range weapons.Loadout {
+		w := weapons.Get(wt)
+		close := Resolve(Shot{w, 10, 0}, Target{Radius: 40}).Damage
+		far := Resolve(Shot{w, 10, w.Range}, Target{Radius: 40}).Damage
+		wantFar := w.Damage
+		if w.FalloffStart > 0 {
+			wantFar = w.MinDamage
+		}
+		if close != w.Damage || far != wantFar {
+			t.Errorf("%s does %d close and %d far want %d and %d", w.Name, close, far, w.Damage, wantFar)
+		}
+	}
+}
@@ -66,3 +66,12 @@ func NewDir(v Vector2) Direction {
 func (d Direction) ScalarMult(by float32) Vector2 {
 	return NewVector(d.X*by, d.Y*by)
 }
+
+// the direction turned by angle radians, clockwise with y pointing down
+func (d Direction) Rotated(angle float32) Direction {
+	sin, cos := math.Sincos(float64(angle))
+	return Direction(NewVector(
+		d.X*float32(cos)-d.Y*float32(sin),
+		d.X*float32(sin)+d.Y*float32(cos),
+	))
+}
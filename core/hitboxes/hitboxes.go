@@ -6,9 +6,11 @@ import (
 )
 
 func PlayerSize(health netmsg.PlayerHealth) float32 {
-	return config.InitialPlayerSize + (float32(health)-config.InitialPlayerHealth)*config.PlayerShrinkStep
+	g := config.Current()
+	return g.InitialPlayerSize + (float32(health)-g.InitialPlayerHealth)*g.PlayerShrinkStep
 }
 
 func BulletSize(health netmsg.PlayerHealth) float32 {
-	return config.InitialBulletSize + (float32(health)-config.InitialPlayerHealth)*config.BulletShrinkStep
+	g := config.Current()
+	return g.InitialBulletSize + (float32(health)-g.InitialPlayerHealth)*g.BulletShrinkStep
 }
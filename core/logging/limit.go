@@ -0,0 +1,84 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// a logger that lets through one record per message every interval, for
+// logs that could come with every packet. how many were dropped in between
+// is added to the next one that gets through as "suppressed"
+func Limited(logger *slog.Logger, interval time.Duration) *slog.Logger {
+	return limited(logger, interval, time.Now)
+}
+
+func limited(logger *slog.Logger, interval time.Duration, now func() time.Time) *slog.Logger {
+	return slog.New(&limitHandler{logger.Handler(), &limitState{
+		interval: interval,
+		now:      now,
+		seen:     make(map[string]*limitEntry),
+	}})
+}
+
+type limitEntry struct {
+	last       time.Time
+	suppressed int
+}
+
+// shared by a limited logger and the loggers made from it with With
+type limitState struct {
+	mu       sync.Mutex
+	interval time.Duration
+	now      func() time.Time
+	seen     map[string]*limitEntry // by message
+}
+
+// whether a record with msg gets through, and how many were dropped before it
+func (s *limitState) allow(msg string) (bool, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	entry, ok := s.seen[msg]
+	if !ok {
+		s.seen[msg] = &limitEntry{last: now}
+		return true, 0
+	}
+	if now.Sub(entry.last) < s.interval {
+		entry.suppressed++
+		return false, 0
+	}
+	suppressed := entry.suppressed
+	entry.last, entry.suppressed = now, 0
+	return true, suppressed
+}
+
+type limitHandler struct {
+	inner slog.Handler
+	state *limitState
+}
+
+func (h *limitHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+func (h *limitHandler) Handle(ctx context.Context, r slog.Record) error {
+	ok, suppressed := h.state.allow(r.Message)
+	if !ok {
+		return nil
+	}
+	if suppressed > 0 {
+		r = r.Clone()
+		r.AddAttrs(slog.Int("suppressed", suppressed))
+	}
+	return h.inner.Handle(ctx, r)
+}
+
+func (h *limitHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &limitHandler{h.inner.WithAttrs(attrs), h.state}
+}
+
+func (h *limitHandler) WithGroup(name string) slog.Handler {
+	return &limitHandler{h.inner.WithGroup(name), h.state}
+}
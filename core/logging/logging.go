@@ -0,0 +1,186 @@
+// leveled, structured logs for the server and the client. every subsystem
+// gets its own logger and level, set up once from the environment:
+//
+//	LOG_FORMAT=json            text (default) or json
+//	LOG_LEVEL=info             level of subsystems not in LOG_LEVELS
+//	LOG_LEVELS=net=debug,sim=warn
+//
+// loggers can be made before Setup, they log through whatever was set up last
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	Net   = "net"
+	Sim   = "sim"
+	Match = "match"
+)
+
+type Config struct {
+	Format string // "text" or "json"
+	Level  string
+	// subsystem=level pairs separated by commas
+	Levels string
+	Output io.Writer
+}
+
+var (
+	base         atomic.Pointer[slog.Handler]
+	defaultLevel slog.LevelVar
+	levelsMu     sync.Mutex
+	levels       = map[string]*slog.LevelVar{}
+)
+
+func init() {
+	var h slog.Handler = slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})
+	base.Store(&h)
+}
+
+func parseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	err := level.UnmarshalText([]byte(strings.TrimSpace(name)))
+	return level, err
+}
+
+// the level of subsystem, shared by all its loggers
+func levelOf(subsystem string) *slog.LevelVar {
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+	level, ok := levels[subsystem]
+	if !ok {
+		level = &slog.LevelVar{}
+		level.Set(defaultLevel.Level())
+		levels[subsystem] = level
+	}
+	return level
+}
+
+func Setup(cfg Config) error {
+	if cfg.Output == nil {
+		cfg.Output = os.Stderr
+	}
+	// levels are checked per subsystem, the output takes everything
+	opts := &slog.HandlerOptions{Level: slog.LevelDebug}
+	var h slog.Handler
+	switch cfg.Format {
+	case "", "text":
+		h = slog.NewTextHandler(cfg.Output, opts)
+	case "json":
+		h = slog.NewJSONHandler(cfg.Output, opts)
+	default:
+		return fmt.Errorf("unknown log format '%s'", cfg.Format)
+	}
+
+	level := slog.LevelInfo
+	if cfg.Level != "" {
+		var err error
+		if level, err = parseLevel(cfg.Level); err != nil {
+			return fmt.Errorf("bad LOG_LEVEL: %w", err)
+		}
+	}
+	subsystemLevels := map[string]slog.Level{}
+	for _, pair := range strings.Split(cfg.Levels, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		name, levelName, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("log level '%s' isn't subsystem=level", pair)
+		}
+		subLevel, err := parseLevel(levelName)
+		if err != nil {
+			return fmt.Errorf("bad level for %s: %w", name, err)
+		}
+		subsystemLevels[strings.TrimSpace(name)] = subLevel
+	}
+
+	base.Store(&h)
+	defaultLevel.Set(level)
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+	for name, lv := range levels {
+		lv.Set(level)
+		if subLevel, ok := subsystemLevels[name]; ok {
+			lv.Set(subLevel)
+		}
+	}
+	for name, subLevel := range subsystemLevels {
+		if _, ok := levels[name]; !ok {
+			levels[name] = &slog.LevelVar{}
+			levels[name].Set(subLevel)
+		}
+	}
+	return nil
+}
+
+// the logger of subsystem, its records carry a "subsystem" field
+func For(subsystem string) *slog.Logger {
+	return slog.New(&subsystemHandler{
+		level: levelOf(subsystem),
+		wrap: func(h slog.Handler) slog.Handler {
+			return h.WithAttrs([]slog.Attr{slog.String("subsystem", subsystem)})
+		},
+	})
+}
+
+// checks the subsystem's level, then logs through the base handler with
+// the attrs and groups added to the logger on the way
+type subsystemHandler struct {
+	level slog.Leveler
+	wrap  func(slog.Handler) slog.Handler
+}
+
+func (h *subsystemHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *subsystemHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.wrap(*base.Load()).Handle(ctx, r)
+}
+
+func (h *subsystemHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &subsystemHandler{h.level, func(inner slog.Handler) slog.Handler {
+		return h.wrap(inner).WithAttrs(attrs)
+	}}
+}
+
+func (h *subsystemHandler) WithGroup(name string) slog.Handler {
+	return &subsystemHandler{h.level, func(inner slog.Handler) slog.Handler {
+		return h.wrap(inner).WithGroup(name)
+	}}
+}
+
+// fields the game's logs share
+func Player(id uint32) slog.Attr {
+	return slog.Any("player", id)
+}
+
+func Tick(tick uint32) slog.Attr {
+	return slog.Any("tick", tick)
+}
+
+func Room(name string) slog.Attr {
+	return slog.String("room", name)
+}
+
+func Err(err error) slog.Attr {
+	return slog.Any("err", err)
+}
+
+// the config from LOG_FORMAT, LOG_LEVEL and LOG_LEVELS read with getEnv
+func ConfigFromEnv(getEnv func(name, def string) string) Config {
+	return Config{
+		Format: getEnv("LOG_FORMAT", "text"),
+		Level:  getEnv("LOG_LEVEL", "info"),
+		Levels: getEnv("LOG_LEVELS", ""),
+	}
+}
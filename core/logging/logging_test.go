@@ -0,0 +1,102 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// the records written to out, one decoded json object each
+func records(t *testing.T, out *bytes.Buffer) []map[string]any {
+	recs := []map[string]any{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		rec := map[string]any{}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("line %q isn't json: %v", line, err)
+		}
+		recs = append(recs, rec)
+	}
+	return recs
+}
+
+func TestSubsystemLevels(t *testing.T) {
+	// made before Setup, like package level loggers
+	netLog := For(Net)
+	var out bytes.Buffer
+	if err := Setup(Config{Format: "json", Level: "warn", Levels: "net=debug, sim=error", Output: &out}); err != nil {
+		t.Fatal(err)
+	}
+	simLog, matchLog := For(Sim), For(Match)
+
+	netLog.Debug("packet", Player(3))
+	simLog.Warn("dropped")
+	simLog.Error("broken", Tick(7))
+	matchLog.Info("started")
+	matchLog.With(Room("default")).Warn("full")
+
+	recs := records(t, &out)
+	want := []struct{ msg, subsystem string }{
+		{"packet", Net},
+		{"broken", Sim},
+		{"full", Match},
+	}
+	if len(recs) != len(want) {
+		t.Fatalf("got %d records want %d: %s", len(recs), len(want), out.String())
+	}
+	for i, w := range want {
+		if recs[i]["msg"] != w.msg || recs[i]["subsystem"] != w.subsystem {
+			t.Errorf("got record %v want %s from %s", recs[i], w.msg, w.subsystem)
+		}
+	}
+	if recs[0]["player"] != 3.0 || recs[1]["tick"] != 7.0 || recs[2]["room"] != "default" {
+		t.Errorf("fields missing from %v", recs)
+	}
+}
+
+func TestSetupErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{"format", Config{Format: "xml"}},
+		{"level", Config{Level: "loud"}},
+		{"pair", Config{Levels: "net"}},
+		{"subsystem level", Config{Levels: "net=loud"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := Setup(test.cfg); err == nil {
+				t.Error("got no error")
+			}
+		})
+	}
+}
+
+func TestLimited(t *testing.T) {
+	var out bytes.Buffer
+	if err := Setup(Config{Format: "json", Output: &out}); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(0, 0)
+	logger := limited(For(Net), time.Second, func() time.Time { return now })
+
+	for range 5 {
+		logger.Info("input")
+		now = now.Add(300 * time.Millisecond)
+	}
+	logger.Info("other")
+
+	recs := records(t, &out)
+	// through at 0s and 1.2s, the 3 in between are counted on the second
+	if len(recs) != 3 {
+		t.Fatalf("got %d records want 3: %s", len(recs), out.String())
+	}
+	if recs[0]["suppressed"] != nil || recs[1]["suppressed"] != 3.0 || recs[2]["msg"] != "other" {
+		t.Errorf("got %v", recs)
+	}
+}
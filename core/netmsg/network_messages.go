@@ -1,9 +1,13 @@
 package netmsg
 
 import (
+	"CircleWar/config"
 	"CircleWar/core/geom"
 	pb "CircleWar/core/network/protobuf"
+	"CircleWar/core/pickups"
+	"CircleWar/core/weapons"
 	"errors"
+	"time"
 
 	"google.golang.org/protobuf/proto"
 )
@@ -28,17 +32,38 @@ func Deserialize(msg []byte, n uint32) (GameMessage, error) {
 
 	switch payload := gameMsg.Payload.(type) {
 	case *pb.GameMessage_DeathNote:
-		return NewDeathNote(payload.DeathNote.PlayerId), nil
+		return NewDeathNote(payload.DeathNote.PlayerId, payload.DeathNote.Spectating), nil
 	case *pb.GameMessage_ConnectAck:
-		return NewConnectAck(payload.ConnectAck.PlayerId), nil
+		return NewConnectAck(payload.ConnectAck.PlayerId, GameplayFromProtobuf(payload.ConnectAck.Gameplay)), nil
 	case *pb.GameMessage_ConnectRequest:
 		return NewConnectRequest(payload.ConnectRequest.GameName), nil
 	case *pb.GameMessage_ReconnectRequest:
 		return NewReconnectRequest(payload.ReconnectRequest.OldPlayerId), nil
 	case *pb.GameMessage_World:
-		return worldStateFromProtobuf(payload), nil
+		return WorldStateFromProtobuf(payload.World), nil
 	case *pb.GameMessage_PlayerInput:
-		return playerInputFromProtobuf(payload), nil
+		return PlayerInputFromProtobuf(payload.PlayerInput), nil
+	case *pb.GameMessage_Ping:
+		return NewPing(payload.Ping.Seq, payload.Ping.SentUnixNano), nil
+	case *pb.GameMessage_Pong:
+		return NewPong(payload.Pong.Seq, payload.Pong.SentUnixNano), nil
+	case *pb.GameMessage_SpectateRequest:
+		return NewSpectateRequest(payload.SpectateRequest.GameName), nil
+	case *pb.GameMessage_SpectateAck:
+		return NewSpectateAck(GameplayFromProtobuf(payload.SpectateAck.Gameplay)), nil
+	case *pb.GameMessage_ConnectReject:
+		return NewConnectReject(payload.ConnectReject.Reason), nil
+	case *pb.GameMessage_ViewUpdate:
+		center := payload.ViewUpdate.GetCenter()
+		return NewViewUpdate(geom.NewVector(center.GetX(), center.GetY())), nil
+	case *pb.GameMessage_ServerMessage:
+		return NewServerMessage(payload.ServerMessage.Text), nil
+	case *pb.GameMessage_ServerShutdown:
+		return NewServerShutdown(payload.ServerShutdown.Reason, payload.ServerShutdown.ReconnectHint), nil
+	case *pb.GameMessage_GameplayUpdate:
+		return NewGameplayUpdate(GameplayFromProtobuf(payload.GameplayUpdate.Gameplay)), nil
+	case *pb.GameMessage_GameplayRequest:
+		return NewGameplayRequest(), nil
 	default:
 		return nil, errors.New("Unrecognized game message")
 	}
@@ -48,6 +73,46 @@ type GameMessage interface {
 	Serialize() ([]byte, error)
 }
 
+// name of the message's type for logs and metrics, like "world_state"
+func Name(msg GameMessage) string {
+	switch msg.(type) {
+	case *WorldState:
+		return "world_state"
+	case *PlayerInput:
+		return "player_input"
+	case *ConnectRequest:
+		return "connect_request"
+	case *ReconnectRequest:
+		return "reconnect_request"
+	case *ConnectAck:
+		return "connect_ack"
+	case *DeathNote:
+		return "death_note"
+	case *Ping:
+		return "ping"
+	case *Pong:
+		return "pong"
+	case *SpectateRequest:
+		return "spectate_request"
+	case *SpectateAck:
+		return "spectate_ack"
+	case *ConnectReject:
+		return "connect_reject"
+	case *ViewUpdate:
+		return "view_update"
+	case *ServerMessage:
+		return "server_message"
+	case *ServerShutdown:
+		return "server_shutdown"
+	case *GameplayUpdate:
+		return "gameplay_update"
+	case *GameplayRequest:
+		return "gameplay_request"
+	default:
+		return "unknown"
+	}
+}
+
 type pbConvertible interface {
 	ToProtobuf() *pb.GameMessage
 }
@@ -83,6 +148,7 @@ func BuildPlayerMoveAction(ma *MoveAction) *pb.PlayerAction {
 
 type ShootAction struct {
 	Target geom.Vector2
+	Slot   uint32
 }
 
 func (*ShootAction) IsPlayerAction() {}
@@ -94,43 +160,121 @@ func BuildPlayerShootAction(sa *ShootAction) *pb.PlayerAction {
 				X: sa.Target.X,
 				Y: sa.Target.Y,
 			},
+			Slot: sa.Slot,
+		},
+	}}
+}
+
+// a normalized movement vector and where the player aims, in radians
+type AnalogAction struct {
+	Move geom.Vector2
+	Aim  float32
+}
+
+func (*AnalogAction) IsPlayerAction() {}
+
+func BuildPlayerAnalogAction(aa *AnalogAction) *pb.PlayerAction {
+	return &pb.PlayerAction{Action: &pb.PlayerAction_Analog{
+		Analog: &pb.AnalogAction{
+			Move: &pb.Position{X: aa.Move.X, Y: aa.Move.Y},
+			Aim:  aa.Aim,
 		},
 	}}
 }
 
+// a power-up running on a player and how long it has left
+type Effect struct {
+	Kind      pickups.Kind
+	Remaining time.Duration
+}
+
+// dashes the player the way it moves, or aims when standing still
+type DashAction struct{}
+
+func (*DashAction) IsPlayerAction() {}
+
+func BuildPlayerDashAction(*DashAction) *pb.PlayerAction {
+	return &pb.PlayerAction{Action: &pb.PlayerAction_Dash{Dash: &pb.DashAction{}}}
+}
+
 type PlayerState struct {
-	Id     uint32
-	Pos    geom.Vector2
-	Health float32
+	Id      uint32
+	Pos     geom.Vector2
+	Health  float32
+	Aim     float32
+	Effects []Effect
+	// time until the player can dash again
+	DashCooldown time.Duration
 }
 
-func NewPlayerState(id uint32, pos geom.Vector2, health float32) *PlayerState {
-	return &PlayerState{id, pos, health}
+func NewPlayerState(id uint32, pos geom.Vector2, health, aim float32) *PlayerState {
+	return &PlayerState{id, pos, health, aim, nil, 0}
 }
 
-func BuildPlayerState(pos geom.Vector2, health PlayerHealth, playerId uint32) pb.PlayerState {
-	return pb.PlayerState{
-		Pos:      &pb.Position{X: pos.X, Y: pos.Y},
-		Health:   float32(health),
-		PlayerId: playerId,
+func BuildPlayerState(pos geom.Vector2, health PlayerHealth, playerId uint32, aim float32, effects []Effect, dashCooldown time.Duration) *pb.PlayerState {
+	pbEffects := []*pb.Effect{}
+	for _, effect := range effects {
+		pbEffects = append(pbEffects, &pb.Effect{
+			Kind:        pb.PickupKind(effect.Kind),
+			RemainingMs: uint32(effect.Remaining.Milliseconds()),
+		})
+	}
+	return &pb.PlayerState{
+		Pos:            &pb.Position{X: pos.X, Y: pos.Y},
+		Health:         float32(health),
+		PlayerId:       playerId,
+		Aim:            aim,
+		Effects:        pbEffects,
+		DashCooldownMs: uint32(dashCooldown.Milliseconds()),
 	}
 }
 
+func effectsFromProtobuf(pbEffects []*pb.Effect) []Effect {
+	var effects []Effect
+	for _, effect := range pbEffects {
+		effects = append(effects, Effect{
+			pickups.Kind(effect.Kind),
+			time.Duration(effect.RemainingMs) * time.Millisecond,
+		})
+	}
+	return effects
+}
+
 type BulletState struct {
 	OwnerId uint32
 	Pos     geom.Vector2
 	Size    float32
+	Weapon  weapons.Type
 }
 
-func NewBulletState(ownerId uint32, pos geom.Vector2, size float32) *BulletState {
-	return &BulletState{ownerId, pos, size}
+func NewBulletState(ownerId uint32, pos geom.Vector2, size float32, weapon weapons.Type) *BulletState {
+	return &BulletState{ownerId, pos, size, weapon}
 }
 
-func BuildBulletState(pos geom.Vector2, size float32, ownerId uint32) pb.BulletState {
-	return pb.BulletState{
+func BuildBulletState(pos geom.Vector2, size float32, ownerId uint32, weapon weapons.Type) *pb.BulletState {
+	return &pb.BulletState{
 		Pos:     &pb.Position{X: pos.X, Y: pos.Y},
 		Size:    size,
 		OwnerId: ownerId,
+		Weapon:  pb.WeaponType(weapon),
+	}
+}
+
+type PickupState struct {
+	Id   uint32
+	Pos  geom.Vector2
+	Kind pickups.Kind
+}
+
+func NewPickupState(id uint32, pos geom.Vector2, kind pickups.Kind) *PickupState {
+	return &PickupState{id, pos, kind}
+}
+
+func BuildPickupState(pos geom.Vector2, kind pickups.Kind, pickupId uint32) *pb.PickupState {
+	return &pb.PickupState{
+		PickupId: pickupId,
+		Pos:      &pb.Position{X: pos.X, Y: pos.Y},
+		Kind:     pb.PickupKind(kind),
 	}
 }
 
@@ -153,6 +297,10 @@ func (pi *PlayerInput) ToProtobuf() *pb.GameMessage {
 			playerInput.PlayerActions = append(playerInput.PlayerActions, BuildPlayerMoveAction(inner))
 		case *ShootAction:
 			playerInput.PlayerActions = append(playerInput.PlayerActions, BuildPlayerShootAction(inner))
+		case *AnalogAction:
+			playerInput.PlayerActions = append(playerInput.PlayerActions, BuildPlayerAnalogAction(inner))
+		case *DashAction:
+			playerInput.PlayerActions = append(playerInput.PlayerActions, BuildPlayerDashAction(inner))
 		}
 	}
 
@@ -161,19 +309,24 @@ func (pi *PlayerInput) ToProtobuf() *pb.GameMessage {
 	}
 }
 
-func playerInputFromProtobuf(pbPlayerInput *pb.GameMessage_PlayerInput) *PlayerInput {
+func PlayerInputFromProtobuf(pbPlayerInput *pb.PlayerInput) *PlayerInput {
 	playerInput := &PlayerInput{}
 
-	for _, playerAct := range pbPlayerInput.PlayerInput.PlayerActions {
+	for _, playerAct := range pbPlayerInput.PlayerActions {
 		switch act := playerAct.Action.(type) {
 		case *pb.PlayerAction_Move:
 			playerInput.Actions = append(playerInput.Actions, &MoveAction{Direction(act.Move.Dir)})
 		case *pb.PlayerAction_Shoot:
-			playerInput.Actions = append(playerInput.Actions, &ShootAction{geom.NewVector(act.Shoot.Target.X, act.Shoot.Target.Y)})
+			playerInput.Actions = append(playerInput.Actions, &ShootAction{geom.NewVector(act.Shoot.Target.X, act.Shoot.Target.Y), act.Shoot.Slot})
+		case *pb.PlayerAction_Analog:
+			move := act.Analog.GetMove()
+			playerInput.Actions = append(playerInput.Actions, &AnalogAction{geom.NewVector(move.GetX(), move.GetY()), act.Analog.Aim})
+		case *pb.PlayerAction_Dash:
+			playerInput.Actions = append(playerInput.Actions, &DashAction{})
 		}
 	}
 
-	playerInput.PlayerId = pbPlayerInput.PlayerInput.PlayerId
+	playerInput.PlayerId = pbPlayerInput.PlayerId
 
 	return playerInput
 }
@@ -185,11 +338,14 @@ func (pi *PlayerInput) Serialize() ([]byte, error) {
 type WorldState struct {
 	Players []*PlayerState
 	Bullets []*BulletState
+	Pickups []*PickupState
 	TickNum uint32
+	// of the server's gameplay, 0 when it's not known
+	GameplayChecksum uint32
 }
 
 func NewWorldState(players []*PlayerState, bullets []*BulletState, tickNum uint32) *WorldState {
-	return &WorldState{players, bullets, tickNum}
+	return &WorldState{Players: players, Bullets: bullets, TickNum: tickNum}
 }
 
 func (*WorldState) IsGameMessage() {}
@@ -198,41 +354,61 @@ func (ws *WorldState) ToProtobuf() *pb.GameMessage {
 	worldState := &pb.WorldState{}
 
 	for _, player := range ws.Players {
-		pbPlayer := BuildPlayerState(player.Pos, PlayerHealth(player.Health), uint32(player.Id))
-		worldState.Players = append(worldState.Players, &pbPlayer)
+		pbPlayer := BuildPlayerState(player.Pos, PlayerHealth(player.Health), uint32(player.Id), player.Aim, player.Effects, player.DashCooldown)
+		worldState.Players = append(worldState.Players, pbPlayer)
 	}
 
 	for _, bullet := range ws.Bullets {
-		pbBullet := BuildBulletState(bullet.Pos, bullet.Size, uint32(bullet.OwnerId))
-		worldState.Bullets = append(worldState.Bullets, &pbBullet)
+		pbBullet := BuildBulletState(bullet.Pos, bullet.Size, uint32(bullet.OwnerId), bullet.Weapon)
+		worldState.Bullets = append(worldState.Bullets, pbBullet)
+	}
+
+	for _, pickup := range ws.Pickups {
+		pbPickup := BuildPickupState(pickup.Pos, pickup.Kind, pickup.Id)
+		worldState.Pickups = append(worldState.Pickups, pbPickup)
 	}
 
 	worldState.TickNum = ws.TickNum
+	worldState.GameplayChecksum = ws.GameplayChecksum
 
 	return &pb.GameMessage{
 		Payload: &pb.GameMessage_World{World: worldState},
 	}
 }
 
-func worldStateFromProtobuf(pbWorld *pb.GameMessage_World) *WorldState {
+func WorldStateFromProtobuf(pbWorld *pb.WorldState) *WorldState {
 	worldState := &WorldState{}
 
-	for _, player := range pbWorld.World.Players {
-		worldState.Players = append(worldState.Players, NewPlayerState(
+	for _, player := range pbWorld.Players {
+		playerState := NewPlayerState(
 			player.PlayerId,
 			geom.NewVector(player.Pos.X, player.Pos.Y),
 			player.Health,
-		))
+			player.Aim,
+		)
+		playerState.Effects = effectsFromProtobuf(player.Effects)
+		playerState.DashCooldown = time.Duration(player.DashCooldownMs) * time.Millisecond
+		worldState.Players = append(worldState.Players, playerState)
 	}
 
-	for _, bullet := range pbWorld.World.Bullets {
+	for _, bullet := range pbWorld.Bullets {
 		worldState.Bullets = append(worldState.Bullets, NewBulletState(
 			bullet.OwnerId,
 			geom.NewVector(bullet.Pos.X, bullet.Pos.Y),
 			bullet.Size,
+			weapons.Type(bullet.Weapon),
+		))
+	}
+
+	for _, pickup := range pbWorld.Pickups {
+		worldState.Pickups = append(worldState.Pickups, NewPickupState(
+			pickup.PickupId,
+			geom.NewVector(pickup.GetPos().GetX(), pickup.GetPos().GetY()),
+			pickups.Kind(pickup.Kind),
 		))
 	}
-	worldState.TickNum = pbWorld.World.TickNum
+	worldState.TickNum = pbWorld.TickNum
+	worldState.GameplayChecksum = pbWorld.GameplayChecksum
 
 	return worldState
 }
@@ -263,12 +439,60 @@ func (cr *ConnectRequest) Serialize() ([]byte, error) {
 	return marshal(cr)
 }
 
+func GameplayToProtobuf(g config.Gameplay) *pb.GameplayConfig {
+	return &pb.GameplayConfig{
+		WorldWidth:          g.WorldWidth,
+		WorldHeight:         g.WorldHeight,
+		PlayerSpeed:         g.PlayerSpeed,
+		DashSpeed:           g.DashSpeed,
+		DashDurationMs:      int32(g.DashDurationMS),
+		DashInvulnerableMs:  int32(g.DashInvulnerableMS),
+		DashCooldownMs:      int32(g.DashCooldownMS),
+		BodyBlock:           g.BodyBlock,
+		BulletSpeed:         g.BulletSpeed,
+		BulletTimeToLiveSec: g.BulletTimeToLiveSec,
+		BulletCooldownMs:    int32(g.BulletCooldownMS),
+		InitialPlayerHealth: g.InitialPlayerHealth,
+		InitialPlayerSize:   g.InitialPlayerSize,
+		PlayerShrinkStep:    g.PlayerShrinkStep,
+		InitialBulletSize:   g.InitialBulletSize,
+		BulletShrinkStep:    g.BulletShrinkStep,
+	}
+}
+
+// the defaults when the message has none
+func GameplayFromProtobuf(pbg *pb.GameplayConfig) config.Gameplay {
+	if pbg == nil {
+		return config.Default().Gameplay
+	}
+	return config.Gameplay{
+		WorldWidth:          pbg.WorldWidth,
+		WorldHeight:         pbg.WorldHeight,
+		PlayerSpeed:         pbg.PlayerSpeed,
+		DashSpeed:           pbg.DashSpeed,
+		DashDurationMS:      int(pbg.DashDurationMs),
+		DashInvulnerableMS:  int(pbg.DashInvulnerableMs),
+		DashCooldownMS:      int(pbg.DashCooldownMs),
+		BodyBlock:           pbg.BodyBlock,
+		BulletSpeed:         pbg.BulletSpeed,
+		BulletTimeToLiveSec: pbg.BulletTimeToLiveSec,
+		BulletCooldownMS:    int(pbg.BulletCooldownMs),
+		InitialPlayerHealth: pbg.InitialPlayerHealth,
+		InitialPlayerSize:   pbg.InitialPlayerSize,
+		PlayerShrinkStep:    pbg.PlayerShrinkStep,
+		InitialBulletSize:   pbg.InitialBulletSize,
+		BulletShrinkStep:    pbg.BulletShrinkStep,
+	}
+}
+
+// the server's gameplay comes with the ack, the client plays by it
 type ConnectAck struct {
 	PlayerId uint32
+	Gameplay config.Gameplay
 }
 
-func NewConnectAck(playerId uint32) *ConnectAck {
-	return &ConnectAck{playerId}
+func NewConnectAck(playerId uint32, gameplay config.Gameplay) *ConnectAck {
+	return &ConnectAck{playerId, gameplay}
 }
 
 func (*ConnectAck) IsGameMessage() {}
@@ -276,7 +500,7 @@ func (*ConnectAck) IsGameMessage() {}
 func (ca *ConnectAck) ToProtobuf() *pb.GameMessage {
 	return &pb.GameMessage{
 		Payload: &pb.GameMessage_ConnectAck{
-			ConnectAck: &pb.ConnectAck{PlayerId: ca.PlayerId},
+			ConnectAck: &pb.ConnectAck{PlayerId: ca.PlayerId, Gameplay: GameplayToProtobuf(ca.Gameplay)},
 		},
 	}
 }
@@ -287,10 +511,12 @@ func (ca *ConnectAck) Serialize() ([]byte, error) {
 
 type DeathNote struct {
 	PlayerId uint32
+	// eliminated players watch the rest of the match instead of respawning
+	Spectating bool
 }
 
-func NewDeathNote(playerId uint32) *DeathNote {
-	return &DeathNote{playerId}
+func NewDeathNote(playerId uint32, spectating bool) *DeathNote {
+	return &DeathNote{playerId, spectating}
 }
 
 func (*DeathNote) IsGameMessage() {}
@@ -298,7 +524,7 @@ func (*DeathNote) IsGameMessage() {}
 func (dn *DeathNote) ToProtobuf() *pb.GameMessage {
 	return &pb.GameMessage{
 		Payload: &pb.GameMessage_DeathNote{
-			DeathNote: &pb.DeathNote{PlayerId: dn.PlayerId},
+			DeathNote: &pb.DeathNote{PlayerId: dn.PlayerId, Spectating: dn.Spectating},
 		},
 	}
 }
@@ -328,3 +554,227 @@ func (rr *ReconnectRequest) ToProtobuf() *pb.GameMessage {
 func (rr *ReconnectRequest) Serialize() ([]byte, error) {
 	return marshal(rr)
 }
+
+type Ping struct {
+	Seq      uint32
+	SentNano int64
+}
+
+func NewPing(seq uint32, sentNano int64) *Ping {
+	return &Ping{seq, sentNano}
+}
+
+func (*Ping) IsGameMessage() {}
+
+func (p *Ping) ToProtobuf() *pb.GameMessage {
+	return &pb.GameMessage{
+		Payload: &pb.GameMessage_Ping{
+			Ping: &pb.Ping{Seq: p.Seq, SentUnixNano: p.SentNano},
+		},
+	}
+}
+
+func (p *Ping) Serialize() ([]byte, error) {
+	return marshal(p)
+}
+
+// echoed back to whoever sent the matching Ping
+type Pong struct {
+	Seq      uint32
+	SentNano int64
+}
+
+func NewPong(seq uint32, sentNano int64) *Pong {
+	return &Pong{seq, sentNano}
+}
+
+func (*Pong) IsGameMessage() {}
+
+func (p *Pong) ToProtobuf() *pb.GameMessage {
+	return &pb.GameMessage{
+		Payload: &pb.GameMessage_Pong{
+			Pong: &pb.Pong{Seq: p.Seq, SentUnixNano: p.SentNano},
+		},
+	}
+}
+
+func (p *Pong) Serialize() ([]byte, error) {
+	return marshal(p)
+}
+
+type SpectateRequest struct {
+	GameName string
+}
+
+func NewSpectateRequest(gameName string) *SpectateRequest {
+	return &SpectateRequest{gameName}
+}
+
+func (*SpectateRequest) IsGameMessage() {}
+
+func (sr *SpectateRequest) ToProtobuf() *pb.GameMessage {
+	return &pb.GameMessage{
+		Payload: &pb.GameMessage_SpectateRequest{
+			SpectateRequest: &pb.SpectateRequest{GameName: sr.GameName},
+		},
+	}
+}
+
+func (sr *SpectateRequest) Serialize() ([]byte, error) {
+	return marshal(sr)
+}
+
+type SpectateAck struct {
+	Gameplay config.Gameplay
+}
+
+func NewSpectateAck(gameplay config.Gameplay) *SpectateAck {
+	return &SpectateAck{gameplay}
+}
+
+func (*SpectateAck) IsGameMessage() {}
+
+func (sa *SpectateAck) ToProtobuf() *pb.GameMessage {
+	return &pb.GameMessage{
+		Payload: &pb.GameMessage_SpectateAck{
+			SpectateAck: &pb.SpectateAck{Gameplay: GameplayToProtobuf(sa.Gameplay)},
+		},
+	}
+}
+
+func (sa *SpectateAck) Serialize() ([]byte, error) {
+	return marshal(sa)
+}
+
+type ConnectReject struct {
+	Reason string
+}
+
+func NewConnectReject(reason string) *ConnectReject {
+	return &ConnectReject{reason}
+}
+
+func (*ConnectReject) IsGameMessage() {}
+
+func (cr *ConnectReject) ToProtobuf() *pb.GameMessage {
+	return &pb.GameMessage{
+		Payload: &pb.GameMessage_ConnectReject{
+			ConnectReject: &pb.ConnectReject{Reason: cr.Reason},
+		},
+	}
+}
+
+func (cr *ConnectReject) Serialize() ([]byte, error) {
+	return marshal(cr)
+}
+
+type ViewUpdate struct {
+	Center geom.Vector2
+}
+
+func NewViewUpdate(center geom.Vector2) *ViewUpdate {
+	return &ViewUpdate{center}
+}
+
+func (*ViewUpdate) IsGameMessage() {}
+
+func (vu *ViewUpdate) ToProtobuf() *pb.GameMessage {
+	return &pb.GameMessage{
+		Payload: &pb.GameMessage_ViewUpdate{
+			ViewUpdate: &pb.ViewUpdate{Center: &pb.Position{X: vu.Center.X, Y: vu.Center.Y}},
+		},
+	}
+}
+
+func (vu *ViewUpdate) Serialize() ([]byte, error) {
+	return marshal(vu)
+}
+
+type ServerMessage struct {
+	Text string
+}
+
+func NewServerMessage(text string) *ServerMessage {
+	return &ServerMessage{text}
+}
+
+func (*ServerMessage) IsGameMessage() {}
+
+func (sm *ServerMessage) ToProtobuf() *pb.GameMessage {
+	return &pb.GameMessage{
+		Payload: &pb.GameMessage_ServerMessage{
+			ServerMessage: &pb.ServerMessage{Text: sm.Text},
+		},
+	}
+}
+
+func (sm *ServerMessage) Serialize() ([]byte, error) {
+	return marshal(sm)
+}
+
+type ServerShutdown struct {
+	Reason string
+	// where to go instead, a host:port or empty
+	ReconnectHint string
+}
+
+func NewServerShutdown(reason, reconnectHint string) *ServerShutdown {
+	return &ServerShutdown{reason, reconnectHint}
+}
+
+func (*ServerShutdown) IsGameMessage() {}
+
+func (ss *ServerShutdown) ToProtobuf() *pb.GameMessage {
+	return &pb.GameMessage{
+		Payload: &pb.GameMessage_ServerShutdown{
+			ServerShutdown: &pb.ServerShutdown{Reason: ss.Reason, ReconnectHint: ss.ReconnectHint},
+		},
+	}
+}
+
+func (ss *ServerShutdown) Serialize() ([]byte, error) {
+	return marshal(ss)
+}
+
+// the server's gameplay changed, clients play by the new one from now on
+type GameplayUpdate struct {
+	Gameplay config.Gameplay
+}
+
+func NewGameplayUpdate(gameplay config.Gameplay) *GameplayUpdate {
+	return &GameplayUpdate{gameplay}
+}
+
+func (*GameplayUpdate) IsGameMessage() {}
+
+func (gu *GameplayUpdate) ToProtobuf() *pb.GameMessage {
+	return &pb.GameMessage{
+		Payload: &pb.GameMessage_GameplayUpdate{
+			GameplayUpdate: &pb.GameplayUpdate{Gameplay: GameplayToProtobuf(gu.Gameplay)},
+		},
+	}
+}
+
+func (gu *GameplayUpdate) Serialize() ([]byte, error) {
+	return marshal(gu)
+}
+
+// asks for the server's gameplay again, when a snapshot's checksum says ours
+// is another one
+type GameplayRequest struct{}
+
+func NewGameplayRequest() *GameplayRequest {
+	return &GameplayRequest{}
+}
+
+func (*GameplayRequest) IsGameMessage() {}
+
+func (gr *GameplayRequest) ToProtobuf() *pb.GameMessage {
+	return &pb.GameMessage{
+		Payload: &pb.GameMessage_GameplayRequest{GameplayRequest: &pb.GameplayRequest{}},
+	}
+}
+
+func (gr *GameplayRequest) Serialize() ([]byte, error) {
+	return marshal(gr)
+}
@@ -3,11 +3,46 @@ package gameConn
 import (
 	"CircleWar/core/netmsg"
 	"net"
+	"slices"
 	"sync"
+	"sync/atomic"
 )
 
+// traffic totals since the connection was opened
+type ConnStats struct {
+	PacketsIn  uint64
+	PacketsOut uint64
+	BytesIn    uint64
+	BytesOut   uint64
+}
+
+type connCounters struct {
+	packetsIn, packetsOut atomic.Uint64
+	bytesIn, bytesOut     atomic.Uint64
+}
+
+func (cc *connCounters) countIn(n int) {
+	cc.packetsIn.Add(1)
+	cc.bytesIn.Add(uint64(n))
+}
+
+func (cc *connCounters) countOut(n int) {
+	cc.packetsOut.Add(1)
+	cc.bytesOut.Add(uint64(n))
+}
+
+func (cc *connCounters) stats() ConnStats {
+	return ConnStats{
+		PacketsIn:  cc.packetsIn.Load(),
+		PacketsOut: cc.packetsOut.Load(),
+		BytesIn:    cc.bytesIn.Load(),
+		BytesOut:   cc.bytesOut.Load(),
+	}
+}
+
 type ClientConn struct {
-	conn *net.UDPConn
+	conn     *net.UDPConn
+	counters connCounters
 }
 
 func NewClientConn(servAddr *net.UDPAddr) (*ClientConn, error) {
@@ -15,7 +50,11 @@ func NewClientConn(servAddr *net.UDPAddr) (*ClientConn, error) {
 	if err != nil {
 		return &ClientConn{}, err
 	}
-	return &ClientConn{conn}, nil
+	return &ClientConn{conn: conn}, nil
+}
+
+func (cc *ClientConn) Stats() ConnStats {
+	return cc.counters.stats()
 }
 
 func (cc *ClientConn) Close() error {
@@ -27,10 +66,11 @@ func (cc *ClientConn) Send(msg netmsg.GameMessage) error {
 	if err != nil {
 		return err
 	} else {
-		_, err := cc.conn.Write(bytes)
+		n, err := cc.conn.Write(bytes)
 		if err != nil {
 			return err
 		}
+		cc.counters.countOut(n)
 		return nil
 	}
 }
@@ -42,6 +82,7 @@ func (cc *ClientConn) Recieve() (netmsg.GameMessage, error) {
 	if err != nil {
 		return nil, err
 	} else {
+		cc.counters.countIn(n)
 		gameMsg, err := netmsg.Deserialize(buf, uint32(n))
 		if err != nil {
 			return nil, err
@@ -54,6 +95,11 @@ type ServerConn struct {
 	conn    *net.UDPConn
 	clients []net.UDPAddr
 	cmu     sync.Mutex // client lock
+
+	counters          connCounters
+	byType            map[string]*connCounters
+	tmu               sync.Mutex // byType lock
+	deserializeErrors atomic.Uint64
 }
 
 func NewServerConn(ip net.IP, port int) (*ServerConn, error) {
@@ -66,18 +112,74 @@ func NewServerConn(ip net.IP, port int) (*ServerConn, error) {
 	if err != nil {
 		return &ServerConn{}, err
 	}
-	return &ServerConn{conn, []net.UDPAddr{}, sync.Mutex{}}, nil
+	return &ServerConn{conn: conn, clients: []net.UDPAddr{}, byType: make(map[string]*connCounters)}, nil
 }
 
+func (sc *ServerConn) typeCounters(msgType string) *connCounters {
+	sc.tmu.Lock()
+	defer sc.tmu.Unlock()
+	cc, ok := sc.byType[msgType]
+	if !ok {
+		cc = &connCounters{}
+		sc.byType[msgType] = cc
+	}
+	return cc
+}
+
+func (sc *ServerConn) Stats() ConnStats {
+	return sc.counters.stats()
+}
+
+// traffic totals by netmsg.Name of the messages, packets that didn't
+// deserialize count as "unknown"
+func (sc *ServerConn) TypeStats() map[string]ConnStats {
+	sc.tmu.Lock()
+	defer sc.tmu.Unlock()
+	stats := make(map[string]ConnStats, len(sc.byType))
+	for msgType, cc := range sc.byType {
+		stats[msgType] = cc.stats()
+	}
+	return stats
+}
+
+// packets that arrived but weren't game messages
+func (sc *ServerConn) DeserializeErrors() uint64 {
+	return sc.deserializeErrors.Load()
+}
+
+// adds the address to the broadcast set, adding it twice does nothing
 func (sc *ServerConn) AddListener(newListener net.UDPAddr) {
 	sc.cmu.Lock()
 	defer sc.cmu.Unlock()
+	for _, addr := range sc.clients {
+		if addr.String() == newListener.String() {
+			return
+		}
+	}
 	sc.clients = append(sc.clients, newListener)
 }
 
+// copy of the broadcast set, for sending each listener its own message
+func (sc *ServerConn) Listeners() []net.UDPAddr {
+	sc.cmu.Lock()
+	defer sc.cmu.Unlock()
+	return slices.Clone(sc.clients)
+}
+
+func (sc *ServerConn) IsListener(addr net.UDPAddr) bool {
+	sc.cmu.Lock()
+	defer sc.cmu.Unlock()
+	return slices.ContainsFunc(sc.clients, func(client net.UDPAddr) bool {
+		return client.String() == addr.String()
+	})
+}
+
 func (sc *ServerConn) RemoveListener(listener net.UDPAddr) {
 	sc.cmu.Lock()
 	defer sc.cmu.Unlock()
+	sc.clients = slices.DeleteFunc(sc.clients, func(addr net.UDPAddr) bool {
+		return addr.String() == listener.String()
+	})
 }
 
 func (sc *ServerConn) Close() error {
@@ -101,10 +203,12 @@ func (cc *ServerConn) SendTo(msg netmsg.GameMessage, addr net.UDPAddr) error {
 	if err != nil {
 		return err
 	} else {
-		_, err := cc.conn.WriteToUDP(bytes, &addr)
+		n, err := cc.conn.WriteToUDP(bytes, &addr)
 		if err != nil {
 			return err
 		}
+		cc.counters.countOut(n)
+		cc.typeCounters(netmsg.Name(msg)).countOut(n)
 	}
 	return nil
 }
@@ -115,10 +219,14 @@ func (cc *ServerConn) Recieve() (netmsg.GameMessage, net.UDPAddr, error) {
 	if err != nil {
 		return nil, net.UDPAddr{}, err
 	} else {
+		cc.counters.countIn(n)
 		gameMsg, err := netmsg.Deserialize(buf, uint32(n))
 		if err != nil {
+			cc.deserializeErrors.Add(1)
+			cc.typeCounters("unknown").countIn(n)
 			return nil, net.UDPAddr{}, err
 		}
+		cc.typeCounters(netmsg.Name(gameMsg)).countIn(n)
 		return gameMsg, *addr, nil
 	}
 }
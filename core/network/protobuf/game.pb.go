@@ -77,6 +77,168 @@ func (Direction) EnumDescriptor() ([]byte, []int) {
 	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{0}
 }
 
+type WeaponType int32
+
+const (
+	WeaponType_WEAPON_PISTOL  WeaponType = 0
+	WeaponType_WEAPON_SHOTGUN WeaponType = 1
+	WeaponType_WEAPON_SNIPER  WeaponType = 2
+	WeaponType_WEAPON_SMG     WeaponType = 3
+)
+
+// Enum value maps for WeaponType.
+var (
+	WeaponType_name = map[int32]string{
+		0: "WEAPON_PISTOL",
+		1: "WEAPON_SHOTGUN",
+		2: "WEAPON_SNIPER",
+		3: "WEAPON_SMG",
+	}
+	WeaponType_value = map[string]int32{
+		"WEAPON_PISTOL":  0,
+		"WEAPON_SHOTGUN": 1,
+		"WEAPON_SNIPER":  2,
+		"WEAPON_SMG":     3,
+	}
+)
+
+func (x WeaponType) Enum() *WeaponType {
+	p := new(WeaponType)
+	*p = x
+	return p
+}
+
+func (x WeaponType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (WeaponType) Descriptor() protoreflect.EnumDescriptor {
+	return file_core_network_protobuf_proto_src_game_proto_enumTypes[1].Descriptor()
+}
+
+func (WeaponType) Type() protoreflect.EnumType {
+	return &file_core_network_protobuf_proto_src_game_proto_enumTypes[1]
+}
+
+func (x WeaponType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use WeaponType.Descriptor instead.
+func (WeaponType) EnumDescriptor() ([]byte, []int) {
+	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{1}
+}
+
+type PickupKind int32
+
+const (
+	PickupKind_PICKUP_HEALTH      PickupKind = 0
+	PickupKind_PICKUP_SPEED       PickupKind = 1
+	PickupKind_PICKUP_RAPID_FIRE  PickupKind = 2
+	PickupKind_PICKUP_SHIELD      PickupKind = 3
+	PickupKind_PICKUP_BIG_BULLETS PickupKind = 4
+	PickupKind_PICKUP_ARMOR       PickupKind = 5
+)
+
+// Enum value maps for PickupKind.
+var (
+	PickupKind_name = map[int32]string{
+		0: "PICKUP_HEALTH",
+		1: "PICKUP_SPEED",
+		2: "PICKUP_RAPID_FIRE",
+		3: "PICKUP_SHIELD",
+		4: "PICKUP_BIG_BULLETS",
+		5: "PICKUP_ARMOR",
+	}
+	PickupKind_value = map[string]int32{
+		"PICKUP_HEALTH":      0,
+		"PICKUP_SPEED":       1,
+		"PICKUP_RAPID_FIRE":  2,
+		"PICKUP_SHIELD":      3,
+		"PICKUP_BIG_BULLETS": 4,
+		"PICKUP_ARMOR":       5,
+	}
+)
+
+func (x PickupKind) Enum() *PickupKind {
+	p := new(PickupKind)
+	*p = x
+	return p
+}
+
+func (x PickupKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PickupKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_core_network_protobuf_proto_src_game_proto_enumTypes[2].Descriptor()
+}
+
+func (PickupKind) Type() protoreflect.EnumType {
+	return &file_core_network_protobuf_proto_src_game_proto_enumTypes[2]
+}
+
+func (x PickupKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PickupKind.Descriptor instead.
+func (PickupKind) EnumDescriptor() ([]byte, []int) {
+	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{2}
+}
+
+type PlayerEventKind int32
+
+const (
+	PlayerEventKind_PLAYER_EVENT_NONE       PlayerEventKind = 0
+	PlayerEventKind_PLAYER_EVENT_CONNECT    PlayerEventKind = 1
+	PlayerEventKind_PLAYER_EVENT_RECONNECT  PlayerEventKind = 2
+	PlayerEventKind_PLAYER_EVENT_DISCONNECT PlayerEventKind = 3
+)
+
+// Enum value maps for PlayerEventKind.
+var (
+	PlayerEventKind_name = map[int32]string{
+		0: "PLAYER_EVENT_NONE",
+		1: "PLAYER_EVENT_CONNECT",
+		2: "PLAYER_EVENT_RECONNECT",
+		3: "PLAYER_EVENT_DISCONNECT",
+	}
+	PlayerEventKind_value = map[string]int32{
+		"PLAYER_EVENT_NONE":       0,
+		"PLAYER_EVENT_CONNECT":    1,
+		"PLAYER_EVENT_RECONNECT":  2,
+		"PLAYER_EVENT_DISCONNECT": 3,
+	}
+)
+
+func (x PlayerEventKind) Enum() *PlayerEventKind {
+	p := new(PlayerEventKind)
+	*p = x
+	return p
+}
+
+func (x PlayerEventKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PlayerEventKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_core_network_protobuf_proto_src_game_proto_enumTypes[3].Descriptor()
+}
+
+func (PlayerEventKind) Type() protoreflect.EnumType {
+	return &file_core_network_protobuf_proto_src_game_proto_enumTypes[3]
+}
+
+func (x PlayerEventKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PlayerEventKind.Descriptor instead.
+func (PlayerEventKind) EnumDescriptor() ([]byte, []int) {
+	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{3}
+}
+
 type MoveAction struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Dir           Direction              `protobuf:"varint,1,opt,name=dir,proto3,enum=proto.Direction" json:"dir,omitempty"`
@@ -124,7 +286,9 @@ func (x *MoveAction) GetDir() Direction {
 type ShootAction struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// shoot target coordinates
-	Target        *Position `protobuf:"bytes,1,opt,name=target,proto3" json:"target,omitempty"`
+	Target *Position `protobuf:"bytes,1,opt,name=target,proto3" json:"target,omitempty"`
+	// slot of the weapon in the player's loadout
+	Slot          uint32 `protobuf:"varint,2,opt,name=slot,proto3" json:"slot,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -166,12 +330,113 @@ func (x *ShootAction) GetTarget() *Position {
 	return nil
 }
 
+func (x *ShootAction) GetSlot() uint32 {
+	if x != nil {
+		return x.Slot
+	}
+	return 0
+}
+
+// sent every frame instead of MoveAction by clients with sticks or smooth
+// diagonals, the server clamps move to length 1
+type AnalogAction struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Move  *Position              `protobuf:"bytes,1,opt,name=move,proto3" json:"move,omitempty"`
+	// radians, 0 points right and it grows clockwise
+	Aim           float32 `protobuf:"fixed32,2,opt,name=aim,proto3" json:"aim,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnalogAction) Reset() {
+	*x = AnalogAction{}
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnalogAction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalogAction) ProtoMessage() {}
+
+func (x *AnalogAction) ProtoReflect() protoreflect.Message {
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalogAction.ProtoReflect.Descriptor instead.
+func (*AnalogAction) Descriptor() ([]byte, []int) {
+	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AnalogAction) GetMove() *Position {
+	if x != nil {
+		return x.Move
+	}
+	return nil
+}
+
+func (x *AnalogAction) GetAim() float32 {
+	if x != nil {
+		return x.Aim
+	}
+	return 0
+}
+
+// a quick burst the way the player moves, or aims when standing still
+type DashAction struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DashAction) Reset() {
+	*x = DashAction{}
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DashAction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DashAction) ProtoMessage() {}
+
+func (x *DashAction) ProtoReflect() protoreflect.Message {
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DashAction.ProtoReflect.Descriptor instead.
+func (*DashAction) Descriptor() ([]byte, []int) {
+	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{3}
+}
+
 type PlayerAction struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Types that are valid to be assigned to Action:
 	//
 	//	*PlayerAction_Move
 	//	*PlayerAction_Shoot
+	//	*PlayerAction_Analog
+	//	*PlayerAction_Dash
 	Action        isPlayerAction_Action `protobuf_oneof:"action"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -179,7 +444,7 @@ type PlayerAction struct {
 
 func (x *PlayerAction) Reset() {
 	*x = PlayerAction{}
-	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[2]
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -191,7 +456,7 @@ func (x *PlayerAction) String() string {
 func (*PlayerAction) ProtoMessage() {}
 
 func (x *PlayerAction) ProtoReflect() protoreflect.Message {
-	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[2]
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -204,7 +469,7 @@ func (x *PlayerAction) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PlayerAction.ProtoReflect.Descriptor instead.
 func (*PlayerAction) Descriptor() ([]byte, []int) {
-	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{2}
+	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *PlayerAction) GetAction() isPlayerAction_Action {
@@ -232,6 +497,24 @@ func (x *PlayerAction) GetShoot() *ShootAction {
 	return nil
 }
 
+func (x *PlayerAction) GetAnalog() *AnalogAction {
+	if x != nil {
+		if x, ok := x.Action.(*PlayerAction_Analog); ok {
+			return x.Analog
+		}
+	}
+	return nil
+}
+
+func (x *PlayerAction) GetDash() *DashAction {
+	if x != nil {
+		if x, ok := x.Action.(*PlayerAction_Dash); ok {
+			return x.Dash
+		}
+	}
+	return nil
+}
+
 type isPlayerAction_Action interface {
 	isPlayerAction_Action()
 }
@@ -244,10 +527,22 @@ type PlayerAction_Shoot struct {
 	Shoot *ShootAction `protobuf:"bytes,2,opt,name=shoot,proto3,oneof"`
 }
 
+type PlayerAction_Analog struct {
+	Analog *AnalogAction `protobuf:"bytes,3,opt,name=analog,proto3,oneof"`
+}
+
+type PlayerAction_Dash struct {
+	Dash *DashAction `protobuf:"bytes,4,opt,name=dash,proto3,oneof"`
+}
+
 func (*PlayerAction_Move) isPlayerAction_Action() {}
 
 func (*PlayerAction_Shoot) isPlayerAction_Action() {}
 
+func (*PlayerAction_Analog) isPlayerAction_Action() {}
+
+func (*PlayerAction_Dash) isPlayerAction_Action() {}
+
 type PlayerInput struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	PlayerActions []*PlayerAction        `protobuf:"bytes,1,rep,name=player_actions,json=playerActions,proto3" json:"player_actions,omitempty"`
@@ -258,7 +553,7 @@ type PlayerInput struct {
 
 func (x *PlayerInput) Reset() {
 	*x = PlayerInput{}
-	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[3]
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -270,7 +565,7 @@ func (x *PlayerInput) String() string {
 func (*PlayerInput) ProtoMessage() {}
 
 func (x *PlayerInput) ProtoReflect() protoreflect.Message {
-	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[3]
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -283,7 +578,7 @@ func (x *PlayerInput) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PlayerInput.ProtoReflect.Descriptor instead.
 func (*PlayerInput) Descriptor() ([]byte, []int) {
-	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{3}
+	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *PlayerInput) GetPlayerActions() []*PlayerAction {
@@ -310,7 +605,7 @@ type Position struct {
 
 func (x *Position) Reset() {
 	*x = Position{}
-	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[4]
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -322,7 +617,7 @@ func (x *Position) String() string {
 func (*Position) ProtoMessage() {}
 
 func (x *Position) ProtoReflect() protoreflect.Message {
-	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[4]
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -335,7 +630,7 @@ func (x *Position) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Position.ProtoReflect.Descriptor instead.
 func (*Position) Descriptor() ([]byte, []int) {
-	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{4}
+	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *Position) GetX() float32 {
@@ -352,18 +647,75 @@ func (x *Position) GetY() float32 {
 	return 0
 }
 
-type PlayerState struct {
+// a power-up running on a player
+type Effect struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Pos           *Position              `protobuf:"bytes,1,opt,name=pos,proto3" json:"pos,omitempty"`
-	Health        float32                `protobuf:"fixed32,2,opt,name=health,proto3" json:"health,omitempty"`
-	PlayerId      uint32                 `protobuf:"varint,3,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	Kind          PickupKind             `protobuf:"varint,1,opt,name=kind,proto3,enum=proto.PickupKind" json:"kind,omitempty"`
+	RemainingMs   uint32                 `protobuf:"varint,2,opt,name=remaining_ms,json=remainingMs,proto3" json:"remaining_ms,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
+func (x *Effect) Reset() {
+	*x = Effect{}
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Effect) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Effect) ProtoMessage() {}
+
+func (x *Effect) ProtoReflect() protoreflect.Message {
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Effect.ProtoReflect.Descriptor instead.
+func (*Effect) Descriptor() ([]byte, []int) {
+	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Effect) GetKind() PickupKind {
+	if x != nil {
+		return x.Kind
+	}
+	return PickupKind_PICKUP_HEALTH
+}
+
+func (x *Effect) GetRemainingMs() uint32 {
+	if x != nil {
+		return x.RemainingMs
+	}
+	return 0
+}
+
+type PlayerState struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Pos      *Position              `protobuf:"bytes,1,opt,name=pos,proto3" json:"pos,omitempty"`
+	Health   float32                `protobuf:"fixed32,2,opt,name=health,proto3" json:"health,omitempty"`
+	PlayerId uint32                 `protobuf:"varint,3,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	Aim      float32                `protobuf:"fixed32,4,opt,name=aim,proto3" json:"aim,omitempty"`
+	Effects  []*Effect              `protobuf:"bytes,5,rep,name=effects,proto3" json:"effects,omitempty"`
+	// time until the player can dash again
+	DashCooldownMs uint32 `protobuf:"varint,6,opt,name=dash_cooldown_ms,json=dashCooldownMs,proto3" json:"dash_cooldown_ms,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
 func (x *PlayerState) Reset() {
 	*x = PlayerState{}
-	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[5]
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -375,7 +727,7 @@ func (x *PlayerState) String() string {
 func (*PlayerState) ProtoMessage() {}
 
 func (x *PlayerState) ProtoReflect() protoreflect.Message {
-	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[5]
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -388,7 +740,7 @@ func (x *PlayerState) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PlayerState.ProtoReflect.Descriptor instead.
 func (*PlayerState) Descriptor() ([]byte, []int) {
-	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{5}
+	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *PlayerState) GetPos() *Position {
@@ -412,18 +764,40 @@ func (x *PlayerState) GetPlayerId() uint32 {
 	return 0
 }
 
+func (x *PlayerState) GetAim() float32 {
+	if x != nil {
+		return x.Aim
+	}
+	return 0
+}
+
+func (x *PlayerState) GetEffects() []*Effect {
+	if x != nil {
+		return x.Effects
+	}
+	return nil
+}
+
+func (x *PlayerState) GetDashCooldownMs() uint32 {
+	if x != nil {
+		return x.DashCooldownMs
+	}
+	return 0
+}
+
 type BulletState struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Pos           *Position              `protobuf:"bytes,1,opt,name=pos,proto3" json:"pos,omitempty"`
 	Size          float32                `protobuf:"fixed32,2,opt,name=size,proto3" json:"size,omitempty"`
 	OwnerId       uint32                 `protobuf:"varint,3,opt,name=owner_id,json=ownerId,proto3" json:"owner_id,omitempty"`
+	Weapon        WeaponType             `protobuf:"varint,4,opt,name=weapon,proto3,enum=proto.WeaponType" json:"weapon,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *BulletState) Reset() {
 	*x = BulletState{}
-	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[6]
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -435,7 +809,7 @@ func (x *BulletState) String() string {
 func (*BulletState) ProtoMessage() {}
 
 func (x *BulletState) ProtoReflect() protoreflect.Message {
-	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[6]
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -448,7 +822,7 @@ func (x *BulletState) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BulletState.ProtoReflect.Descriptor instead.
 func (*BulletState) Descriptor() ([]byte, []int) {
-	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{6}
+	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *BulletState) GetPos() *Position {
@@ -472,18 +846,89 @@ func (x *BulletState) GetOwnerId() uint32 {
 	return 0
 }
 
-type WorldState struct {
+func (x *BulletState) GetWeapon() WeaponType {
+	if x != nil {
+		return x.Weapon
+	}
+	return WeaponType_WEAPON_PISTOL
+}
+
+type PickupState struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	TickNum       uint32                 `protobuf:"varint,1,opt,name=tick_num,json=tickNum,proto3" json:"tick_num,omitempty"`
-	Players       []*PlayerState         `protobuf:"bytes,2,rep,name=players,proto3" json:"players,omitempty"`
-	Bullets       []*BulletState         `protobuf:"bytes,3,rep,name=bullets,proto3" json:"bullets,omitempty"`
+	PickupId      uint32                 `protobuf:"varint,1,opt,name=pickup_id,json=pickupId,proto3" json:"pickup_id,omitempty"`
+	Pos           *Position              `protobuf:"bytes,2,opt,name=pos,proto3" json:"pos,omitempty"`
+	Kind          PickupKind             `protobuf:"varint,3,opt,name=kind,proto3,enum=proto.PickupKind" json:"kind,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *WorldState) Reset() {
-	*x = WorldState{}
-	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[7]
+func (x *PickupState) Reset() {
+	*x = PickupState{}
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PickupState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PickupState) ProtoMessage() {}
+
+func (x *PickupState) ProtoReflect() protoreflect.Message {
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PickupState.ProtoReflect.Descriptor instead.
+func (*PickupState) Descriptor() ([]byte, []int) {
+	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *PickupState) GetPickupId() uint32 {
+	if x != nil {
+		return x.PickupId
+	}
+	return 0
+}
+
+func (x *PickupState) GetPos() *Position {
+	if x != nil {
+		return x.Pos
+	}
+	return nil
+}
+
+func (x *PickupState) GetKind() PickupKind {
+	if x != nil {
+		return x.Kind
+	}
+	return PickupKind_PICKUP_HEALTH
+}
+
+type WorldState struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	TickNum uint32                 `protobuf:"varint,1,opt,name=tick_num,json=tickNum,proto3" json:"tick_num,omitempty"`
+	Players []*PlayerState         `protobuf:"bytes,2,rep,name=players,proto3" json:"players,omitempty"`
+	Bullets []*BulletState         `protobuf:"bytes,3,rep,name=bullets,proto3" json:"bullets,omitempty"`
+	Pickups []*PickupState         `protobuf:"bytes,4,rep,name=pickups,proto3" json:"pickups,omitempty"`
+	// of the gameplay the server plays by, clients that have another one ask
+	// for it with a GameplayRequest
+	GameplayChecksum uint32 `protobuf:"varint,5,opt,name=gameplay_checksum,json=gameplayChecksum,proto3" json:"gameplay_checksum,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *WorldState) Reset() {
+	*x = WorldState{}
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -492,10 +937,974 @@ func (x *WorldState) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*WorldState) ProtoMessage() {}
+func (*WorldState) ProtoMessage() {}
+
+func (x *WorldState) ProtoReflect() protoreflect.Message {
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorldState.ProtoReflect.Descriptor instead.
+func (*WorldState) Descriptor() ([]byte, []int) {
+	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *WorldState) GetTickNum() uint32 {
+	if x != nil {
+		return x.TickNum
+	}
+	return 0
+}
+
+func (x *WorldState) GetPlayers() []*PlayerState {
+	if x != nil {
+		return x.Players
+	}
+	return nil
+}
+
+func (x *WorldState) GetBullets() []*BulletState {
+	if x != nil {
+		return x.Bullets
+	}
+	return nil
+}
+
+func (x *WorldState) GetPickups() []*PickupState {
+	if x != nil {
+		return x.Pickups
+	}
+	return nil
+}
+
+func (x *WorldState) GetGameplayChecksum() uint32 {
+	if x != nil {
+		return x.GameplayChecksum
+	}
+	return 0
+}
+
+type ConnectRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GameName      string                 `protobuf:"bytes,1,opt,name=game_name,json=gameName,proto3" json:"game_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConnectRequest) Reset() {
+	*x = ConnectRequest{}
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConnectRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConnectRequest) ProtoMessage() {}
+
+func (x *ConnectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConnectRequest.ProtoReflect.Descriptor instead.
+func (*ConnectRequest) Descriptor() ([]byte, []int) {
+	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ConnectRequest) GetGameName() string {
+	if x != nil {
+		return x.GameName
+	}
+	return ""
+}
+
+// the server's gameplay settings, sent when joining so the client agrees
+// on sizes and speeds
+type GameplayConfig struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	WorldWidth          float32                `protobuf:"fixed32,1,opt,name=world_width,json=worldWidth,proto3" json:"world_width,omitempty"`
+	WorldHeight         float32                `protobuf:"fixed32,2,opt,name=world_height,json=worldHeight,proto3" json:"world_height,omitempty"`
+	PlayerSpeed         float32                `protobuf:"fixed32,3,opt,name=player_speed,json=playerSpeed,proto3" json:"player_speed,omitempty"`
+	DashSpeed           float32                `protobuf:"fixed32,4,opt,name=dash_speed,json=dashSpeed,proto3" json:"dash_speed,omitempty"`
+	DashDurationMs      int32                  `protobuf:"varint,5,opt,name=dash_duration_ms,json=dashDurationMs,proto3" json:"dash_duration_ms,omitempty"`
+	DashInvulnerableMs  int32                  `protobuf:"varint,6,opt,name=dash_invulnerable_ms,json=dashInvulnerableMs,proto3" json:"dash_invulnerable_ms,omitempty"`
+	DashCooldownMs      int32                  `protobuf:"varint,7,opt,name=dash_cooldown_ms,json=dashCooldownMs,proto3" json:"dash_cooldown_ms,omitempty"`
+	BodyBlock           bool                   `protobuf:"varint,8,opt,name=body_block,json=bodyBlock,proto3" json:"body_block,omitempty"`
+	BulletSpeed         float32                `protobuf:"fixed32,9,opt,name=bullet_speed,json=bulletSpeed,proto3" json:"bullet_speed,omitempty"`
+	BulletTimeToLiveSec float32                `protobuf:"fixed32,10,opt,name=bullet_time_to_live_sec,json=bulletTimeToLiveSec,proto3" json:"bullet_time_to_live_sec,omitempty"`
+	BulletCooldownMs    int32                  `protobuf:"varint,11,opt,name=bullet_cooldown_ms,json=bulletCooldownMs,proto3" json:"bullet_cooldown_ms,omitempty"`
+	InitialPlayerHealth float32                `protobuf:"fixed32,12,opt,name=initial_player_health,json=initialPlayerHealth,proto3" json:"initial_player_health,omitempty"`
+	InitialPlayerSize   float32                `protobuf:"fixed32,13,opt,name=initial_player_size,json=initialPlayerSize,proto3" json:"initial_player_size,omitempty"`
+	PlayerShrinkStep    float32                `protobuf:"fixed32,14,opt,name=player_shrink_step,json=playerShrinkStep,proto3" json:"player_shrink_step,omitempty"`
+	InitialBulletSize   float32                `protobuf:"fixed32,15,opt,name=initial_bullet_size,json=initialBulletSize,proto3" json:"initial_bullet_size,omitempty"`
+	BulletShrinkStep    float32                `protobuf:"fixed32,16,opt,name=bullet_shrink_step,json=bulletShrinkStep,proto3" json:"bullet_shrink_step,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *GameplayConfig) Reset() {
+	*x = GameplayConfig{}
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GameplayConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GameplayConfig) ProtoMessage() {}
+
+func (x *GameplayConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GameplayConfig.ProtoReflect.Descriptor instead.
+func (*GameplayConfig) Descriptor() ([]byte, []int) {
+	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GameplayConfig) GetWorldWidth() float32 {
+	if x != nil {
+		return x.WorldWidth
+	}
+	return 0
+}
+
+func (x *GameplayConfig) GetWorldHeight() float32 {
+	if x != nil {
+		return x.WorldHeight
+	}
+	return 0
+}
+
+func (x *GameplayConfig) GetPlayerSpeed() float32 {
+	if x != nil {
+		return x.PlayerSpeed
+	}
+	return 0
+}
+
+func (x *GameplayConfig) GetDashSpeed() float32 {
+	if x != nil {
+		return x.DashSpeed
+	}
+	return 0
+}
+
+func (x *GameplayConfig) GetDashDurationMs() int32 {
+	if x != nil {
+		return x.DashDurationMs
+	}
+	return 0
+}
+
+func (x *GameplayConfig) GetDashInvulnerableMs() int32 {
+	if x != nil {
+		return x.DashInvulnerableMs
+	}
+	return 0
+}
+
+func (x *GameplayConfig) GetDashCooldownMs() int32 {
+	if x != nil {
+		return x.DashCooldownMs
+	}
+	return 0
+}
+
+func (x *GameplayConfig) GetBodyBlock() bool {
+	if x != nil {
+		return x.BodyBlock
+	}
+	return false
+}
+
+func (x *GameplayConfig) GetBulletSpeed() float32 {
+	if x != nil {
+		return x.BulletSpeed
+	}
+	return 0
+}
+
+func (x *GameplayConfig) GetBulletTimeToLiveSec() float32 {
+	if x != nil {
+		return x.BulletTimeToLiveSec
+	}
+	return 0
+}
+
+func (x *GameplayConfig) GetBulletCooldownMs() int32 {
+	if x != nil {
+		return x.BulletCooldownMs
+	}
+	return 0
+}
+
+func (x *GameplayConfig) GetInitialPlayerHealth() float32 {
+	if x != nil {
+		return x.InitialPlayerHealth
+	}
+	return 0
+}
+
+func (x *GameplayConfig) GetInitialPlayerSize() float32 {
+	if x != nil {
+		return x.InitialPlayerSize
+	}
+	return 0
+}
+
+func (x *GameplayConfig) GetPlayerShrinkStep() float32 {
+	if x != nil {
+		return x.PlayerShrinkStep
+	}
+	return 0
+}
+
+func (x *GameplayConfig) GetInitialBulletSize() float32 {
+	if x != nil {
+		return x.InitialBulletSize
+	}
+	return 0
+}
+
+func (x *GameplayConfig) GetBulletShrinkStep() float32 {
+	if x != nil {
+		return x.BulletShrinkStep
+	}
+	return 0
+}
+
+type ConnectAck struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PlayerId      uint32                 `protobuf:"varint,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	Gameplay      *GameplayConfig        `protobuf:"bytes,2,opt,name=gameplay,proto3" json:"gameplay,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConnectAck) Reset() {
+	*x = ConnectAck{}
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConnectAck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConnectAck) ProtoMessage() {}
+
+func (x *ConnectAck) ProtoReflect() protoreflect.Message {
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConnectAck.ProtoReflect.Descriptor instead.
+func (*ConnectAck) Descriptor() ([]byte, []int) {
+	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ConnectAck) GetPlayerId() uint32 {
+	if x != nil {
+		return x.PlayerId
+	}
+	return 0
+}
+
+func (x *ConnectAck) GetGameplay() *GameplayConfig {
+	if x != nil {
+		return x.Gameplay
+	}
+	return nil
+}
+
+type DeathNote struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	PlayerId uint32                 `protobuf:"varint,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	// the player can't respawn and watches the rest of the match
+	Spectating    bool `protobuf:"varint,2,opt,name=spectating,proto3" json:"spectating,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeathNote) Reset() {
+	*x = DeathNote{}
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeathNote) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeathNote) ProtoMessage() {}
+
+func (x *DeathNote) ProtoReflect() protoreflect.Message {
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeathNote.ProtoReflect.Descriptor instead.
+func (*DeathNote) Descriptor() ([]byte, []int) {
+	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *DeathNote) GetPlayerId() uint32 {
+	if x != nil {
+		return x.PlayerId
+	}
+	return 0
+}
+
+func (x *DeathNote) GetSpectating() bool {
+	if x != nil {
+		return x.Spectating
+	}
+	return false
+}
+
+type ReconnectRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OldPlayerId   uint32                 `protobuf:"varint,1,opt,name=old_player_id,json=oldPlayerId,proto3" json:"old_player_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReconnectRequest) Reset() {
+	*x = ReconnectRequest{}
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReconnectRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReconnectRequest) ProtoMessage() {}
+
+func (x *ReconnectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReconnectRequest.ProtoReflect.Descriptor instead.
+func (*ReconnectRequest) Descriptor() ([]byte, []int) {
+	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ReconnectRequest) GetOldPlayerId() uint32 {
+	if x != nil {
+		return x.OldPlayerId
+	}
+	return 0
+}
+
+// watch the game without a player
+type SpectateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GameName      string                 `protobuf:"bytes,1,opt,name=game_name,json=gameName,proto3" json:"game_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SpectateRequest) Reset() {
+	*x = SpectateRequest{}
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SpectateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SpectateRequest) ProtoMessage() {}
+
+func (x *SpectateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SpectateRequest.ProtoReflect.Descriptor instead.
+func (*SpectateRequest) Descriptor() ([]byte, []int) {
+	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *SpectateRequest) GetGameName() string {
+	if x != nil {
+		return x.GameName
+	}
+	return ""
+}
+
+type SpectateAck struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Gameplay      *GameplayConfig        `protobuf:"bytes,1,opt,name=gameplay,proto3" json:"gameplay,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SpectateAck) Reset() {
+	*x = SpectateAck{}
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SpectateAck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SpectateAck) ProtoMessage() {}
+
+func (x *SpectateAck) ProtoReflect() protoreflect.Message {
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SpectateAck.ProtoReflect.Descriptor instead.
+func (*SpectateAck) Descriptor() ([]byte, []int) {
+	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *SpectateAck) GetGameplay() *GameplayConfig {
+	if x != nil {
+		return x.Gameplay
+	}
+	return nil
+}
+
+type ConnectReject struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reason        string                 `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConnectReject) Reset() {
+	*x = ConnectReject{}
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConnectReject) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConnectReject) ProtoMessage() {}
+
+func (x *ConnectReject) ProtoReflect() protoreflect.Message {
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConnectReject.ProtoReflect.Descriptor instead.
+func (*ConnectReject) Descriptor() ([]byte, []int) {
+	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ConnectReject) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// where a spectator's camera looks, players are seen from their position
+type ViewUpdate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Center        *Position              `protobuf:"bytes,1,opt,name=center,proto3" json:"center,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ViewUpdate) Reset() {
+	*x = ViewUpdate{}
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ViewUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ViewUpdate) ProtoMessage() {}
+
+func (x *ViewUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ViewUpdate.ProtoReflect.Descriptor instead.
+func (*ViewUpdate) Descriptor() ([]byte, []int) {
+	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ViewUpdate) GetCenter() *Position {
+	if x != nil {
+		return x.Center
+	}
+	return nil
+}
+
+// a message from whoever runs the server, shown to every client
+type ServerMessage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServerMessage) Reset() {
+	*x = ServerMessage{}
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerMessage) ProtoMessage() {}
+
+func (x *ServerMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerMessage.ProtoReflect.Descriptor instead.
+func (*ServerMessage) Descriptor() ([]byte, []int) {
+	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ServerMessage) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+// the server's gameplay settings changed while it runs
+type GameplayUpdate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Gameplay      *GameplayConfig        `protobuf:"bytes,1,opt,name=gameplay,proto3" json:"gameplay,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GameplayUpdate) Reset() {
+	*x = GameplayUpdate{}
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GameplayUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GameplayUpdate) ProtoMessage() {}
+
+func (x *GameplayUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GameplayUpdate.ProtoReflect.Descriptor instead.
+func (*GameplayUpdate) Descriptor() ([]byte, []int) {
+	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *GameplayUpdate) GetGameplay() *GameplayConfig {
+	if x != nil {
+		return x.Gameplay
+	}
+	return nil
+}
+
+// a client missed a GameplayUpdate, the server sends it again
+type GameplayRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GameplayRequest) Reset() {
+	*x = GameplayRequest{}
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GameplayRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GameplayRequest) ProtoMessage() {}
+
+func (x *GameplayRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GameplayRequest.ProtoReflect.Descriptor instead.
+func (*GameplayRequest) Descriptor() ([]byte, []int) {
+	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{23}
+}
+
+// sent to every client when the server stops
+type ServerShutdown struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Reason string                 `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	// where to go instead, a host:port or empty
+	ReconnectHint string `protobuf:"bytes,2,opt,name=reconnect_hint,json=reconnectHint,proto3" json:"reconnect_hint,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServerShutdown) Reset() {
+	*x = ServerShutdown{}
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerShutdown) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerShutdown) ProtoMessage() {}
+
+func (x *ServerShutdown) ProtoReflect() protoreflect.Message {
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerShutdown.ProtoReflect.Descriptor instead.
+func (*ServerShutdown) Descriptor() ([]byte, []int) {
+	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ServerShutdown) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *ServerShutdown) GetReconnectHint() string {
+	if x != nil {
+		return x.ReconnectHint
+	}
+	return ""
+}
+
+// the receiver of a ping echoes it back as a pong
+type Ping struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seq           uint32                 `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	SentUnixNano  int64                  `protobuf:"varint,2,opt,name=sent_unix_nano,json=sentUnixNano,proto3" json:"sent_unix_nano,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Ping) Reset() {
+	*x = Ping{}
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Ping) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Ping) ProtoMessage() {}
+
+func (x *Ping) ProtoReflect() protoreflect.Message {
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Ping.ProtoReflect.Descriptor instead.
+func (*Ping) Descriptor() ([]byte, []int) {
+	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *Ping) GetSeq() uint32 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *Ping) GetSentUnixNano() int64 {
+	if x != nil {
+		return x.SentUnixNano
+	}
+	return 0
+}
+
+type Pong struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seq           uint32                 `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	SentUnixNano  int64                  `protobuf:"varint,2,opt,name=sent_unix_nano,json=sentUnixNano,proto3" json:"sent_unix_nano,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Pong) Reset() {
+	*x = Pong{}
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Pong) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Pong) ProtoMessage() {}
+
+func (x *Pong) ProtoReflect() protoreflect.Message {
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Pong.ProtoReflect.Descriptor instead.
+func (*Pong) Descriptor() ([]byte, []int) {
+	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *Pong) GetSeq() uint32 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *Pong) GetSentUnixNano() int64 {
+	if x != nil {
+		return x.SentUnixNano
+	}
+	return 0
+}
+
+type PlayerEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          PlayerEventKind        `protobuf:"varint,1,opt,name=kind,proto3,enum=proto.PlayerEventKind" json:"kind,omitempty"`
+	PlayerId      uint32                 `protobuf:"varint,2,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	Addr          string                 `protobuf:"bytes,3,opt,name=addr,proto3" json:"addr,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlayerEvent) Reset() {
+	*x = PlayerEvent{}
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlayerEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlayerEvent) ProtoMessage() {}
+
+func (x *PlayerEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlayerEvent.ProtoReflect.Descriptor instead.
+func (*PlayerEvent) Descriptor() ([]byte, []int) {
+	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *PlayerEvent) GetKind() PlayerEventKind {
+	if x != nil {
+		return x.Kind
+	}
+	return PlayerEventKind_PLAYER_EVENT_NONE
+}
+
+func (x *PlayerEvent) GetPlayerId() uint32 {
+	if x != nil {
+		return x.PlayerId
+	}
+	return 0
+}
+
+func (x *PlayerEvent) GetAddr() string {
+	if x != nil {
+		return x.Addr
+	}
+	return ""
+}
+
+// first frame of every replay file
+type ReplayHeader struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Version         uint32                 `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	TicksPerSecond  uint32                 `protobuf:"varint,2,opt,name=ticks_per_second,json=ticksPerSecond,proto3" json:"ticks_per_second,omitempty"`
+	WorldWidth      float32                `protobuf:"fixed32,3,opt,name=world_width,json=worldWidth,proto3" json:"world_width,omitempty"`
+	WorldHeight     float32                `protobuf:"fixed32,4,opt,name=world_height,json=worldHeight,proto3" json:"world_height,omitempty"`
+	StartedUnixNano int64                  `protobuf:"varint,5,opt,name=started_unix_nano,json=startedUnixNano,proto3" json:"started_unix_nano,omitempty"`
+	InitialWorld    *WorldState            `protobuf:"bytes,6,opt,name=initial_world,json=initialWorld,proto3" json:"initial_world,omitempty"`
+	// where pickups spawn, random spots when empty
+	PickupSpawns  []*Position     `protobuf:"bytes,7,rep,name=pickup_spawns,json=pickupSpawns,proto3" json:"pickup_spawns,omitempty"`
+	Gameplay      *GameplayConfig `protobuf:"bytes,8,opt,name=gameplay,proto3" json:"gameplay,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplayHeader) Reset() {
+	*x = ReplayHeader{}
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplayHeader) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayHeader) ProtoMessage() {}
 
-func (x *WorldState) ProtoReflect() protoreflect.Message {
-	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[7]
+func (x *ReplayHeader) ProtoReflect() protoreflect.Message {
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -506,98 +1915,91 @@ func (x *WorldState) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use WorldState.ProtoReflect.Descriptor instead.
-func (*WorldState) Descriptor() ([]byte, []int) {
-	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use ReplayHeader.ProtoReflect.Descriptor instead.
+func (*ReplayHeader) Descriptor() ([]byte, []int) {
+	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{28}
 }
 
-func (x *WorldState) GetTickNum() uint32 {
+func (x *ReplayHeader) GetVersion() uint32 {
 	if x != nil {
-		return x.TickNum
+		return x.Version
 	}
 	return 0
 }
 
-func (x *WorldState) GetPlayers() []*PlayerState {
+func (x *ReplayHeader) GetTicksPerSecond() uint32 {
 	if x != nil {
-		return x.Players
+		return x.TicksPerSecond
 	}
-	return nil
+	return 0
 }
 
-func (x *WorldState) GetBullets() []*BulletState {
+func (x *ReplayHeader) GetWorldWidth() float32 {
 	if x != nil {
-		return x.Bullets
+		return x.WorldWidth
 	}
-	return nil
-}
-
-type ConnectRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	GameName      string                 `protobuf:"bytes,1,opt,name=game_name,json=gameName,proto3" json:"game_name,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	return 0
 }
 
-func (x *ConnectRequest) Reset() {
-	*x = ConnectRequest{}
-	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[8]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *ReplayHeader) GetWorldHeight() float32 {
+	if x != nil {
+		return x.WorldHeight
+	}
+	return 0
 }
 
-func (x *ConnectRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *ReplayHeader) GetStartedUnixNano() int64 {
+	if x != nil {
+		return x.StartedUnixNano
+	}
+	return 0
 }
 
-func (*ConnectRequest) ProtoMessage() {}
-
-func (x *ConnectRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[8]
+func (x *ReplayHeader) GetInitialWorld() *WorldState {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.InitialWorld
 	}
-	return mi.MessageOf(x)
+	return nil
 }
 
-// Deprecated: Use ConnectRequest.ProtoReflect.Descriptor instead.
-func (*ConnectRequest) Descriptor() ([]byte, []int) {
-	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{8}
+func (x *ReplayHeader) GetPickupSpawns() []*Position {
+	if x != nil {
+		return x.PickupSpawns
+	}
+	return nil
 }
 
-func (x *ConnectRequest) GetGameName() string {
+func (x *ReplayHeader) GetGameplay() *GameplayConfig {
 	if x != nil {
-		return x.GameName
+		return x.Gameplay
 	}
-	return ""
+	return nil
 }
 
-type ConnectAck struct {
+// the gameplay changed after the first after_events events of the tick
+type GameplayChange struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	PlayerId      uint32                 `protobuf:"varint,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	AfterEvents   uint32                 `protobuf:"varint,1,opt,name=after_events,json=afterEvents,proto3" json:"after_events,omitempty"`
+	Gameplay      *GameplayConfig        `protobuf:"bytes,2,opt,name=gameplay,proto3" json:"gameplay,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ConnectAck) Reset() {
-	*x = ConnectAck{}
-	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[9]
+func (x *GameplayChange) Reset() {
+	*x = GameplayChange{}
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ConnectAck) String() string {
+func (x *GameplayChange) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ConnectAck) ProtoMessage() {}
+func (*GameplayChange) ProtoMessage() {}
 
-func (x *ConnectAck) ProtoReflect() protoreflect.Message {
-	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[9]
+func (x *GameplayChange) ProtoReflect() protoreflect.Message {
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -608,40 +2010,54 @@ func (x *ConnectAck) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ConnectAck.ProtoReflect.Descriptor instead.
-func (*ConnectAck) Descriptor() ([]byte, []int) {
-	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use GameplayChange.ProtoReflect.Descriptor instead.
+func (*GameplayChange) Descriptor() ([]byte, []int) {
+	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{29}
 }
 
-func (x *ConnectAck) GetPlayerId() uint32 {
+func (x *GameplayChange) GetAfterEvents() uint32 {
 	if x != nil {
-		return x.PlayerId
+		return x.AfterEvents
 	}
 	return 0
 }
 
-type DeathNote struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	PlayerId      uint32                 `protobuf:"varint,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *GameplayChange) GetGameplay() *GameplayConfig {
+	if x != nil {
+		return x.Gameplay
+	}
+	return nil
 }
 
-func (x *DeathNote) Reset() {
-	*x = DeathNote{}
-	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[10]
+// events and inputs applied before the tick was simulated
+type ReplayTick struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	TickNum uint32                 `protobuf:"varint,1,opt,name=tick_num,json=tickNum,proto3" json:"tick_num,omitempty"`
+	Events  []*PlayerEvent         `protobuf:"bytes,2,rep,name=events,proto3" json:"events,omitempty"`
+	Inputs  []*PlayerInput         `protobuf:"bytes,3,rep,name=inputs,proto3" json:"inputs,omitempty"`
+	// checksum of the world after the tick
+	WorldChecksum uint64 `protobuf:"varint,4,opt,name=world_checksum,json=worldChecksum,proto3" json:"world_checksum,omitempty"`
+	// in the order they happened among the events
+	GameplayChanges []*GameplayChange `protobuf:"bytes,6,rep,name=gameplay_changes,json=gameplayChanges,proto3" json:"gameplay_changes,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ReplayTick) Reset() {
+	*x = ReplayTick{}
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeathNote) String() string {
+func (x *ReplayTick) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeathNote) ProtoMessage() {}
+func (*ReplayTick) ProtoMessage() {}
 
-func (x *DeathNote) ProtoReflect() protoreflect.Message {
-	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[10]
+func (x *ReplayTick) ProtoReflect() protoreflect.Message {
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -652,40 +2068,72 @@ func (x *DeathNote) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeathNote.ProtoReflect.Descriptor instead.
-func (*DeathNote) Descriptor() ([]byte, []int) {
-	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use ReplayTick.ProtoReflect.Descriptor instead.
+func (*ReplayTick) Descriptor() ([]byte, []int) {
+	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{30}
 }
 
-func (x *DeathNote) GetPlayerId() uint32 {
+func (x *ReplayTick) GetTickNum() uint32 {
 	if x != nil {
-		return x.PlayerId
+		return x.TickNum
 	}
 	return 0
 }
 
-type ReconnectRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	OldPlayerId   uint32                 `protobuf:"varint,1,opt,name=old_player_id,json=oldPlayerId,proto3" json:"old_player_id,omitempty"`
+func (x *ReplayTick) GetEvents() []*PlayerEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *ReplayTick) GetInputs() []*PlayerInput {
+	if x != nil {
+		return x.Inputs
+	}
+	return nil
+}
+
+func (x *ReplayTick) GetWorldChecksum() uint64 {
+	if x != nil {
+		return x.WorldChecksum
+	}
+	return 0
+}
+
+func (x *ReplayTick) GetGameplayChanges() []*GameplayChange {
+	if x != nil {
+		return x.GameplayChanges
+	}
+	return nil
+}
+
+type ReplayFrame struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Frame:
+	//
+	//	*ReplayFrame_Header
+	//	*ReplayFrame_Tick
+	Frame         isReplayFrame_Frame `protobuf_oneof:"frame"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ReconnectRequest) Reset() {
-	*x = ReconnectRequest{}
-	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[11]
+func (x *ReplayFrame) Reset() {
+	*x = ReplayFrame{}
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ReconnectRequest) String() string {
+func (x *ReplayFrame) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ReconnectRequest) ProtoMessage() {}
+func (*ReplayFrame) ProtoMessage() {}
 
-func (x *ReconnectRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[11]
+func (x *ReplayFrame) ProtoReflect() protoreflect.Message {
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -696,18 +2144,52 @@ func (x *ReconnectRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ReconnectRequest.ProtoReflect.Descriptor instead.
-func (*ReconnectRequest) Descriptor() ([]byte, []int) {
-	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use ReplayFrame.ProtoReflect.Descriptor instead.
+func (*ReplayFrame) Descriptor() ([]byte, []int) {
+	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{31}
 }
 
-func (x *ReconnectRequest) GetOldPlayerId() uint32 {
+func (x *ReplayFrame) GetFrame() isReplayFrame_Frame {
 	if x != nil {
-		return x.OldPlayerId
+		return x.Frame
 	}
-	return 0
+	return nil
+}
+
+func (x *ReplayFrame) GetHeader() *ReplayHeader {
+	if x != nil {
+		if x, ok := x.Frame.(*ReplayFrame_Header); ok {
+			return x.Header
+		}
+	}
+	return nil
+}
+
+func (x *ReplayFrame) GetTick() *ReplayTick {
+	if x != nil {
+		if x, ok := x.Frame.(*ReplayFrame_Tick); ok {
+			return x.Tick
+		}
+	}
+	return nil
+}
+
+type isReplayFrame_Frame interface {
+	isReplayFrame_Frame()
+}
+
+type ReplayFrame_Header struct {
+	Header *ReplayHeader `protobuf:"bytes,1,opt,name=header,proto3,oneof"`
+}
+
+type ReplayFrame_Tick struct {
+	Tick *ReplayTick `protobuf:"bytes,2,opt,name=tick,proto3,oneof"`
 }
 
+func (*ReplayFrame_Header) isReplayFrame_Frame() {}
+
+func (*ReplayFrame_Tick) isReplayFrame_Frame() {}
+
 type GameMessage struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Types that are valid to be assigned to Payload:
@@ -718,6 +2200,16 @@ type GameMessage struct {
 	//	*GameMessage_ReconnectRequest
 	//	*GameMessage_ConnectAck
 	//	*GameMessage_DeathNote
+	//	*GameMessage_Ping
+	//	*GameMessage_Pong
+	//	*GameMessage_SpectateRequest
+	//	*GameMessage_SpectateAck
+	//	*GameMessage_ConnectReject
+	//	*GameMessage_ViewUpdate
+	//	*GameMessage_ServerMessage
+	//	*GameMessage_ServerShutdown
+	//	*GameMessage_GameplayUpdate
+	//	*GameMessage_GameplayRequest
 	Payload       isGameMessage_Payload `protobuf_oneof:"payload"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -725,7 +2217,7 @@ type GameMessage struct {
 
 func (x *GameMessage) Reset() {
 	*x = GameMessage{}
-	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[12]
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -737,7 +2229,7 @@ func (x *GameMessage) String() string {
 func (*GameMessage) ProtoMessage() {}
 
 func (x *GameMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[12]
+	mi := &file_core_network_protobuf_proto_src_game_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -750,7 +2242,7 @@ func (x *GameMessage) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GameMessage.ProtoReflect.Descriptor instead.
 func (*GameMessage) Descriptor() ([]byte, []int) {
-	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{12}
+	return file_core_network_protobuf_proto_src_game_proto_rawDescGZIP(), []int{32}
 }
 
 func (x *GameMessage) GetPayload() isGameMessage_Payload {
@@ -814,6 +2306,96 @@ func (x *GameMessage) GetDeathNote() *DeathNote {
 	return nil
 }
 
+func (x *GameMessage) GetPing() *Ping {
+	if x != nil {
+		if x, ok := x.Payload.(*GameMessage_Ping); ok {
+			return x.Ping
+		}
+	}
+	return nil
+}
+
+func (x *GameMessage) GetPong() *Pong {
+	if x != nil {
+		if x, ok := x.Payload.(*GameMessage_Pong); ok {
+			return x.Pong
+		}
+	}
+	return nil
+}
+
+func (x *GameMessage) GetSpectateRequest() *SpectateRequest {
+	if x != nil {
+		if x, ok := x.Payload.(*GameMessage_SpectateRequest); ok {
+			return x.SpectateRequest
+		}
+	}
+	return nil
+}
+
+func (x *GameMessage) GetSpectateAck() *SpectateAck {
+	if x != nil {
+		if x, ok := x.Payload.(*GameMessage_SpectateAck); ok {
+			return x.SpectateAck
+		}
+	}
+	return nil
+}
+
+func (x *GameMessage) GetConnectReject() *ConnectReject {
+	if x != nil {
+		if x, ok := x.Payload.(*GameMessage_ConnectReject); ok {
+			return x.ConnectReject
+		}
+	}
+	return nil
+}
+
+func (x *GameMessage) GetViewUpdate() *ViewUpdate {
+	if x != nil {
+		if x, ok := x.Payload.(*GameMessage_ViewUpdate); ok {
+			return x.ViewUpdate
+		}
+	}
+	return nil
+}
+
+func (x *GameMessage) GetServerMessage() *ServerMessage {
+	if x != nil {
+		if x, ok := x.Payload.(*GameMessage_ServerMessage); ok {
+			return x.ServerMessage
+		}
+	}
+	return nil
+}
+
+func (x *GameMessage) GetServerShutdown() *ServerShutdown {
+	if x != nil {
+		if x, ok := x.Payload.(*GameMessage_ServerShutdown); ok {
+			return x.ServerShutdown
+		}
+	}
+	return nil
+}
+
+func (x *GameMessage) GetGameplayUpdate() *GameplayUpdate {
+	if x != nil {
+		if x, ok := x.Payload.(*GameMessage_GameplayUpdate); ok {
+			return x.GameplayUpdate
+		}
+	}
+	return nil
+}
+
+func (x *GameMessage) GetGameplayRequest() *GameplayRequest {
+	if x != nil {
+		if x, ok := x.Payload.(*GameMessage_GameplayRequest); ok {
+			return x.GameplayRequest
+		}
+	}
+	return nil
+}
+
 type isGameMessage_Payload interface {
 	isGameMessage_Payload()
 }
@@ -842,6 +2424,46 @@ type GameMessage_DeathNote struct {
 	DeathNote *DeathNote `protobuf:"bytes,6,opt,name=death_note,json=deathNote,proto3,oneof"`
 }
 
+type GameMessage_Ping struct {
+	Ping *Ping `protobuf:"bytes,7,opt,name=ping,proto3,oneof"`
+}
+
+type GameMessage_Pong struct {
+	Pong *Pong `protobuf:"bytes,8,opt,name=pong,proto3,oneof"`
+}
+
+type GameMessage_SpectateRequest struct {
+	SpectateRequest *SpectateRequest `protobuf:"bytes,9,opt,name=spectate_request,json=spectateRequest,proto3,oneof"`
+}
+
+type GameMessage_SpectateAck struct {
+	SpectateAck *SpectateAck `protobuf:"bytes,10,opt,name=spectate_ack,json=spectateAck,proto3,oneof"`
+}
+
+type GameMessage_ConnectReject struct {
+	ConnectReject *ConnectReject `protobuf:"bytes,11,opt,name=connect_reject,json=connectReject,proto3,oneof"`
+}
+
+type GameMessage_ViewUpdate struct {
+	ViewUpdate *ViewUpdate `protobuf:"bytes,12,opt,name=view_update,json=viewUpdate,proto3,oneof"`
+}
+
+type GameMessage_ServerMessage struct {
+	ServerMessage *ServerMessage `protobuf:"bytes,13,opt,name=server_message,json=serverMessage,proto3,oneof"`
+}
+
+type GameMessage_ServerShutdown struct {
+	ServerShutdown *ServerShutdown `protobuf:"bytes,14,opt,name=server_shutdown,json=serverShutdown,proto3,oneof"`
+}
+
+type GameMessage_GameplayUpdate struct {
+	GameplayUpdate *GameplayUpdate `protobuf:"bytes,15,opt,name=gameplay_update,json=gameplayUpdate,proto3,oneof"`
+}
+
+type GameMessage_GameplayRequest struct {
+	GameplayRequest *GameplayRequest `protobuf:"bytes,16,opt,name=gameplay_request,json=gameplayRequest,proto3,oneof"`
+}
+
 func (*GameMessage_World) isGameMessage_Payload() {}
 
 func (*GameMessage_PlayerInput) isGameMessage_Payload() {}
@@ -854,6 +2476,26 @@ func (*GameMessage_ConnectAck) isGameMessage_Payload() {}
 
 func (*GameMessage_DeathNote) isGameMessage_Payload() {}
 
+func (*GameMessage_Ping) isGameMessage_Payload() {}
+
+func (*GameMessage_Pong) isGameMessage_Payload() {}
+
+func (*GameMessage_SpectateRequest) isGameMessage_Payload() {}
+
+func (*GameMessage_SpectateAck) isGameMessage_Payload() {}
+
+func (*GameMessage_ConnectReject) isGameMessage_Payload() {}
+
+func (*GameMessage_ViewUpdate) isGameMessage_Payload() {}
+
+func (*GameMessage_ServerMessage) isGameMessage_Payload() {}
+
+func (*GameMessage_ServerShutdown) isGameMessage_Payload() {}
+
+func (*GameMessage_GameplayUpdate) isGameMessage_Payload() {}
+
+func (*GameMessage_GameplayRequest) isGameMessage_Payload() {}
+
 var File_core_network_protobuf_proto_src_game_proto protoreflect.FileDescriptor
 
 const file_core_network_protobuf_proto_src_game_proto_rawDesc = "" +
@@ -861,41 +2503,138 @@ const file_core_network_protobuf_proto_src_game_proto_rawDesc = "" +
 	"*core/network/protobuf/proto_src/game.proto\x12\x05proto\"0\n" +
 	"\n" +
 	"MoveAction\x12\"\n" +
-	"\x03dir\x18\x01 \x01(\x0e2\x10.proto.DirectionR\x03dir\"6\n" +
+	"\x03dir\x18\x01 \x01(\x0e2\x10.proto.DirectionR\x03dir\"J\n" +
 	"\vShootAction\x12'\n" +
-	"\x06target\x18\x01 \x01(\v2\x0f.proto.PositionR\x06target\"m\n" +
+	"\x06target\x18\x01 \x01(\v2\x0f.proto.PositionR\x06target\x12\x12\n" +
+	"\x04slot\x18\x02 \x01(\rR\x04slot\"E\n" +
+	"\fAnalogAction\x12#\n" +
+	"\x04move\x18\x01 \x01(\v2\x0f.proto.PositionR\x04move\x12\x10\n" +
+	"\x03aim\x18\x02 \x01(\x02R\x03aim\"\f\n" +
+	"\n" +
+	"DashAction\"\xc5\x01\n" +
 	"\fPlayerAction\x12'\n" +
 	"\x04move\x18\x01 \x01(\v2\x11.proto.MoveActionH\x00R\x04move\x12*\n" +
-	"\x05shoot\x18\x02 \x01(\v2\x12.proto.ShootActionH\x00R\x05shootB\b\n" +
+	"\x05shoot\x18\x02 \x01(\v2\x12.proto.ShootActionH\x00R\x05shoot\x12-\n" +
+	"\x06analog\x18\x03 \x01(\v2\x13.proto.AnalogActionH\x00R\x06analog\x12'\n" +
+	"\x04dash\x18\x04 \x01(\v2\x11.proto.DashActionH\x00R\x04dashB\b\n" +
 	"\x06action\"f\n" +
 	"\vPlayerInput\x12:\n" +
 	"\x0eplayer_actions\x18\x01 \x03(\v2\x13.proto.PlayerActionR\rplayerActions\x12\x1b\n" +
 	"\tplayer_id\x18\x02 \x01(\rR\bplayerId\"&\n" +
 	"\bPosition\x12\f\n" +
 	"\x01x\x18\x01 \x01(\x02R\x01x\x12\f\n" +
-	"\x01y\x18\x02 \x01(\x02R\x01y\"e\n" +
+	"\x01y\x18\x02 \x01(\x02R\x01y\"R\n" +
+	"\x06Effect\x12%\n" +
+	"\x04kind\x18\x01 \x01(\x0e2\x11.proto.PickupKindR\x04kind\x12!\n" +
+	"\fremaining_ms\x18\x02 \x01(\rR\vremainingMs\"\xca\x01\n" +
 	"\vPlayerState\x12!\n" +
 	"\x03pos\x18\x01 \x01(\v2\x0f.proto.PositionR\x03pos\x12\x16\n" +
 	"\x06health\x18\x02 \x01(\x02R\x06health\x12\x1b\n" +
-	"\tplayer_id\x18\x03 \x01(\rR\bplayerId\"_\n" +
+	"\tplayer_id\x18\x03 \x01(\rR\bplayerId\x12\x10\n" +
+	"\x03aim\x18\x04 \x01(\x02R\x03aim\x12'\n" +
+	"\aeffects\x18\x05 \x03(\v2\r.proto.EffectR\aeffects\x12(\n" +
+	"\x10dash_cooldown_ms\x18\x06 \x01(\rR\x0edashCooldownMs\"\x8a\x01\n" +
 	"\vBulletState\x12!\n" +
 	"\x03pos\x18\x01 \x01(\v2\x0f.proto.PositionR\x03pos\x12\x12\n" +
 	"\x04size\x18\x02 \x01(\x02R\x04size\x12\x19\n" +
-	"\bowner_id\x18\x03 \x01(\rR\aownerId\"\x83\x01\n" +
+	"\bowner_id\x18\x03 \x01(\rR\aownerId\x12)\n" +
+	"\x06weapon\x18\x04 \x01(\x0e2\x11.proto.WeaponTypeR\x06weapon\"t\n" +
+	"\vPickupState\x12\x1b\n" +
+	"\tpickup_id\x18\x01 \x01(\rR\bpickupId\x12!\n" +
+	"\x03pos\x18\x02 \x01(\v2\x0f.proto.PositionR\x03pos\x12%\n" +
+	"\x04kind\x18\x03 \x01(\x0e2\x11.proto.PickupKindR\x04kind\"\xde\x01\n" +
 	"\n" +
 	"WorldState\x12\x19\n" +
 	"\btick_num\x18\x01 \x01(\rR\atickNum\x12,\n" +
 	"\aplayers\x18\x02 \x03(\v2\x12.proto.PlayerStateR\aplayers\x12,\n" +
-	"\abullets\x18\x03 \x03(\v2\x12.proto.BulletStateR\abullets\"-\n" +
+	"\abullets\x18\x03 \x03(\v2\x12.proto.BulletStateR\abullets\x12,\n" +
+	"\apickups\x18\x04 \x03(\v2\x12.proto.PickupStateR\apickups\x12+\n" +
+	"\x11gameplay_checksum\x18\x05 \x01(\rR\x10gameplayChecksum\"-\n" +
 	"\x0eConnectRequest\x12\x1b\n" +
-	"\tgame_name\x18\x01 \x01(\tR\bgameName\")\n" +
+	"\tgame_name\x18\x01 \x01(\tR\bgameName\"\xb2\x05\n" +
+	"\x0eGameplayConfig\x12\x1f\n" +
+	"\vworld_width\x18\x01 \x01(\x02R\n" +
+	"worldWidth\x12!\n" +
+	"\fworld_height\x18\x02 \x01(\x02R\vworldHeight\x12!\n" +
+	"\fplayer_speed\x18\x03 \x01(\x02R\vplayerSpeed\x12\x1d\n" +
+	"\n" +
+	"dash_speed\x18\x04 \x01(\x02R\tdashSpeed\x12(\n" +
+	"\x10dash_duration_ms\x18\x05 \x01(\x05R\x0edashDurationMs\x120\n" +
+	"\x14dash_invulnerable_ms\x18\x06 \x01(\x05R\x12dashInvulnerableMs\x12(\n" +
+	"\x10dash_cooldown_ms\x18\a \x01(\x05R\x0edashCooldownMs\x12\x1d\n" +
+	"\n" +
+	"body_block\x18\b \x01(\bR\tbodyBlock\x12!\n" +
+	"\fbullet_speed\x18\t \x01(\x02R\vbulletSpeed\x124\n" +
+	"\x17bullet_time_to_live_sec\x18\n" +
+	" \x01(\x02R\x13bulletTimeToLiveSec\x12,\n" +
+	"\x12bullet_cooldown_ms\x18\v \x01(\x05R\x10bulletCooldownMs\x122\n" +
+	"\x15initial_player_health\x18\f \x01(\x02R\x13initialPlayerHealth\x12.\n" +
+	"\x13initial_player_size\x18\r \x01(\x02R\x11initialPlayerSize\x12,\n" +
+	"\x12player_shrink_step\x18\x0e \x01(\x02R\x10playerShrinkStep\x12.\n" +
+	"\x13initial_bullet_size\x18\x0f \x01(\x02R\x11initialBulletSize\x12,\n" +
+	"\x12bullet_shrink_step\x18\x10 \x01(\x02R\x10bulletShrinkStep\"\\\n" +
 	"\n" +
 	"ConnectAck\x12\x1b\n" +
-	"\tplayer_id\x18\x01 \x01(\rR\bplayerId\"(\n" +
+	"\tplayer_id\x18\x01 \x01(\rR\bplayerId\x121\n" +
+	"\bgameplay\x18\x02 \x01(\v2\x15.proto.GameplayConfigR\bgameplay\"H\n" +
 	"\tDeathNote\x12\x1b\n" +
-	"\tplayer_id\x18\x01 \x01(\rR\bplayerId\"6\n" +
+	"\tplayer_id\x18\x01 \x01(\rR\bplayerId\x12\x1e\n" +
+	"\n" +
+	"spectating\x18\x02 \x01(\bR\n" +
+	"spectating\"6\n" +
 	"\x10ReconnectRequest\x12\"\n" +
-	"\rold_player_id\x18\x01 \x01(\rR\voldPlayerId\"\xef\x02\n" +
+	"\rold_player_id\x18\x01 \x01(\rR\voldPlayerId\".\n" +
+	"\x0fSpectateRequest\x12\x1b\n" +
+	"\tgame_name\x18\x01 \x01(\tR\bgameName\"@\n" +
+	"\vSpectateAck\x121\n" +
+	"\bgameplay\x18\x01 \x01(\v2\x15.proto.GameplayConfigR\bgameplay\"'\n" +
+	"\rConnectReject\x12\x16\n" +
+	"\x06reason\x18\x01 \x01(\tR\x06reason\"5\n" +
+	"\n" +
+	"ViewUpdate\x12'\n" +
+	"\x06center\x18\x01 \x01(\v2\x0f.proto.PositionR\x06center\"#\n" +
+	"\rServerMessage\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\"C\n" +
+	"\x0eGameplayUpdate\x121\n" +
+	"\bgameplay\x18\x01 \x01(\v2\x15.proto.GameplayConfigR\bgameplay\"\x11\n" +
+	"\x0fGameplayRequest\"O\n" +
+	"\x0eServerShutdown\x12\x16\n" +
+	"\x06reason\x18\x01 \x01(\tR\x06reason\x12%\n" +
+	"\x0ereconnect_hint\x18\x02 \x01(\tR\rreconnectHint\">\n" +
+	"\x04Ping\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\rR\x03seq\x12$\n" +
+	"\x0esent_unix_nano\x18\x02 \x01(\x03R\fsentUnixNano\">\n" +
+	"\x04Pong\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\rR\x03seq\x12$\n" +
+	"\x0esent_unix_nano\x18\x02 \x01(\x03R\fsentUnixNano\"j\n" +
+	"\vPlayerEvent\x12*\n" +
+	"\x04kind\x18\x01 \x01(\x0e2\x16.proto.PlayerEventKindR\x04kind\x12\x1b\n" +
+	"\tplayer_id\x18\x02 \x01(\rR\bplayerId\x12\x12\n" +
+	"\x04addr\x18\x03 \x01(\tR\x04addr\"\xe3\x02\n" +
+	"\fReplayHeader\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\rR\aversion\x12(\n" +
+	"\x10ticks_per_second\x18\x02 \x01(\rR\x0eticksPerSecond\x12\x1f\n" +
+	"\vworld_width\x18\x03 \x01(\x02R\n" +
+	"worldWidth\x12!\n" +
+	"\fworld_height\x18\x04 \x01(\x02R\vworldHeight\x12*\n" +
+	"\x11started_unix_nano\x18\x05 \x01(\x03R\x0fstartedUnixNano\x126\n" +
+	"\rinitial_world\x18\x06 \x01(\v2\x11.proto.WorldStateR\finitialWorld\x124\n" +
+	"\rpickup_spawns\x18\a \x03(\v2\x0f.proto.PositionR\fpickupSpawns\x121\n" +
+	"\bgameplay\x18\b \x01(\v2\x15.proto.GameplayConfigR\bgameplay\"f\n" +
+	"\x0eGameplayChange\x12!\n" +
+	"\fafter_events\x18\x01 \x01(\rR\vafterEvents\x121\n" +
+	"\bgameplay\x18\x02 \x01(\v2\x15.proto.GameplayConfigR\bgameplay\"\xee\x01\n" +
+	"\n" +
+	"ReplayTick\x12\x19\n" +
+	"\btick_num\x18\x01 \x01(\rR\atickNum\x12*\n" +
+	"\x06events\x18\x02 \x03(\v2\x12.proto.PlayerEventR\x06events\x12*\n" +
+	"\x06inputs\x18\x03 \x03(\v2\x12.proto.PlayerInputR\x06inputs\x12%\n" +
+	"\x0eworld_checksum\x18\x04 \x01(\x04R\rworldChecksum\x12@\n" +
+	"\x10gameplay_changes\x18\x06 \x03(\v2\x15.proto.GameplayChangeR\x0fgameplayChangesJ\x04\b\x05\x10\x06\"n\n" +
+	"\vReplayFrame\x12-\n" +
+	"\x06header\x18\x01 \x01(\v2\x13.proto.ReplayHeaderH\x00R\x06header\x12'\n" +
+	"\x04tick\x18\x02 \x01(\v2\x11.proto.ReplayTickH\x00R\x04tickB\a\n" +
+	"\x05frame\"\xb0\a\n" +
 	"\vGameMessage\x12)\n" +
 	"\x05world\x18\x01 \x01(\v2\x11.proto.WorldStateH\x00R\x05world\x127\n" +
 	"\fplayer_input\x18\x02 \x01(\v2\x12.proto.PlayerInputH\x00R\vplayerInput\x12@\n" +
@@ -904,14 +2643,46 @@ const file_core_network_protobuf_proto_src_game_proto_rawDesc = "" +
 	"\vconnect_ack\x18\x05 \x01(\v2\x11.proto.ConnectAckH\x00R\n" +
 	"connectAck\x121\n" +
 	"\n" +
-	"death_note\x18\x06 \x01(\v2\x10.proto.DeathNoteH\x00R\tdeathNoteB\t\n" +
+	"death_note\x18\x06 \x01(\v2\x10.proto.DeathNoteH\x00R\tdeathNote\x12!\n" +
+	"\x04ping\x18\a \x01(\v2\v.proto.PingH\x00R\x04ping\x12!\n" +
+	"\x04pong\x18\b \x01(\v2\v.proto.PongH\x00R\x04pong\x12C\n" +
+	"\x10spectate_request\x18\t \x01(\v2\x16.proto.SpectateRequestH\x00R\x0fspectateRequest\x127\n" +
+	"\fspectate_ack\x18\n" +
+	" \x01(\v2\x12.proto.SpectateAckH\x00R\vspectateAck\x12=\n" +
+	"\x0econnect_reject\x18\v \x01(\v2\x14.proto.ConnectRejectH\x00R\rconnectReject\x124\n" +
+	"\vview_update\x18\f \x01(\v2\x11.proto.ViewUpdateH\x00R\n" +
+	"viewUpdate\x12=\n" +
+	"\x0eserver_message\x18\r \x01(\v2\x14.proto.ServerMessageH\x00R\rserverMessage\x12@\n" +
+	"\x0fserver_shutdown\x18\x0e \x01(\v2\x15.proto.ServerShutdownH\x00R\x0eserverShutdown\x12@\n" +
+	"\x0fgameplay_update\x18\x0f \x01(\v2\x15.proto.GameplayUpdateH\x00R\x0egameplayUpdate\x12C\n" +
+	"\x10gameplay_request\x18\x10 \x01(\v2\x16.proto.GameplayRequestH\x00R\x0fgameplayRequestB\t\n" +
 	"\apayload*<\n" +
 	"\tDirection\x12\b\n" +
 	"\x04NONE\x10\x00\x12\b\n" +
 	"\x04LEFT\x10\x01\x12\t\n" +
 	"\x05RIGHT\x10\x02\x12\x06\n" +
 	"\x02UP\x10\x03\x12\b\n" +
-	"\x04DOWN\x10\x04B\x18Z\x16core/network/protobuf/b\x06proto3"
+	"\x04DOWN\x10\x04*V\n" +
+	"\n" +
+	"WeaponType\x12\x11\n" +
+	"\rWEAPON_PISTOL\x10\x00\x12\x12\n" +
+	"\x0eWEAPON_SHOTGUN\x10\x01\x12\x11\n" +
+	"\rWEAPON_SNIPER\x10\x02\x12\x0e\n" +
+	"\n" +
+	"WEAPON_SMG\x10\x03*\x85\x01\n" +
+	"\n" +
+	"PickupKind\x12\x11\n" +
+	"\rPICKUP_HEALTH\x10\x00\x12\x10\n" +
+	"\fPICKUP_SPEED\x10\x01\x12\x15\n" +
+	"\x11PICKUP_RAPID_FIRE\x10\x02\x12\x11\n" +
+	"\rPICKUP_SHIELD\x10\x03\x12\x16\n" +
+	"\x12PICKUP_BIG_BULLETS\x10\x04\x12\x10\n" +
+	"\fPICKUP_ARMOR\x10\x05*{\n" +
+	"\x0fPlayerEventKind\x12\x15\n" +
+	"\x11PLAYER_EVENT_NONE\x10\x00\x12\x18\n" +
+	"\x14PLAYER_EVENT_CONNECT\x10\x01\x12\x1a\n" +
+	"\x16PLAYER_EVENT_RECONNECT\x10\x02\x12\x1b\n" +
+	"\x17PLAYER_EVENT_DISCONNECT\x10\x03B\x18Z\x16core/network/protobuf/b\x06proto3"
 
 var (
 	file_core_network_protobuf_proto_src_game_proto_rawDescOnce sync.Once
@@ -925,45 +2696,101 @@ func file_core_network_protobuf_proto_src_game_proto_rawDescGZIP() []byte {
 	return file_core_network_protobuf_proto_src_game_proto_rawDescData
 }
 
-var file_core_network_protobuf_proto_src_game_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_core_network_protobuf_proto_src_game_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_core_network_protobuf_proto_src_game_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_core_network_protobuf_proto_src_game_proto_msgTypes = make([]protoimpl.MessageInfo, 33)
 var file_core_network_protobuf_proto_src_game_proto_goTypes = []any{
 	(Direction)(0),           // 0: proto.Direction
-	(*MoveAction)(nil),       // 1: proto.MoveAction
-	(*ShootAction)(nil),      // 2: proto.ShootAction
-	(*PlayerAction)(nil),     // 3: proto.PlayerAction
-	(*PlayerInput)(nil),      // 4: proto.PlayerInput
-	(*Position)(nil),         // 5: proto.Position
-	(*PlayerState)(nil),      // 6: proto.PlayerState
-	(*BulletState)(nil),      // 7: proto.BulletState
-	(*WorldState)(nil),       // 8: proto.WorldState
-	(*ConnectRequest)(nil),   // 9: proto.ConnectRequest
-	(*ConnectAck)(nil),       // 10: proto.ConnectAck
-	(*DeathNote)(nil),        // 11: proto.DeathNote
-	(*ReconnectRequest)(nil), // 12: proto.ReconnectRequest
-	(*GameMessage)(nil),      // 13: proto.GameMessage
+	(WeaponType)(0),          // 1: proto.WeaponType
+	(PickupKind)(0),          // 2: proto.PickupKind
+	(PlayerEventKind)(0),     // 3: proto.PlayerEventKind
+	(*MoveAction)(nil),       // 4: proto.MoveAction
+	(*ShootAction)(nil),      // 5: proto.ShootAction
+	(*AnalogAction)(nil),     // 6: proto.AnalogAction
+	(*DashAction)(nil),       // 7: proto.DashAction
+	(*PlayerAction)(nil),     // 8: proto.PlayerAction
+	(*PlayerInput)(nil),      // 9: proto.PlayerInput
+	(*Position)(nil),         // 10: proto.Position
+	(*Effect)(nil),           // 11: proto.Effect
+	(*PlayerState)(nil),      // 12: proto.PlayerState
+	(*BulletState)(nil),      // 13: proto.BulletState
+	(*PickupState)(nil),      // 14: proto.PickupState
+	(*WorldState)(nil),       // 15: proto.WorldState
+	(*ConnectRequest)(nil),   // 16: proto.ConnectRequest
+	(*GameplayConfig)(nil),   // 17: proto.GameplayConfig
+	(*ConnectAck)(nil),       // 18: proto.ConnectAck
+	(*DeathNote)(nil),        // 19: proto.DeathNote
+	(*ReconnectRequest)(nil), // 20: proto.ReconnectRequest
+	(*SpectateRequest)(nil),  // 21: proto.SpectateRequest
+	(*SpectateAck)(nil),      // 22: proto.SpectateAck
+	(*ConnectReject)(nil),    // 23: proto.ConnectReject
+	(*ViewUpdate)(nil),       // 24: proto.ViewUpdate
+	(*ServerMessage)(nil),    // 25: proto.ServerMessage
+	(*GameplayUpdate)(nil),   // 26: proto.GameplayUpdate
+	(*GameplayRequest)(nil),  // 27: proto.GameplayRequest
+	(*ServerShutdown)(nil),   // 28: proto.ServerShutdown
+	(*Ping)(nil),             // 29: proto.Ping
+	(*Pong)(nil),             // 30: proto.Pong
+	(*PlayerEvent)(nil),      // 31: proto.PlayerEvent
+	(*ReplayHeader)(nil),     // 32: proto.ReplayHeader
+	(*GameplayChange)(nil),   // 33: proto.GameplayChange
+	(*ReplayTick)(nil),       // 34: proto.ReplayTick
+	(*ReplayFrame)(nil),      // 35: proto.ReplayFrame
+	(*GameMessage)(nil),      // 36: proto.GameMessage
 }
 var file_core_network_protobuf_proto_src_game_proto_depIdxs = []int32{
 	0,  // 0: proto.MoveAction.dir:type_name -> proto.Direction
-	5,  // 1: proto.ShootAction.target:type_name -> proto.Position
-	1,  // 2: proto.PlayerAction.move:type_name -> proto.MoveAction
-	2,  // 3: proto.PlayerAction.shoot:type_name -> proto.ShootAction
-	3,  // 4: proto.PlayerInput.player_actions:type_name -> proto.PlayerAction
-	5,  // 5: proto.PlayerState.pos:type_name -> proto.Position
-	5,  // 6: proto.BulletState.pos:type_name -> proto.Position
-	6,  // 7: proto.WorldState.players:type_name -> proto.PlayerState
-	7,  // 8: proto.WorldState.bullets:type_name -> proto.BulletState
-	8,  // 9: proto.GameMessage.world:type_name -> proto.WorldState
-	4,  // 10: proto.GameMessage.player_input:type_name -> proto.PlayerInput
-	9,  // 11: proto.GameMessage.connect_request:type_name -> proto.ConnectRequest
-	12, // 12: proto.GameMessage.reconnect_request:type_name -> proto.ReconnectRequest
-	10, // 13: proto.GameMessage.connect_ack:type_name -> proto.ConnectAck
-	11, // 14: proto.GameMessage.death_note:type_name -> proto.DeathNote
-	15, // [15:15] is the sub-list for method output_type
-	15, // [15:15] is the sub-list for method input_type
-	15, // [15:15] is the sub-list for extension type_name
-	15, // [15:15] is the sub-list for extension extendee
-	0,  // [0:15] is the sub-list for field type_name
+	10, // 1: proto.ShootAction.target:type_name -> proto.Position
+	10, // 2: proto.AnalogAction.move:type_name -> proto.Position
+	4,  // 3: proto.PlayerAction.move:type_name -> proto.MoveAction
+	5,  // 4: proto.PlayerAction.shoot:type_name -> proto.ShootAction
+	6,  // 5: proto.PlayerAction.analog:type_name -> proto.AnalogAction
+	7,  // 6: proto.PlayerAction.dash:type_name -> proto.DashAction
+	8,  // 7: proto.PlayerInput.player_actions:type_name -> proto.PlayerAction
+	2,  // 8: proto.Effect.kind:type_name -> proto.PickupKind
+	10, // 9: proto.PlayerState.pos:type_name -> proto.Position
+	11, // 10: proto.PlayerState.effects:type_name -> proto.Effect
+	10, // 11: proto.BulletState.pos:type_name -> proto.Position
+	1,  // 12: proto.BulletState.weapon:type_name -> proto.WeaponType
+	10, // 13: proto.PickupState.pos:type_name -> proto.Position
+	2,  // 14: proto.PickupState.kind:type_name -> proto.PickupKind
+	12, // 15: proto.WorldState.players:type_name -> proto.PlayerState
+	13, // 16: proto.WorldState.bullets:type_name -> proto.BulletState
+	14, // 17: proto.WorldState.pickups:type_name -> proto.PickupState
+	17, // 18: proto.ConnectAck.gameplay:type_name -> proto.GameplayConfig
+	17, // 19: proto.SpectateAck.gameplay:type_name -> proto.GameplayConfig
+	10, // 20: proto.ViewUpdate.center:type_name -> proto.Position
+	17, // 21: proto.GameplayUpdate.gameplay:type_name -> proto.GameplayConfig
+	3,  // 22: proto.PlayerEvent.kind:type_name -> proto.PlayerEventKind
+	15, // 23: proto.ReplayHeader.initial_world:type_name -> proto.WorldState
+	10, // 24: proto.ReplayHeader.pickup_spawns:type_name -> proto.Position
+	17, // 25: proto.ReplayHeader.gameplay:type_name -> proto.GameplayConfig
+	17, // 26: proto.GameplayChange.gameplay:type_name -> proto.GameplayConfig
+	31, // 27: proto.ReplayTick.events:type_name -> proto.PlayerEvent
+	9,  // 28: proto.ReplayTick.inputs:type_name -> proto.PlayerInput
+	33, // 29: proto.ReplayTick.gameplay_changes:type_name -> proto.GameplayChange
+	32, // 30: proto.ReplayFrame.header:type_name -> proto.ReplayHeader
+	34, // 31: proto.ReplayFrame.tick:type_name -> proto.ReplayTick
+	15, // 32: proto.GameMessage.world:type_name -> proto.WorldState
+	9,  // 33: proto.GameMessage.player_input:type_name -> proto.PlayerInput
+	16, // 34: proto.GameMessage.connect_request:type_name -> proto.ConnectRequest
+	20, // 35: proto.GameMessage.reconnect_request:type_name -> proto.ReconnectRequest
+	18, // 36: proto.GameMessage.connect_ack:type_name -> proto.ConnectAck
+	19, // 37: proto.GameMessage.death_note:type_name -> proto.DeathNote
+	29, // 38: proto.GameMessage.ping:type_name -> proto.Ping
+	30, // 39: proto.GameMessage.pong:type_name -> proto.Pong
+	21, // 40: proto.GameMessage.spectate_request:type_name -> proto.SpectateRequest
+	22, // 41: proto.GameMessage.spectate_ack:type_name -> proto.SpectateAck
+	23, // 42: proto.GameMessage.connect_reject:type_name -> proto.ConnectReject
+	24, // 43: proto.GameMessage.view_update:type_name -> proto.ViewUpdate
+	25, // 44: proto.GameMessage.server_message:type_name -> proto.ServerMessage
+	28, // 45: proto.GameMessage.server_shutdown:type_name -> proto.ServerShutdown
+	26, // 46: proto.GameMessage.gameplay_update:type_name -> proto.GameplayUpdate
+	27, // 47: proto.GameMessage.gameplay_request:type_name -> proto.GameplayRequest
+	48, // [48:48] is the sub-list for method output_type
+	48, // [48:48] is the sub-list for method input_type
+	48, // [48:48] is the sub-list for extension type_name
+	48, // [48:48] is the sub-list for extension extendee
+	0,  // [0:48] is the sub-list for field type_name
 }
 
 func init() { file_core_network_protobuf_proto_src_game_proto_init() }
@@ -971,25 +2798,41 @@ func file_core_network_protobuf_proto_src_game_proto_init() {
 	if File_core_network_protobuf_proto_src_game_proto != nil {
 		return
 	}
-	file_core_network_protobuf_proto_src_game_proto_msgTypes[2].OneofWrappers = []any{
+	file_core_network_protobuf_proto_src_game_proto_msgTypes[4].OneofWrappers = []any{
 		(*PlayerAction_Move)(nil),
 		(*PlayerAction_Shoot)(nil),
+		(*PlayerAction_Analog)(nil),
+		(*PlayerAction_Dash)(nil),
+	}
+	file_core_network_protobuf_proto_src_game_proto_msgTypes[31].OneofWrappers = []any{
+		(*ReplayFrame_Header)(nil),
+		(*ReplayFrame_Tick)(nil),
 	}
-	file_core_network_protobuf_proto_src_game_proto_msgTypes[12].OneofWrappers = []any{
+	file_core_network_protobuf_proto_src_game_proto_msgTypes[32].OneofWrappers = []any{
 		(*GameMessage_World)(nil),
 		(*GameMessage_PlayerInput)(nil),
 		(*GameMessage_ConnectRequest)(nil),
 		(*GameMessage_ReconnectRequest)(nil),
 		(*GameMessage_ConnectAck)(nil),
 		(*GameMessage_DeathNote)(nil),
+		(*GameMessage_Ping)(nil),
+		(*GameMessage_Pong)(nil),
+		(*GameMessage_SpectateRequest)(nil),
+		(*GameMessage_SpectateAck)(nil),
+		(*GameMessage_ConnectReject)(nil),
+		(*GameMessage_ViewUpdate)(nil),
+		(*GameMessage_ServerMessage)(nil),
+		(*GameMessage_ServerShutdown)(nil),
+		(*GameMessage_GameplayUpdate)(nil),
+		(*GameMessage_GameplayRequest)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_core_network_protobuf_proto_src_game_proto_rawDesc), len(file_core_network_protobuf_proto_src_game_proto_rawDesc)),
-			NumEnums:      1,
-			NumMessages:   13,
+			NumEnums:      4,
+			NumMessages:   33,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -0,0 +1,100 @@
+package pickups
+
+import (
+	"hash/fnv"
+	"time"
+)
+
+// same values as the PickupKind enum of the protocol
+type Kind int32
+
+const (
+	Health Kind = iota
+	SpeedBoost
+	RapidFire
+	Shield
+	BigBullets
+	Armor
+)
+
+// players keep an effect slot for each kind
+const NumKinds = Armor + 1
+
+// every kind, the order the spawner picks from
+var Kinds = []Kind{Health, SpeedBoost, RapidFire, Shield, BigBullets, Armor}
+
+// what picking up a kind again does to an effect that's still running
+type Rule int
+
+const (
+	// no effect, it happens once when picked up
+	Instant Rule = iota
+	// back to the full duration
+	Refresh
+	// the duration is added on top, up to MaxDuration
+	Stack
+)
+
+type Def struct {
+	Kind        Kind
+	Name        string
+	Rule        Rule
+	Duration    time.Duration
+	MaxDuration time.Duration
+}
+
+// indexed by Kind
+var defs = []Def{
+	Health:     {Kind: Health, Name: "health", Rule: Instant},
+	SpeedBoost: {Kind: SpeedBoost, Name: "speed", Rule: Refresh, Duration: 5 * time.Second},
+	RapidFire:  {Kind: RapidFire, Name: "rapid fire", Rule: Stack, Duration: 6 * time.Second, MaxDuration: 12 * time.Second},
+	Shield:     {Kind: Shield, Name: "shield", Rule: Refresh, Duration: 4 * time.Second},
+	BigBullets: {Kind: BigBullets, Name: "big bullets", Rule: Stack, Duration: 6 * time.Second, MaxDuration: 12 * time.Second},
+	Armor:      {Kind: Armor, Name: "armor", Rule: Refresh, Duration: 8 * time.Second},
+}
+
+const (
+	Radius = 18
+	// a new pickup spawns this often while there are less than MaxOnGround
+	SpawnInterval = 8 * time.Second
+	MaxOnGround   = 4
+	// health a health pickup gives back, never past the initial health
+	HealthRestore = 5
+	// while the effects run players move faster, cool down quicker and
+	// shoot bigger bullets
+	SpeedFactor      = 1.5
+	CooldownFactor   = 0.5
+	BulletSizeFactor = 1.5
+	// damage armor takes off every hit
+	ArmorReduction = 2
+)
+
+func Get(k Kind) Def {
+	if k < 0 || int(k) >= len(defs) {
+		return Def{Kind: k, Name: "unknown", Rule: Instant}
+	}
+	return defs[k]
+}
+
+// how long the effect runs after being picked up with remaining left of it
+func (d Def) Extend(remaining time.Duration) time.Duration {
+	switch d.Rule {
+	case Refresh:
+		return d.Duration
+	case Stack:
+		return min(max(0, remaining)+d.Duration, d.MaxDuration)
+	}
+	return 0
+}
+
+// number in [0, 1) derived from seed, the spawner rolls with it so the
+// simulation stays deterministic
+func Roll(seed uint64) float32 {
+	h := fnv.New64a()
+	var buf [8]byte
+	for i := range buf {
+		buf[i] = byte(seed >> (8 * i))
+	}
+	h.Write(buf[:])
+	return float32(h.Sum64()%1_000_000) / 1_000_000
+}
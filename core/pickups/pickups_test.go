@@ -0,0 +1,43 @@
+package pickups
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExtend(t *testing.T) {
+	tests := []struct {
+		name      string
+		kind      Kind
+		remaining time.Duration
+		want      time.Duration
+	}{
+		{"instant", Health, time.Second, 0},
+		{"refresh from nothing", SpeedBoost, 0, 5 * time.Second},
+		{"refresh resets", SpeedBoost, 2 * time.Second, 5 * time.Second},
+		{"stack from nothing", RapidFire, 0, 6 * time.Second},
+		{"stack adds", RapidFire, 3 * time.Second, 9 * time.Second},
+		{"stack is capped", BigBullets, 10 * time.Second, 12 * time.Second},
+		{"expired stacks from nothing", BigBullets, -time.Second, 6 * time.Second},
+		{"unknown kind", Kind(99), time.Second, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Get(test.kind).Extend(test.remaining); got != test.want {
+				t.Errorf("got %s want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRoll(t *testing.T) {
+	for seed := range uint64(200) {
+		got := Roll(seed)
+		if got < 0 || got >= 1 {
+			t.Fatalf("seed %d rolled %f", seed, got)
+		}
+		if got != Roll(seed) {
+			t.Fatalf("seed %d rolls differently", seed)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package replay
+
+import (
+	"CircleWar/config"
+	"CircleWar/core/netmsg"
+	"bufio"
+	"os"
+	"sort"
+)
+
+// ticks waiting to be written before RecordTick starts blocking
+const recordQueueSize = 1024
+
+// writes replay frames on its own goroutine so the tick loop never waits
+// on the disk. a nil Recorder records nothing.
+type Recorder struct {
+	file    *os.File
+	queue   chan Tick
+	done    chan error
+	pending []PlayerEvent
+	// gameplay changes since the last tick, in order with pending
+	gameplay []GameplayChange
+}
+
+func NewRecorder(path string, header Header) (*Recorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	header.Version = FormatVersion
+	w := bufio.NewWriter(file)
+	if _, err := w.Write(magic); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := writeFrame(w, header.toProtobuf()); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	rec := &Recorder{
+		file:  file,
+		queue: make(chan Tick, recordQueueSize),
+		done:  make(chan error, 1),
+	}
+	go rec.writeLoop(w)
+	return rec, nil
+}
+
+func (rec *Recorder) writeLoop(w *bufio.Writer) {
+	var err error
+	for tick := range rec.queue {
+		if err != nil {
+			continue // keep draining so RecordTick never blocks forever
+		}
+		err = writeFrame(w, tick.toProtobuf())
+		// flush once caught up, so a killed server loses at most a tick or so
+		if err == nil && len(rec.queue) == 0 {
+			err = w.Flush()
+		}
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	rec.done <- err
+}
+
+// attaches the event to the next recorded tick
+func (rec *Recorder) RecordEvent(ev PlayerEvent) {
+	if rec == nil {
+		return
+	}
+	rec.pending = append(rec.pending, ev)
+}
+
+// attaches the new gameplay to the next recorded tick, after the events
+// recorded so far
+func (rec *Recorder) RecordGameplay(gameplay config.Gameplay) {
+	if rec == nil {
+		return
+	}
+	rec.gameplay = append(rec.gameplay, GameplayChange{len(rec.pending), gameplay})
+}
+
+// records the inputs applied on tickNum together with any pending events,
+// checksum is the Checksum of the world the tick resulted in
+func (rec *Recorder) RecordTick(tickNum uint32, inputs map[uint]netmsg.PlayerInput, checksum uint64) {
+	if rec == nil {
+		return
+	}
+
+	tick := Tick{TickNum: tickNum, Events: rec.pending, Checksum: checksum, GameplayChanges: rec.gameplay}
+	for _, input := range inputs {
+		tick.Inputs = append(tick.Inputs, &input)
+	}
+	sort.Slice(tick.Inputs, func(i, j int) bool {
+		return tick.Inputs[i].PlayerId < tick.Inputs[j].PlayerId
+	})
+	rec.pending = nil
+	rec.gameplay = nil
+
+	rec.queue <- tick
+}
+
+// writes out every queued tick and closes the file
+func (rec *Recorder) Close() error {
+	if rec == nil {
+		return nil
+	}
+	close(rec.queue)
+	err := <-rec.done
+	if closeErr := rec.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,267 @@
+package replay
+
+import (
+	"CircleWar/config"
+	"CircleWar/core/geom"
+	"CircleWar/core/netmsg"
+	pb "CircleWar/core/network/protobuf"
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// a replay file is the magic bytes followed by frames, each frame is a
+// uvarint length and a marshalled pb.ReplayFrame. the first frame is the header
+//
+// version 2 added world checksums and tick based simulation time,
+// version 3 moves entities by the fixed step instead of whole ticks,
+// version 4 adds analog input and the players' aim,
+// version 5 adds weapons,
+// version 6 adds pickups and the players' effects,
+// version 7 adds dashing,
+// version 8 pushes overlapping players apart,
+// version 9 adds damage falloff,
+// version 10 keeps the players' movement when they get hit,
+// version 11 records the gameplay config the match was played with,
+// version 12 records gameplay changes in the middle of the match,
+// version 13 gives the shotgun damage falloff,
+// version 14 adds the armor pickup,
+// version 15 orders gameplay changes among the events of a tick
+const FormatVersion = 15
+
+var magic = []byte("CWRP")
+
+// frames bigger than this are treated as a corrupt file
+const maxFrameSize = 1 << 20
+
+type EventKind int32
+
+const (
+	Connect    EventKind = EventKind(pb.PlayerEventKind_PLAYER_EVENT_CONNECT)
+	Reconnect  EventKind = EventKind(pb.PlayerEventKind_PLAYER_EVENT_RECONNECT)
+	Disconnect EventKind = EventKind(pb.PlayerEventKind_PLAYER_EVENT_DISCONNECT)
+)
+
+type PlayerEvent struct {
+	Kind     EventKind
+	PlayerId uint32
+	Addr     string
+}
+
+type Header struct {
+	Version        uint32
+	TicksPerSecond uint32
+	Width, Height  float32
+	Started        time.Time
+	InitialWorld   *netmsg.WorldState
+	PickupSpawns   []geom.Vector2
+	// the replay plays by these, not by the watcher's
+	Gameplay config.Gameplay
+}
+
+// events and inputs that were applied before the tick was simulated,
+// and the checksum of the world the tick resulted in
+type Tick struct {
+	TickNum  uint32
+	Events   []PlayerEvent
+	Inputs   []*netmsg.PlayerInput
+	Checksum uint64
+	// gameplay changes before the tick, in the order they happened
+	GameplayChanges []GameplayChange
+}
+
+// the gameplay the match played by once the first AfterEvents events of
+// the tick were applied
+type GameplayChange struct {
+	AfterEvents int
+	Gameplay    config.Gameplay
+}
+
+func (h *Header) toProtobuf() *pb.ReplayFrame {
+	pbHeader := &pb.ReplayHeader{
+		Version:         h.Version,
+		TicksPerSecond:  h.TicksPerSecond,
+		WorldWidth:      h.Width,
+		WorldHeight:     h.Height,
+		StartedUnixNano: h.Started.UnixNano(),
+		InitialWorld:    h.InitialWorld.ToProtobuf().GetWorld(),
+		Gameplay:        netmsg.GameplayToProtobuf(h.Gameplay),
+	}
+	for _, spawn := range h.PickupSpawns {
+		pbHeader.PickupSpawns = append(pbHeader.PickupSpawns, &pb.Position{X: spawn.X, Y: spawn.Y})
+	}
+	return &pb.ReplayFrame{Frame: &pb.ReplayFrame_Header{Header: pbHeader}}
+}
+
+func headerFromProtobuf(pbHeader *pb.ReplayHeader) Header {
+	header := Header{
+		Version:        pbHeader.Version,
+		TicksPerSecond: pbHeader.TicksPerSecond,
+		Width:          pbHeader.WorldWidth,
+		Height:         pbHeader.WorldHeight,
+		Started:        time.Unix(0, pbHeader.StartedUnixNano),
+		InitialWorld:   netmsg.WorldStateFromProtobuf(pbHeader.InitialWorld),
+		Gameplay:       netmsg.GameplayFromProtobuf(pbHeader.Gameplay),
+	}
+	for _, spawn := range pbHeader.PickupSpawns {
+		header.PickupSpawns = append(header.PickupSpawns, geom.NewVector(spawn.X, spawn.Y))
+	}
+	return header
+}
+
+func (t *Tick) toProtobuf() *pb.ReplayFrame {
+	pbTick := &pb.ReplayTick{TickNum: t.TickNum, WorldChecksum: t.Checksum}
+	for _, change := range t.GameplayChanges {
+		pbTick.GameplayChanges = append(pbTick.GameplayChanges, &pb.GameplayChange{
+			AfterEvents: uint32(change.AfterEvents),
+			Gameplay:    netmsg.GameplayToProtobuf(change.Gameplay),
+		})
+	}
+	for _, ev := range t.Events {
+		pbTick.Events = append(pbTick.Events, &pb.PlayerEvent{
+			Kind:     pb.PlayerEventKind(ev.Kind),
+			PlayerId: ev.PlayerId,
+			Addr:     ev.Addr,
+		})
+	}
+	for _, input := range t.Inputs {
+		pbTick.Inputs = append(pbTick.Inputs, input.ToProtobuf().GetPlayerInput())
+	}
+	return &pb.ReplayFrame{Frame: &pb.ReplayFrame_Tick{Tick: pbTick}}
+}
+
+func tickFromProtobuf(pbTick *pb.ReplayTick) Tick {
+	tick := Tick{TickNum: pbTick.TickNum, Checksum: pbTick.WorldChecksum}
+	for _, change := range pbTick.GameplayChanges {
+		tick.GameplayChanges = append(tick.GameplayChanges, GameplayChange{
+			int(change.AfterEvents), netmsg.GameplayFromProtobuf(change.Gameplay),
+		})
+	}
+	for _, ev := range pbTick.Events {
+		tick.Events = append(tick.Events, PlayerEvent{EventKind(ev.Kind), ev.PlayerId, ev.Addr})
+	}
+	for _, input := range pbTick.Inputs {
+		tick.Inputs = append(tick.Inputs, netmsg.PlayerInputFromProtobuf(input))
+	}
+	return tick
+}
+
+// FNV-1a over every field of the world, in the order they are stored
+func Checksum(ws *netmsg.WorldState) uint64 {
+	h := fnv.New64a()
+	buf := binary.LittleEndian.AppendUint32(nil, ws.TickNum)
+	for _, player := range ws.Players {
+		buf = binary.LittleEndian.AppendUint32(buf, player.Id)
+		buf = appendFloats(buf, player.Pos.X, player.Pos.Y, player.Health, player.Aim)
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(player.DashCooldown))
+		for _, effect := range player.Effects {
+			buf = binary.LittleEndian.AppendUint32(buf, uint32(effect.Kind))
+			buf = binary.LittleEndian.AppendUint64(buf, uint64(effect.Remaining))
+		}
+	}
+	for _, bullet := range ws.Bullets {
+		buf = binary.LittleEndian.AppendUint32(buf, bullet.OwnerId)
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(bullet.Weapon))
+		buf = appendFloats(buf, bullet.Pos.X, bullet.Pos.Y, bullet.Size)
+	}
+	for _, pickup := range ws.Pickups {
+		buf = binary.LittleEndian.AppendUint32(buf, pickup.Id)
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(pickup.Kind))
+		buf = appendFloats(buf, pickup.Pos.X, pickup.Pos.Y)
+	}
+	h.Write(buf)
+	return h.Sum64()
+}
+
+func appendFloats(buf []byte, floats ...float32) []byte {
+	for _, f := range floats {
+		buf = binary.LittleEndian.AppendUint32(buf, math.Float32bits(f))
+	}
+	return buf
+}
+
+func writeFrame(w io.Writer, frame *pb.ReplayFrame) error {
+	data, err := proto.Marshal(frame)
+	if err != nil {
+		return err
+	}
+	prefix := binary.AppendUvarint(nil, uint64(len(data)))
+	if _, err := w.Write(prefix); err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+type Reader struct {
+	r      *bufio.Reader
+	Header Header
+}
+
+// reads and checks the magic bytes and header
+func NewReader(r io.Reader) (*Reader, error) {
+	rd := &Reader{r: bufio.NewReader(r)}
+
+	fileMagic := make([]byte, len(magic))
+	if _, err := io.ReadFull(rd.r, fileMagic); err != nil {
+		return nil, err
+	}
+	if string(fileMagic) != string(magic) {
+		return nil, errors.New("not a replay file")
+	}
+
+	frame, err := rd.readFrame()
+	if err != nil {
+		return nil, err
+	}
+	pbHeader := frame.GetHeader()
+	if pbHeader == nil {
+		return nil, errors.New("replay is missing its header")
+	}
+	if pbHeader.Version != FormatVersion {
+		return nil, fmt.Errorf("unsupported replay version %d", pbHeader.Version)
+	}
+	rd.Header = headerFromProtobuf(pbHeader)
+
+	return rd, nil
+}
+
+func (rd *Reader) readFrame() (*pb.ReplayFrame, error) {
+	size, err := binary.ReadUvarint(rd.r)
+	if err != nil {
+		return nil, err
+	}
+	if size > maxFrameSize {
+		return nil, fmt.Errorf("replay frame of %d bytes is too big", size)
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(rd.r, data); err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	frame := &pb.ReplayFrame{}
+	if err := proto.Unmarshal(data, frame); err != nil {
+		return nil, err
+	}
+	return frame, nil
+}
+
+// returns io.EOF after the last tick
+func (rd *Reader) Next() (Tick, error) {
+	frame, err := rd.readFrame()
+	if err != nil {
+		return Tick{}, err
+	}
+	pbTick := frame.GetTick()
+	if pbTick == nil {
+		return Tick{}, errors.New("expected a tick frame")
+	}
+	return tickFromProtobuf(pbTick), nil
+}
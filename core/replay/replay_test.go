@@ -0,0 +1,120 @@
+package replay
+
+import (
+	"CircleWar/config"
+	"CircleWar/core/geom"
+	"CircleWar/core/netmsg"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRecorderRoundTrip(t *testing.T) {
+	ticks := []struct {
+		name   string
+		events []PlayerEvent
+		inputs map[uint]netmsg.PlayerInput
+	}{
+		{"empty tick", nil, nil},
+		{"connect", []PlayerEvent{{Connect, 1, "127.0.0.1:5000"}}, nil},
+		{"inputs", nil, map[uint]netmsg.PlayerInput{
+			2: {PlayerId: 2, Actions: []netmsg.PlayerAction{&netmsg.ShootAction{Target: geom.NewVector(3, 4)}}},
+			1: {PlayerId: 1, Actions: []netmsg.PlayerAction{&netmsg.MoveAction{Dir: netmsg.LEFT}}},
+		}},
+		{"reconnect", []PlayerEvent{{Reconnect, 1, "127.0.0.1:5000"}}, nil},
+	}
+
+	gameplay := config.Default().Gameplay
+	gameplay.BulletSpeed = 1234
+	path := filepath.Join(t.TempDir(), "test.replay")
+	rec, err := NewRecorder(path, Header{
+		TicksPerSecond: 60,
+		Width:          100,
+		Height:         50,
+		Started:        time.Unix(10, 0),
+		InitialWorld:   &netmsg.WorldState{TickNum: 7},
+		PickupSpawns:   []geom.Vector2{geom.NewVector(10, 20)},
+		Gameplay:       gameplay,
+	})
+	if err != nil {
+		t.Fatalf("NewRecorder: %s", err)
+	}
+	// the gameplay changes before and after the connect
+	const changedTick = 1
+	changed := gameplay
+	changed.PlayerSpeed = 500
+	changedAgain := changed
+	changedAgain.PlayerSpeed = 600
+	wantChanges := []GameplayChange{{0, changed}, {1, changedAgain}}
+	for i, tick := range ticks {
+		if i == changedTick {
+			rec.RecordGameplay(changed)
+		}
+		for _, ev := range tick.events {
+			rec.RecordEvent(ev)
+		}
+		if i == changedTick {
+			rec.RecordGameplay(changedAgain)
+		}
+		rec.RecordTick(uint32(i), tick.inputs, uint64(i)*31)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	rd, err := NewReader(file)
+	if err != nil {
+		t.Fatalf("NewReader: %s", err)
+	}
+	if rd.Header.Version != FormatVersion || rd.Header.Width != 100 || rd.Header.InitialWorld.TickNum != 7 ||
+		len(rd.Header.PickupSpawns) != 1 || rd.Header.PickupSpawns[0] != geom.NewVector(10, 20) ||
+		rd.Header.Gameplay != gameplay {
+		t.Errorf("bad header %+v", rd.Header)
+	}
+
+	for i, want := range ticks {
+		t.Run(want.name, func(t *testing.T) {
+			got, err := rd.Next()
+			if err != nil {
+				t.Fatalf("Next: %s", err)
+			}
+			if got.TickNum != uint32(i) || got.Checksum != uint64(i)*31 {
+				t.Errorf("got tick %d checksum %d want %d", got.TickNum, got.Checksum, i)
+			}
+			if i == changedTick && !slices.Equal(got.GameplayChanges, wantChanges) || i != changedTick && len(got.GameplayChanges) > 0 {
+				t.Errorf("got gameplay changes %+v", got.GameplayChanges)
+			}
+			if len(got.Events) != len(want.events) {
+				t.Fatalf("got %d events want %d", len(got.Events), len(want.events))
+			}
+			for j := range got.Events {
+				if got.Events[j] != want.events[j] {
+					t.Errorf("got event %+v want %+v", got.Events[j], want.events[j])
+				}
+			}
+			if len(got.Inputs) != len(want.inputs) {
+				t.Fatalf("got %d inputs want %d", len(got.Inputs), len(want.inputs))
+			}
+			for j, input := range got.Inputs {
+				if j > 0 && got.Inputs[j-1].PlayerId > input.PlayerId {
+					t.Errorf("inputs not sorted by player id")
+				}
+				if len(input.Actions) != len(want.inputs[uint(input.PlayerId)].Actions) {
+					t.Errorf("player %d lost actions", input.PlayerId)
+				}
+			}
+		})
+	}
+
+	if _, err := rd.Next(); err != io.EOF {
+		t.Errorf("got %v after last tick, want io.EOF", err)
+	}
+}
@@ -0,0 +1,123 @@
+package weapons
+
+import (
+	"CircleWar/config"
+	"hash/fnv"
+	"time"
+)
+
+// same values as the WeaponType enum of the protocol
+type Type int32
+
+const (
+	Pistol Type = iota
+	Shotgun
+	Sniper
+	SMG
+)
+
+type Weapon struct {
+	Type    Type
+	Name    string
+	Damage  int
+	Speed   float32 // world units per second
+	Spread  float32 // radians, pellets fan out over it, single bullets stray inside it
+	Pellets int
+	// time after a shot before the shooter can shoot again, with any weapon
+	Cooldown time.Duration
+	Range    float32 // world units a bullet flies before it's gone
+	// bullets are this times the shooter's bullet size, which shrinks with health
+	SizeScale float32
+	// damage drops from Damage to MinDamage between FalloffStart (a fraction
+	// of Range) and the end of the range, no falloff when FalloffStart is 0
+	FalloffStart float32
+	MinDamage    int
+}
+
+func (w Weapon) Lifetime() time.Duration {
+	return time.Duration(float64(w.Range) / float64(w.Speed) * float64(time.Second))
+}
+
+// indexed by Type
+var presets = []Weapon{
+	// speed, cooldown and range come from the gameplay config, see Get
+	Pistol: {
+		Type: Pistol, Name: "pistol",
+		Damage: 1, Pellets: 1,
+		SizeScale: 1,
+	},
+	Shotgun: {
+		Type: Shotgun, Name: "shotgun",
+		Damage: 1, Speed: 1500, Spread: 0.5, Pellets: 6,
+		// pellets that flew past three quarters of the range are spent
+		FalloffStart: 0.5, MinDamage: 0,
+		Cooldown:  700 * time.Millisecond,
+		Range:     700,
+		SizeScale: 0.6,
+	},
+	Sniper: {
+		Type: Sniper, Name: "sniper",
+		Damage: 5, Speed: 4000, Pellets: 1,
+		Cooldown:  1200 * time.Millisecond,
+		Range:     4000,
+		SizeScale: 0.5,
+	},
+	SMG: {
+		Type: SMG, Name: "smg",
+		Damage: 1, Speed: 2000, Spread: 0.15, Pellets: 1,
+		Cooldown:  70 * time.Millisecond,
+		Range:     1400,
+		SizeScale: 0.5,
+	},
+}
+
+// the weapon of type t, pistol for unknown types
+func Get(t Type) Weapon {
+	if t < 0 || int(t) >= len(presets) {
+		t = Pistol
+	}
+	w := presets[t]
+	if t == Pistol {
+		g := config.Current()
+		w.Speed = g.BulletSpeed
+		w.Cooldown = g.BulletCooldown()
+		w.Range = g.BulletSpeed * g.BulletTimeToLiveSec
+	}
+	return w
+}
+
+// what every player carries, ShootAction picks a slot of it
+var Loadout = []Type{Pistol, Shotgun, Sniper, SMG}
+
+func InSlot(slot uint32) (Weapon, bool) {
+	if int(slot) >= len(Loadout) {
+		return Weapon{}, false
+	}
+	return Get(Loadout[slot]), true
+}
+
+// angle of each pellet relative to the aim. several pellets fan out evenly,
+// a single one strays by an amount derived from seed so the simulation
+// stays deterministic
+func (w Weapon) PelletAngles(seed uint64) []float32 {
+	pellets := max(1, w.Pellets)
+	if pellets == 1 {
+		if w.Spread == 0 {
+			return []float32{0}
+		}
+		h := fnv.New64a()
+		var buf [8]byte
+		for i := range buf {
+			buf[i] = byte(seed >> (8 * i))
+		}
+		h.Write(buf[:])
+		frac := float32(h.Sum64()%1_000_000) / 1_000_000
+		return []float32{(frac - 0.5) * w.Spread}
+	}
+
+	angles := make([]float32, pellets)
+	for i := range angles {
+		angles[i] = -w.Spread/2 + w.Spread*float32(i)/float32(pellets-1)
+	}
+	return angles
+}
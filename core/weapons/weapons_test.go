@@ -0,0 +1,51 @@
+package weapons
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPelletAngles(t *testing.T) {
+	tests := []struct {
+		name   string
+		weapon Weapon
+		want   []float32 // nil when only the bounds are checked
+	}{
+		{"straight", Weapon{Pellets: 1}, []float32{0}},
+		{"fan", Weapon{Pellets: 3, Spread: 1}, []float32{-0.5, 0, 0.5}},
+		{"stray", Weapon{Pellets: 1, Spread: 0.2}, nil},
+		{"no pellets fires one", Weapon{}, []float32{0}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for seed := range uint64(50) {
+				got := test.weapon.PelletAngles(seed)
+				if !slices.Equal(got, test.weapon.PelletAngles(seed)) {
+					t.Fatalf("seed %d gives different angles", seed)
+				}
+				if test.want != nil && !slices.Equal(got, test.want) {
+					t.Fatalf("got %v want %v", got, test.want)
+				}
+				for _, angle := range got {
+					if angle < -test.weapon.Spread/2 || angle > test.weapon.Spread/2 {
+						t.Fatalf("angle %f outside spread %f", angle, test.weapon.Spread)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestInSlot(t *testing.T) {
+	for slot, want := range Loadout {
+		if w, ok := InSlot(uint32(slot)); !ok || w.Type != want {
+			t.Errorf("slot %d: got %v %t want %v", slot, w.Type, ok, want)
+		}
+	}
+	if _, ok := InSlot(uint32(len(Loadout))); ok {
+		t.Error("slot past the loadout is valid")
+	}
+	if Get(Type(99)).Type != Pistol {
+		t.Error("unknown weapon type isn't a pistol")
+	}
+}
@@ -16,3 +16,11 @@ func ExeDir() string {
 func EnvfilePath() string {
 	return filepath.Join(ExeDir(), ".env")
 }
+
+func KeybindsPath() string {
+	return filepath.Join(ExeDir(), "keybinds.txt")
+}
+
+func ConfigPath() string {
+	return filepath.Join(ExeDir(), "config.json")
+}
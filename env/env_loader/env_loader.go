@@ -1,39 +1,194 @@
+// loads .env files into the process environment. a line is NAME=value,
+// optionally after "export ", and the value can be
+//
+//	unquoted    trimmed, " #" starts a comment and ${VAR} is expanded
+//	'single'    taken as it is
+//	"double"    \n \t \r \" \\ \$ are escapes and ${VAR} is expanded
+//
+// blank lines and lines starting with # are skipped
 package envloader
 
 import (
 	"bufio"
-	"errors"
+	"fmt"
+	"io"
 	"os"
 	"strings"
 )
 
+type Options struct {
+	// the file's values replace variables the process already has
+	Override bool
+}
+
+type variable struct {
+	name, value string
+}
+
+// loads filename, variables the process already has keep their values
 func LoadFile(filename string) error {
+	return Load(filename, Options{})
+}
+
+func Load(filename string, opts Options) error {
 	file, err := os.Open(filename)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		parts := strings.Split(line, "=")
+	vars, err := parse(file, opts.Override)
+	if err != nil {
+		return fmt.Errorf("%s:%w", filename, err)
+	}
+	preset := make(map[string]bool)
+	for _, v := range vars {
+		_, preset[v.name] = os.LookupEnv(v.name)
+	}
+	for _, v := range vars {
+		if preset[v.name] && !opts.Override {
+			continue
+		}
+		if err := os.Setenv(v.name, v.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-		if len(parts) != 2 {
-			return errors.New("Line '" + line + "' has invalid format")
+// the file's variables in order. ${VAR} expands to what VAR is set to once
+// the file is loaded: the process' value unless overriding, else the last
+// one the file gave it so far
+func parse(r io.Reader, override bool) ([]variable, error) {
+	fromFile := make(map[string]string)
+	lookup := func(name string) string {
+		if v, ok := os.LookupEnv(name); ok && !override {
+			return v
 		}
+		if v, ok := fromFile[name]; ok {
+			return v
+		}
+		return os.Getenv(name)
+	}
 
-		parts[0] = strings.Trim(parts[0], " ")
-		parts[1] = strings.Trim(parts[1], " ")
+	var vars []variable
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		v, err := parseLine(line, lookup)
+		if err != nil {
+			return nil, fmt.Errorf("%d: %w", lineNum, err)
+		}
+		fromFile[v.name] = v.value
+		vars = append(vars, v)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
 
-		os.Setenv(parts[0], parts[1])
+func validName(name string) bool {
+	for i, c := range name {
+		letter := c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+		if !letter && (i == 0 || c < '0' || c > '9') {
+			return false
+		}
 	}
+	return name != ""
+}
 
-	if err := scanner.Err(); err != nil {
-		return err
+func parseLine(line string, lookup func(string) string) (variable, error) {
+	if rest, ok := strings.CutPrefix(line, "export"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+		line = strings.TrimSpace(rest)
+	}
+	name, value, ok := strings.Cut(line, "=")
+	if !ok {
+		return variable{}, fmt.Errorf("'%s' isn't NAME=value", line)
+	}
+	name = strings.TrimSpace(name)
+	if !validName(name) {
+		return variable{}, fmt.Errorf("'%s' isn't a variable name", name)
 	}
 
-	return nil
+	value = strings.TrimSpace(value)
+	var err error
+	switch {
+	case strings.HasPrefix(value, "'"):
+		value, err = quoted(value, '\'', false, lookup)
+	case strings.HasPrefix(value, `"`):
+		value, err = quoted(value, '"', true, lookup)
+	default:
+		if i := strings.Index(value, " #"); i >= 0 {
+			value = strings.TrimSpace(value[:i])
+		}
+		value, err = expand(value, lookup)
+	}
+	if err != nil {
+		return variable{}, fmt.Errorf("%s: %w", name, err)
+	}
+	return variable{name, value}, nil
+}
+
+var escapes = map[byte]byte{'n': '\n', 't': '\t', 'r': '\r', '"': '"', '\\': '\\', '$': '$'}
+
+// the value between the quote at the start of s and the closing one, only a
+// comment can come after it. escapes and ${VAR} are only read in double quotes
+func quoted(s string, quote byte, double bool, lookup func(string) string) (string, error) {
+	var out strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == quote:
+			if rest := strings.TrimSpace(s[i+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+				return "", fmt.Errorf("unexpected '%s' after the quoted value", rest)
+			}
+			return out.String(), nil
+		case double && c == '\\':
+			if i+1 == len(s) {
+				break
+			}
+			escaped, ok := escapes[s[i+1]]
+			if !ok {
+				return "", fmt.Errorf("unknown escape \\%c", s[i+1])
+			}
+			out.WriteByte(escaped)
+			i++
+		case double && strings.HasPrefix(s[i:], "${"):
+			// the } has to come before the closing quote
+			end := strings.IndexAny(s[i:], "}\"")
+			if end < 0 || s[i+end] != '}' {
+				return "", fmt.Errorf("unclosed ${")
+			}
+			out.WriteString(lookup(s[i+2 : i+end]))
+			i += end
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return "", fmt.Errorf("missing closing %c", quote)
+}
+
+// replaces every ${VAR} in s
+func expand(s string, lookup func(string) string) (string, error) {
+	var out strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			out.WriteString(s)
+			return out.String(), nil
+		}
+		end := strings.IndexByte(s[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unclosed ${")
+		}
+		out.WriteString(s[:start])
+		out.WriteString(lookup(s[start+2 : start+end]))
+		s = s[start+end+1:]
+	}
 }
 
 func GetEnv(name, def string) string {
@@ -1,10 +1,22 @@
 package envloader
 
 import (
+	"errors"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
+func writeEnvFile(t *testing.T, lines []string) string {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
 func TestEnvLoader(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -15,18 +27,21 @@ func TestEnvLoader(t *testing.T) {
 		{"basic", []string{"YOU=SHUBANA"}, []string{"YOU"}, []string{"SHUBANA"}},
 		{"spaces", []string{" BOSHY  =  BLACK  "}, []string{"BOSHY"}, []string{"BLACK"}},
 		{"spaces", []string{" BOSHY  =  BLACK  ", "CAPTAIN = TEEMO"}, []string{"BOSHY", "CAPTAIN"}, []string{"BLACK", "TEEMO"}},
+		{"comments and blank lines", []string{"# the server", "", "  # indented", "SERVER=1.2.3.4 # not this"}, []string{"SERVER"}, []string{"1.2.3.4"}},
+		{"hash inside a value", []string{"COLOR=#ff0000", "CHANNEL=a#b"}, []string{"COLOR", "CHANNEL"}, []string{"#ff0000", "a#b"}},
+		{"export", []string{"export EXPORTED=yes", "exported_too=1"}, []string{"EXPORTED", "exported_too"}, []string{"yes", "1"}},
+		{"equals in the value", []string{"TOKEN=abc==", "QUERY=a=1&b=2"}, []string{"TOKEN", "QUERY"}, []string{"abc==", "a=1&b=2"}},
+		{"single quotes", []string{`SINGLE='  a \n ${YOU} "b" '  # comment`}, []string{"SINGLE"}, []string{`  a \n ${YOU} "b" `}},
+		{"double quotes", []string{`DOUBLE="line\nnext \"q\" \\ \$ # kept"`}, []string{"DOUBLE"}, []string{"line\nnext \"q\" \\ $ # kept"}},
+		{"empty", []string{"EMPTY=", `EMPTY_QUOTED=""`}, []string{"EMPTY", "EMPTY_QUOTED"}, []string{"", ""}},
+		{"expansion", []string{"HOST=example.com", "URL=http://${HOST}:${PORT_NUM}/x", `QUOTED="${HOST}!"`, "MISSING=[${NOT_SET_ANYWHERE}]"},
+			[]string{"URL", "QUOTED", "MISSING"}, []string{"http://example.com:/x", "example.com!", "[]"}},
 	}
-	testFileName := "test_envloader.txt"
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			file, _ := os.Create(testFileName)
-			defer file.Close()
-			for _, line := range test.lines {
-				file.WriteString(line + "\n")
-			}
-
-			err := LoadFile(testFileName)
+			path := writeEnvFile(t, test.lines)
+			err := LoadFile(path)
 			if err != nil {
 				t.Errorf("error from LoadEnv: %s", err)
 			}
@@ -34,11 +49,66 @@ func TestEnvLoader(t *testing.T) {
 			for i := range len(test.keys) {
 				envar := os.Getenv(test.keys[i])
 				if envar != test.vars[i] {
-					t.Errorf("got %s want %s", envar, test.vars[i])
+					t.Errorf("got %q want %q", envar, test.vars[i])
 				}
 			}
 		})
 	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		lines   []string
+		wantErr string
+	}{
+		{"no equals", []string{"# ok", "GOOD=1", "just words"}, ":3: 'just words' isn't NAME=value"},
+		{"bad name", []string{"1ST=x"}, ":1: '1ST' isn't a variable name"},
+		{"unclosed quote", []string{"", `OPEN="abc`}, `:2: OPEN: missing closing "`},
+		{"text after quote", []string{`AFTER='a' b`}, ":1: AFTER: unexpected 'b'"},
+		{"unknown escape", []string{`ESC="\q"`}, `:1: ESC: unknown escape \q`},
+		{"unclosed expansion", []string{"EXP=${HOST"}, ":1: EXP: unclosed ${"},
+		{"expansion closed after the quote", []string{`EXP="${HOST" # }`}, ":1: EXP: unclosed ${"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := LoadFile(writeEnvFile(t, test.lines))
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("got error %v want one with %q", err, test.wantErr)
+			}
+		})
+	}
+
+	if err := LoadFile(filepath.Join(t.TempDir(), "missing")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("got error %v for a missing file", err)
+	}
+}
 
-	os.Remove(testFileName)
+func TestProcessEnvWins(t *testing.T) {
+	lines := []string{"PRESET=from file", "DERIVED=${PRESET}!", "PRESET=again"}
+	tests := []struct {
+		name        string
+		opts        Options
+		wantPreset  string
+		wantDerived string
+	}{
+		{"default", Options{}, "from process", "from process!"},
+		{"override", Options{Override: true}, "again", "from file!"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("PRESET", "from process")
+			t.Setenv("DERIVED", "")
+			os.Unsetenv("DERIVED")
+			if err := Load(writeEnvFile(t, lines), test.opts); err != nil {
+				t.Fatal(err)
+			}
+			if got := os.Getenv("PRESET"); got != test.wantPreset {
+				t.Errorf("got PRESET %q want %q", got, test.wantPreset)
+			}
+			if got := os.Getenv("DERIVED"); got != test.wantDerived {
+				t.Errorf("got DERIVED %q want %q", got, test.wantDerived)
+			}
+		})
+	}
 }
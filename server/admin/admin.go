@@ -0,0 +1,289 @@
+// http api for whoever runs the server. reads come from the world snapshots
+// the server loop publishes, changes are sent to the loop as commands and
+// run there between ticks, so nothing here touches the world directly
+package admin
+
+import (
+	"CircleWar/config"
+	wstate "CircleWar/server/world_state"
+	"cmp"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// the server hosts one room, clients join it by this name
+const RoomName = "default"
+
+// how long a request waits for the loop to take and run its command
+const commandTimeout = 2 * time.Second
+
+// what the loop knows besides the world
+type Status struct {
+	Mode   string
+	Map    string
+	Paused bool
+	Banned []string
+	// round trip of the last ping of every player
+	Pings map[uint]time.Duration
+	// what the match plays by
+	Gameplay config.Gameplay
+}
+
+type CommandKind int
+
+const (
+	Kick CommandKind = iota
+	Ban
+	SetMode
+	SetMap
+	Pause
+	Resume
+	Broadcast
+	SetConfig
+)
+
+var commandNames = map[CommandKind]string{
+	Kick:      "kick",
+	Ban:       "ban",
+	SetMode:   "mode",
+	SetMap:    "map",
+	Pause:     "pause",
+	Resume:    "resume",
+	Broadcast: "broadcast",
+	SetConfig: "config",
+}
+
+func (k CommandKind) String() string {
+	return commandNames[k]
+}
+
+// a change for the loop to make. it runs it and calls Done with how it went
+type Command struct {
+	Kind     CommandKind
+	PlayerId uint   // Kick and Ban
+	Arg      string // the mode, map, message or json of the settings to change
+	done     chan error
+}
+
+func (c Command) Done(err error) {
+	c.done <- err
+}
+
+// errors of commands the loop refused because of what was asked, they are
+// reported to the admin as bad requests
+type InvalidError struct {
+	Reason string
+}
+
+func (e InvalidError) Error() string {
+	return e.Reason
+}
+
+type Server struct {
+	token    string
+	feed     *wstate.SnapshotFeed
+	status   atomic.Pointer[Status]
+	commands chan Command
+}
+
+// requests must carry token as a bearer token, an empty token refuses them all
+func New(token string, feed *wstate.SnapshotFeed) *Server {
+	s := &Server{token: token, feed: feed, commands: make(chan Command)}
+	s.SetStatus(Status{})
+	return s
+}
+
+// commands for the loop to run, every one of them must be Done
+func (s *Server) Commands() <-chan Command {
+	return s.commands
+}
+
+// called by the loop whenever the status changes
+func (s *Server) SetStatus(status Status) {
+	s.status.Store(&status)
+}
+
+type RoomInfo struct {
+	Name    string `json:"name"`
+	Mode    string `json:"mode"`
+	Map     string `json:"map"`
+	Paused  bool   `json:"paused"`
+	Tick    uint32 `json:"tick"`
+	Players int    `json:"players"`
+}
+
+type PlayerInfo struct {
+	Id     uint    `json:"id"`
+	Addr   string  `json:"address"`
+	Alive  bool    `json:"alive"`
+	Health float32 `json:"health"`
+	PingMs float64 `json:"ping_ms"`
+	Score  int     `json:"score"`
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rooms", s.rooms)
+	mux.HandleFunc("GET /players", s.players)
+	mux.HandleFunc("POST /players/{id}/kick", s.playerCommand(Kick))
+	mux.HandleFunc("POST /players/{id}/ban", s.playerCommand(Ban))
+	mux.HandleFunc("POST /mode", s.argCommand(SetMode, "mode"))
+	mux.HandleFunc("POST /map", s.argCommand(SetMap, "map"))
+	mux.HandleFunc("POST /pause", s.command(Pause))
+	mux.HandleFunc("POST /resume", s.command(Resume))
+	mux.HandleFunc("POST /broadcast", s.argCommand(Broadcast, "message"))
+	mux.HandleFunc("GET /config", s.gameplay)
+	mux.HandleFunc("POST /config", s.setConfig)
+	return s.authorized(mux)
+}
+
+func (s *Server) authorized(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if s.token == "" || !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			writeError(w, http.StatusUnauthorized, "missing or wrong token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, reason string) {
+	writeJSON(w, code, map[string]string{"error": reason})
+}
+
+func (s *Server) rooms(w http.ResponseWriter, r *http.Request) {
+	status := s.status.Load()
+	snap, _ := s.feed.Latest()
+	writeJSON(w, http.StatusOK, []RoomInfo{{
+		Name:    RoomName,
+		Mode:    status.Mode,
+		Map:     status.Map,
+		Paused:  status.Paused,
+		Tick:    snap.Tick,
+		Players: len(snap.Addresses),
+	}})
+}
+
+// every connected player, the dead ones waiting to respawn included
+func (s *Server) players(w http.ResponseWriter, r *http.Request) {
+	status := s.status.Load()
+	snap, _ := s.feed.Latest()
+	players := []PlayerInfo{}
+	for id, addr := range snap.Addresses {
+		info := PlayerInfo{
+			Id:     id,
+			Addr:   addr.String(),
+			PingMs: float64(status.Pings[id]) / float64(time.Millisecond),
+			Score:  snap.Scores[id],
+		}
+		if player, ok := snap.Player(id); ok {
+			info.Alive = true
+			info.Health = float32(player.Health)
+		}
+		players = append(players, info)
+	}
+	slices.SortFunc(players, func(a, b PlayerInfo) int {
+		return cmp.Compare(a.Id, b.Id)
+	})
+	writeJSON(w, http.StatusOK, players)
+}
+
+func (s *Server) gameplay(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.status.Load().Gameplay)
+}
+
+// the body is a json object of the settings to change, like
+// {"bullet_speed": 2000}. the loop checks them and applies them on the next tick
+func (s *Server) setConfig(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1<<16))
+	var settings map[string]json.RawMessage
+	if err != nil || json.Unmarshal(body, &settings) != nil {
+		writeError(w, http.StatusBadRequest, "body isn't a json object")
+		return
+	}
+	s.run(w, r, Command{Kind: SetConfig, Arg: string(body)})
+}
+
+// hands cmd to the loop and writes how it went
+func (s *Server) run(w http.ResponseWriter, r *http.Request, cmd Command) {
+	cmd.done = make(chan error, 1)
+	timeout := time.After(commandTimeout)
+	select {
+	case s.commands <- cmd:
+	case <-timeout:
+		writeError(w, http.StatusServiceUnavailable, "server is busy")
+		return
+	case <-r.Context().Done():
+		return
+	}
+
+	select {
+	case err := <-cmd.done:
+		var invalid InvalidError
+		switch {
+		case err == nil:
+			writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+		case errors.As(err, &invalid):
+			writeError(w, http.StatusBadRequest, invalid.Reason)
+		default:
+			writeError(w, http.StatusInternalServerError, err.Error())
+		}
+	case <-timeout:
+		writeError(w, http.StatusServiceUnavailable, "server is busy")
+	case <-r.Context().Done():
+	}
+}
+
+func (s *Server) command(kind CommandKind) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.run(w, r, Command{Kind: kind})
+	}
+}
+
+func (s *Server) playerCommand(kind CommandKind) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 32)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "player id isn't a number")
+			return
+		}
+		snap, _ := s.feed.Latest()
+		if _, ok := snap.Addresses[uint(id)]; !ok {
+			writeError(w, http.StatusNotFound, "no such player")
+			return
+		}
+		s.run(w, r, Command{Kind: kind, PlayerId: uint(id)})
+	}
+}
+
+// commands with one argument, read from the json body's field
+func (s *Server) argCommand(kind CommandKind, field string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]string{}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, "body isn't a json object of strings")
+			return
+		}
+		arg, ok := body[field]
+		if !ok {
+			writeError(w, http.StatusBadRequest, "missing '"+field+"'")
+			return
+		}
+		s.run(w, r, Command{Kind: kind, Arg: arg})
+	}
+}
@@ -0,0 +1,172 @@
+package admin
+
+import (
+	"CircleWar/config"
+	"CircleWar/server/sim"
+	wstate "CircleWar/server/world_state"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const token = "secret"
+
+// an admin server over a world with players 1 and 2, player 2 is dead
+func newTestServer(t *testing.T) (*Server, *httptest.Server) {
+	sw := wstate.NewServerWorld(1000, 1000, sim.NewStepClock(0))
+	player := sim.ConnectPlayer(&sw, 1, net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 4000})
+	sim.ConnectPlayer(&sw, 2, net.UDPAddr{IP: net.IPv4(10, 0, 0, 2), Port: 4000})
+	player.ChangeHealth(-2)
+	sw.AddKill(1)
+	sw.RemovePlayer(2)
+	feed := &wstate.SnapshotFeed{}
+	feed.Publish(&sw)
+
+	srv := New(token, feed)
+	srv.SetStatus(Status{
+		Mode:     "deathmatch",
+		Pings:    map[uint]time.Duration{1: 25 * time.Millisecond},
+		Gameplay: config.Default().Gameplay,
+	})
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+	return srv, ts
+}
+
+func request(t *testing.T, ts *httptest.Server, method, path, body, auth string) *http.Response {
+	req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if auth != "" {
+		req.Header.Set("Authorization", "Bearer "+auth)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// runs the next command the way the loop would, answering with err
+func runLoop(srv *Server, err error) chan Command {
+	ran := make(chan Command, 1)
+	go func() {
+		cmd := <-srv.Commands()
+		cmd.Done(err)
+		ran <- cmd
+	}()
+	return ran
+}
+
+func TestAuth(t *testing.T) {
+	_, ts := newTestServer(t)
+	tests := []struct {
+		name     string
+		auth     string
+		wantCode int
+	}{
+		{"no token", "", http.StatusUnauthorized},
+		{"wrong token", "guess", http.StatusUnauthorized},
+		{"right token", token, http.StatusOK},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if resp := request(t, ts, "GET", "/rooms", "", test.auth); resp.StatusCode != test.wantCode {
+				t.Errorf("got status %d want %d", resp.StatusCode, test.wantCode)
+			}
+		})
+	}
+
+	t.Run("no token configured", func(t *testing.T) {
+		ts := httptest.NewServer(New("", &wstate.SnapshotFeed{}).Handler())
+		defer ts.Close()
+		if resp := request(t, ts, "GET", "/rooms", "", ""); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("got status %d want %d", resp.StatusCode, http.StatusUnauthorized)
+		}
+	})
+}
+
+func TestListing(t *testing.T) {
+	_, ts := newTestServer(t)
+
+	var rooms []RoomInfo
+	json.NewDecoder(request(t, ts, "GET", "/rooms", "", token).Body).Decode(&rooms)
+	if len(rooms) != 1 || rooms[0].Name != RoomName || rooms[0].Mode != "deathmatch" || rooms[0].Players != 2 {
+		t.Errorf("got rooms %+v", rooms)
+	}
+
+	var players []PlayerInfo
+	json.NewDecoder(request(t, ts, "GET", "/players", "", token).Body).Decode(&players)
+	want := []PlayerInfo{
+		{Id: 1, Addr: "10.0.0.1:4000", Alive: true, Health: config.Default().InitialPlayerHealth - 2, PingMs: 25, Score: 1},
+		{Id: 2, Addr: "10.0.0.2:4000"},
+	}
+	if len(players) != len(want) {
+		t.Fatalf("got players %+v want %+v", players, want)
+	}
+	for i := range want {
+		if players[i] != want[i] {
+			t.Errorf("got player %+v want %+v", players[i], want[i])
+		}
+	}
+
+	var gameplay config.Gameplay
+	json.NewDecoder(request(t, ts, "GET", "/config", "", token).Body).Decode(&gameplay)
+	if gameplay != config.Default().Gameplay {
+		t.Errorf("got gameplay %+v", gameplay)
+	}
+}
+
+func TestCommands(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		body     string
+		loopErr  error
+		wantCode int
+		wantCmd  Command // compared when the loop ran it
+		wantRun  bool
+	}{
+		{"kick", "/players/2/kick", "", nil, http.StatusOK, Command{Kind: Kick, PlayerId: 2}, true},
+		{"ban", "/players/1/ban", "", nil, http.StatusOK, Command{Kind: Ban, PlayerId: 1}, true},
+		{"unknown player", "/players/9/kick", "", nil, http.StatusNotFound, Command{}, false},
+		{"bad player id", "/players/me/kick", "", nil, http.StatusBadRequest, Command{}, false},
+		{"mode", "/mode", `{"mode": "elimination"}`, nil, http.StatusOK, Command{Kind: SetMode, Arg: "elimination"}, true},
+		{"refused mode", "/mode", `{"mode": "tag"}`, InvalidError{"unknown game mode"}, http.StatusBadRequest, Command{Kind: SetMode, Arg: "tag"}, true},
+		{"missing field", "/map", `{"mode": "x"}`, nil, http.StatusBadRequest, Command{}, false},
+		{"not json", "/broadcast", `hello`, nil, http.StatusBadRequest, Command{}, false},
+		{"pause", "/pause", "", nil, http.StatusOK, Command{Kind: Pause}, true},
+		{"resume", "/resume", "", nil, http.StatusOK, Command{Kind: Resume}, true},
+		{"broadcast", "/broadcast", `{"message": "restart in 5"}`, nil, http.StatusOK, Command{Kind: Broadcast, Arg: "restart in 5"}, true},
+		{"config", "/config", `{"bullet_speed": 2000}`, nil, http.StatusOK, Command{Kind: SetConfig, Arg: `{"bullet_speed": 2000}`}, true},
+		{"refused config", "/config", `{"port": 1}`, InvalidError{"port can't change"}, http.StatusBadRequest, Command{Kind: SetConfig, Arg: `{"port": 1}`}, true},
+		{"config not an object", "/config", `[1]`, nil, http.StatusBadRequest, Command{}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv, ts := newTestServer(t)
+			ran := runLoop(srv, test.loopErr)
+			resp := request(t, ts, "POST", test.path, test.body, token)
+			if resp.StatusCode != test.wantCode {
+				t.Errorf("got status %d want %d", resp.StatusCode, test.wantCode)
+			}
+			select {
+			case cmd := <-ran:
+				cmd.done = nil
+				if !test.wantRun || cmd != test.wantCmd {
+					t.Errorf("loop ran %+v", cmd)
+				}
+			case <-time.After(50 * time.Millisecond):
+				if test.wantRun {
+					t.Error("loop didn't get the command")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+// entities of the world and their components. an entity is a slot in a Store,
+// slots are reused after their entity is removed but with a new generation,
+// so a handle of a removed entity never resolves to the one that took its slot
+package entity
+
+import "slices"
+
+type Handle struct {
+	index uint32
+	gen   uint32
+}
+
+// the zero handle never belongs to an entity, generations start at 1
+func (h Handle) IsZero() bool {
+	return h.gen == 0
+}
+
+type Store struct {
+	gens  []uint32
+	alive []bool
+	free  []uint32
+	// handles in the order they were made, removed ones are dropped by
+	// Remove once they are half of it
+	order []Handle
+	count int
+}
+
+func NewStore() *Store {
+	return &Store{}
+}
+
+func (s *Store) New() Handle {
+	var index uint32
+	if len(s.free) > 0 {
+		index = s.free[0]
+		s.free = s.free[1:]
+	} else {
+		index = uint32(len(s.gens))
+		s.gens = append(s.gens, 0)
+		s.alive = append(s.alive, false)
+	}
+	s.gens[index]++
+	s.alive[index] = true
+	s.count++
+
+	h := Handle{index, s.gens[index]}
+	s.order = append(s.order, h)
+	return h
+}
+
+func (s *Store) Alive(h Handle) bool {
+	return int(h.index) < len(s.gens) && s.alive[h.index] && s.gens[h.index] == h.gen
+}
+
+// false when h was already removed
+func (s *Store) Remove(h Handle) bool {
+	if !s.Alive(h) {
+		return false
+	}
+	s.alive[h.index] = false
+	s.free = append(s.free, h.index)
+	s.count--
+	if len(s.order) > 2*s.count+16 {
+		s.order = slices.DeleteFunc(s.order, func(h Handle) bool { return !s.Alive(h) })
+	}
+	return true
+}
+
+func (s *Store) Len() int {
+	return s.count
+}
+
+// live entities in the order they were made. it's a copy, entities can be
+// added and removed while walking it, the removed ones stop being Alive
+func (s *Store) Handles() []Handle {
+	handles := make([]Handle, 0, s.count)
+	for _, h := range s.order {
+		if s.Alive(h) {
+			handles = append(handles, h)
+		}
+	}
+	return handles
+}
+
+// deep copy of the store, handles of s resolve in it to the same entities
+func (s *Store) Clone() *Store {
+	return &Store{
+		gens:  slices.Clone(s.gens),
+		alive: slices.Clone(s.alive),
+		free:  slices.Clone(s.free),
+		order: slices.Clone(s.order),
+		count: s.count,
+	}
+}
+
+// values of type T some entities of a store have. every value is allocated on
+// its own, so pointers from Get stay valid while other entities come and go
+type Component[T any] struct {
+	store  *Store
+	values []*T
+	gens   []uint32
+}
+
+func NewComponent[T any](store *Store) *Component[T] {
+	return &Component[T]{store: store}
+}
+
+func (c *Component[T]) Set(h Handle, value T) {
+	if !c.store.Alive(h) {
+		return
+	}
+	for int(h.index) >= len(c.values) {
+		c.values = append(c.values, nil)
+		c.gens = append(c.gens, 0)
+	}
+	c.values[h.index] = &value
+	c.gens[h.index] = h.gen
+}
+
+// the value of a live entity that has one, nil and false otherwise
+func (c *Component[T]) Get(h Handle) (*T, bool) {
+	if !c.store.Alive(h) || int(h.index) >= len(c.values) || c.gens[h.index] != h.gen {
+		return nil, false
+	}
+	return c.values[h.index], true
+}
+
+func (c *Component[T]) Has(h Handle) bool {
+	_, ok := c.Get(h)
+	return ok
+}
+
+func (c *Component[T]) Delete(h Handle) {
+	if c.Has(h) {
+		c.values[h.index] = nil
+		c.gens[h.index] = 0
+	}
+}
+
+// live entities that have the component, in the order they were made
+func (c *Component[T]) Handles() []Handle {
+	return slices.DeleteFunc(c.store.Handles(), func(h Handle) bool { return !c.Has(h) })
+}
+
+// copy of the component for store, a clone of the one c belongs to. values
+// are copied with copyValue, or as they are when it is nil
+func (c *Component[T]) Clone(store *Store, copyValue func(T) T) *Component[T] {
+	clone := &Component[T]{store: store, values: make([]*T, len(c.values)), gens: slices.Clone(c.gens)}
+	for i, value := range c.values {
+		if value == nil || !store.Alive(Handle{uint32(i), c.gens[i]}) {
+			continue
+		}
+		copied := *value
+		if copyValue != nil {
+			copied = copyValue(copied)
+		}
+		clone.values[i] = &copied
+	}
+	return clone
+}
@@ -0,0 +1,133 @@
+package entity
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestStaleHandles(t *testing.T) {
+	store := NewStore()
+	names := NewComponent[string](store)
+
+	old := store.New()
+	names.Set(old, "old")
+	store.Remove(old)
+	reused := store.New()
+	names.Set(reused, "new")
+
+	if old.index != reused.index {
+		t.Fatalf("slot %d wasn't reused, got %d", old.index, reused.index)
+	}
+	if store.Alive(old) {
+		t.Error("removed handle is alive")
+	}
+	if name, ok := names.Get(old); ok {
+		t.Errorf("removed handle resolves to %q", *name)
+	}
+	if name, ok := names.Get(reused); !ok || *name != "new" {
+		t.Errorf("got %v %t want new", name, ok)
+	}
+	if store.Remove(old) {
+		t.Error("removed the same handle twice")
+	}
+	if (Handle{}).IsZero() != true || reused.IsZero() {
+		t.Error("only the zero handle should be zero")
+	}
+}
+
+func TestComponentNotSet(t *testing.T) {
+	store := NewStore()
+	names := NewComponent[string](store)
+	h := store.New()
+	if names.Has(h) {
+		t.Error("entity has a component that was never set")
+	}
+	names.Set(h, "a")
+	names.Delete(h)
+	if names.Has(h) {
+		t.Error("deleted component is still there")
+	}
+
+	// a value set for the slot's previous entity doesn't carry over
+	store.Remove(h)
+	names.Set(h, "stale")
+	next := store.New()
+	if names.Has(next) {
+		t.Error("new entity got the component of the removed one")
+	}
+}
+
+func TestRemoveWhileIterating(t *testing.T) {
+	store := NewStore()
+	values := NewComponent[int](store)
+	handles := []Handle{}
+	for i := range 5 {
+		h := store.New()
+		values.Set(h, i)
+		handles = append(handles, h)
+	}
+
+	seen := []int{}
+	for i, h := range values.Handles() {
+		value, ok := values.Get(h)
+		if !ok {
+			continue
+		}
+		seen = append(seen, *value)
+		// removes the next entity and adds one that isn't walked
+		if i+1 < len(handles) {
+			store.Remove(handles[i+1])
+		}
+		values.Set(store.New(), 100)
+	}
+	if !slices.Equal(seen, []int{0, 2, 4}) {
+		t.Errorf("walked %v want [0 2 4]", seen)
+	}
+	if store.Len() != 6 {
+		t.Errorf("got %d entities want 6", store.Len())
+	}
+}
+
+func TestHandlesInCreationOrder(t *testing.T) {
+	store := NewStore()
+	a, b, c := store.New(), store.New(), store.New()
+	store.Remove(a)
+	d := store.New() // takes a's slot
+	if got := store.Handles(); !slices.Equal(got, []Handle{b, c, d}) {
+		t.Errorf("got %v want %v", got, []Handle{b, c, d})
+	}
+
+	// removed handles don't pile up, and the rest keep their order
+	want := []Handle{b, c, d}
+	for i := range 100 {
+		h := store.New()
+		if i%10 == 0 {
+			want = append(want, h)
+		} else {
+			store.Remove(h)
+		}
+	}
+	if got := store.Handles(); !slices.Equal(got, want) {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if len(store.order) > 2*store.Len()+16 {
+		t.Errorf("kept %d handles for %d entities", len(store.order), store.Len())
+	}
+}
+
+func TestClone(t *testing.T) {
+	store := NewStore()
+	values := NewComponent[[]int](store)
+	h := store.New()
+	values.Set(h, []int{1})
+
+	cloneStore := store.Clone()
+	clone := values.Clone(cloneStore, slices.Clone)
+	v, _ := clone.Get(h)
+	(*v)[0] = 2
+	cloneStore.Remove(h)
+
+	if orig, ok := values.Get(h); !ok || (*orig)[0] != 1 {
+		t.Errorf("changing the clone changed the original: %v %t", orig, ok)
+	}
+}
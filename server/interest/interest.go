@@ -0,0 +1,161 @@
+// per-client snapshots: a client only gets the part of the world it can see,
+// and when that doesn't fit in a packet, the part closest to what it looks at
+package interest
+
+import (
+	"CircleWar/core/geom"
+	"CircleWar/core/hitboxes"
+	"CircleWar/core/netmsg"
+	"CircleWar/core/pickups"
+	"slices"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// the area a client gets snapshots of
+type View struct {
+	Center                geom.Vector2
+	HalfWidth, HalfHeight float32
+}
+
+// camera of the given size looking at center, kept inside the world the
+// way the client keeps its camera, and grown by margin on every side
+func CameraView(center geom.Vector2, camWidth, camHeight, worldWidth, worldHeight, margin float32) View {
+	halfW, halfH := camWidth/2, camHeight/2
+	return View{
+		Center:     geom.NewVector(clampAxis(center.X, halfW, worldWidth), clampAxis(center.Y, halfH, worldHeight)),
+		HalfWidth:  halfW + margin,
+		HalfHeight: halfH + margin,
+	}
+}
+
+func clampAxis(center, half, worldSize float32) float32 {
+	if 2*half >= worldSize {
+		return worldSize / 2
+	}
+	return max(half, min(center, worldSize-half))
+}
+
+// whether any part of a circle at pos is inside the view
+func (v View) Sees(pos geom.Vector2, radius float32) bool {
+	return pos.InsideSquare(
+		v.Center.X-v.HalfWidth, v.Center.Y-v.HalfHeight,
+		v.Center.X+v.HalfWidth, v.Center.Y+v.HalfHeight,
+		radius,
+	)
+}
+
+type entity struct {
+	player *netmsg.PlayerState
+	bullet *netmsg.BulletState
+	pickup *netmsg.PickupState
+	dist   float32
+	size   int // bytes it adds to the snapshot
+}
+
+// bytes a repeated message field adds to its parent
+func fieldSize(m proto.Message) int {
+	return 1 + protowire.SizeBytes(proto.Size(m))
+}
+
+func playerEntity(player *netmsg.PlayerState, center geom.Vector2) entity {
+	pbPlayer := netmsg.BuildPlayerState(player.Pos, netmsg.PlayerHealth(player.Health), player.Id, player.Aim, player.Effects, player.DashCooldown)
+	return entity{player: player, dist: player.Pos.DistTo(center), size: fieldSize(pbPlayer)}
+}
+
+func bulletEntity(bullet *netmsg.BulletState, center geom.Vector2) entity {
+	pbBullet := netmsg.BuildBulletState(bullet.Pos, bullet.Size, bullet.OwnerId, bullet.Weapon)
+	return entity{bullet: bullet, dist: bullet.Pos.DistTo(center), size: fieldSize(pbBullet)}
+}
+
+func pickupEntity(pickup *netmsg.PickupState, center geom.Vector2) entity {
+	pbPickup := netmsg.BuildPickupState(pickup.Pos, pickup.Kind, pickup.Id)
+	return entity{pickup: pickup, dist: pickup.Pos.DistTo(center), size: fieldSize(pbPickup)}
+}
+
+// closer first, players before bullets at the same distance
+func comparePriority(a, b entity) int {
+	if a.dist != b.dist {
+		if a.dist < b.dist {
+			return -1
+		}
+		return 1
+	}
+	if (a.player != nil) != (b.player != nil) {
+		if a.player != nil {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
+
+// builds the snapshot of world for the client seeing view and playing self
+// (0 for spectators). own player always goes in, the rest is ranked by
+// distance to the view's center and added while the serialized message
+// stays within budget bytes. players, bullets and pickups keep the world's order
+func Cull(world *netmsg.WorldState, view View, self uint32, budget int) *netmsg.WorldState {
+	culled := netmsg.NewWorldState(nil, nil, world.TickNum)
+	culled.GameplayChecksum = world.GameplayChecksum
+	// the world is wrapped in a GameMessage, its length prefix can grow a byte
+	size := proto.Size(culled.ToProtobuf()) + 1
+
+	keepPlayers := map[*netmsg.PlayerState]bool{}
+	keepBullets := map[*netmsg.BulletState]bool{}
+	keepPickups := map[*netmsg.PickupState]bool{}
+	candidates := []entity{}
+	for _, player := range world.Players {
+		if player.Id == self {
+			size += playerEntity(player, view.Center).size
+			keepPlayers[player] = true
+			continue
+		}
+		if view.Sees(player.Pos, hitboxes.PlayerSize(netmsg.PlayerHealth(player.Health))) {
+			candidates = append(candidates, playerEntity(player, view.Center))
+		}
+	}
+	for _, bullet := range world.Bullets {
+		if view.Sees(bullet.Pos, bullet.Size) {
+			candidates = append(candidates, bulletEntity(bullet, view.Center))
+		}
+	}
+	for _, pickup := range world.Pickups {
+		if view.Sees(pickup.Pos, pickups.Radius) {
+			candidates = append(candidates, pickupEntity(pickup, view.Center))
+		}
+	}
+	slices.SortStableFunc(candidates, comparePriority)
+
+	for _, ent := range candidates {
+		if size+ent.size > budget {
+			break
+		}
+		size += ent.size
+		switch {
+		case ent.player != nil:
+			keepPlayers[ent.player] = true
+		case ent.bullet != nil:
+			keepBullets[ent.bullet] = true
+		default:
+			keepPickups[ent.pickup] = true
+		}
+	}
+
+	for _, player := range world.Players {
+		if keepPlayers[player] {
+			culled.Players = append(culled.Players, player)
+		}
+	}
+	for _, bullet := range world.Bullets {
+		if keepBullets[bullet] {
+			culled.Bullets = append(culled.Bullets, bullet)
+		}
+	}
+	for _, pickup := range world.Pickups {
+		if keepPickups[pickup] {
+			culled.Pickups = append(culled.Pickups, pickup)
+		}
+	}
+	return culled
+}
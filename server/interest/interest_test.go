@@ -0,0 +1,107 @@
+package interest
+
+import (
+	"CircleWar/config"
+	"CircleWar/core/geom"
+	"CircleWar/core/netmsg"
+	"CircleWar/core/weapons"
+	"slices"
+	"testing"
+)
+
+func playerIds(ws *netmsg.WorldState) []uint32 {
+	ids := []uint32{}
+	for _, player := range ws.Players {
+		ids = append(ids, player.Id)
+	}
+	return ids
+}
+
+func TestCameraView(t *testing.T) {
+	tests := []struct {
+		name   string
+		center geom.Vector2
+		want   geom.Vector2
+	}{
+		{"middle", geom.NewVector(1000, 1000), geom.NewVector(1000, 1000)},
+		{"top left corner", geom.NewVector(10, 10), geom.NewVector(200, 100)},
+		{"bottom right corner", geom.NewVector(1990, 1990), geom.NewVector(1800, 1900)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			view := CameraView(test.center, 400, 200, 2000, 2000, 50)
+			if view.Center != test.want {
+				t.Errorf("got center %s want %s", view.Center, test.want)
+			}
+			if view.HalfWidth != 250 || view.HalfHeight != 150 {
+				t.Errorf("got half size %f x %f want 250 x 150", view.HalfWidth, view.HalfHeight)
+			}
+		})
+	}
+
+	view := CameraView(geom.NewVector(10, 10), 400, 200, 300, 100, 0)
+	if view.Center != geom.NewVector(150, 50) {
+		t.Errorf("world smaller than the camera: got center %s want it centered", view.Center)
+	}
+}
+
+func TestCull(t *testing.T) {
+	world := netmsg.NewWorldState([]*netmsg.PlayerState{
+		netmsg.NewPlayerState(1, geom.NewVector(100, 100), config.Default().InitialPlayerHealth, 0),
+		netmsg.NewPlayerState(2, geom.NewVector(300, 100), config.Default().InitialPlayerHealth, 0),
+		netmsg.NewPlayerState(3, geom.NewVector(200, 100), config.Default().InitialPlayerHealth, 0),
+		netmsg.NewPlayerState(4, geom.NewVector(5000, 5000), config.Default().InitialPlayerHealth, 0),
+	}, []*netmsg.BulletState{
+		netmsg.NewBulletState(2, geom.NewVector(150, 100), 10, weapons.Pistol),
+		netmsg.NewBulletState(2, geom.NewVector(5000, 100), 10, weapons.Pistol),
+	}, 7)
+	world.GameplayChecksum = config.Default().Checksum()
+	view := View{Center: geom.NewVector(100, 100), HalfWidth: 500, HalfHeight: 500}
+
+	full := Cull(world, view, 1, 1<<20)
+	if ids := playerIds(full); !slices.Equal(ids, []uint32{1, 2, 3}) {
+		t.Errorf("got players %v want the three in view", ids)
+	}
+	if len(full.Bullets) != 1 || full.Bullets[0].Pos.X != 150 {
+		t.Errorf("got bullets %v want the one in view", full.Bullets)
+	}
+	if full.TickNum != 7 || full.GameplayChecksum != world.GameplayChecksum {
+		t.Errorf("got tick %d and gameplay %d want 7 and %d", full.TickNum, full.GameplayChecksum, world.GameplayChecksum)
+	}
+	fullSize := size(t, full)
+
+	tests := []struct {
+		name        string
+		self        uint32
+		budget      int
+		wantPlayers []uint32
+		wantBullets int
+	}{
+		{"everything fits", 1, fullSize + 1, []uint32{1, 2, 3}, 1},
+		{"farthest player dropped", 1, fullSize - 1, []uint32{1, 3}, 1},
+		{"own player over budget", 2, 0, []uint32{2}, 0},
+		{"spectator", 0, 1 << 20, []uint32{1, 2, 3}, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			culled := Cull(world, view, test.self, test.budget)
+			if ids := playerIds(culled); !slices.Equal(ids, test.wantPlayers) {
+				t.Errorf("got players %v want %v", ids, test.wantPlayers)
+			}
+			if len(culled.Bullets) != test.wantBullets {
+				t.Errorf("got %d bullets want %d", len(culled.Bullets), test.wantBullets)
+			}
+			if test.budget > 0 && size(t, culled) > test.budget {
+				t.Errorf("snapshot is %d bytes, over the %d budget", size(t, culled), test.budget)
+			}
+		})
+	}
+}
+
+func size(t *testing.T, ws *netmsg.WorldState) int {
+	data, err := ws.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(data)
+}
@@ -3,273 +3,373 @@ package main
 import (
 	"CircleWar/config"
 	"CircleWar/core/geom"
-	"CircleWar/core/hitboxes"
+	"CircleWar/core/logging"
 	stypes "CircleWar/core/netmsg"
 	"CircleWar/core/network/gameConn"
+	"CircleWar/core/replay"
 	envdata "CircleWar/env/env_data"
 	envloader "CircleWar/env/env_loader"
+	"CircleWar/server/admin"
+	"CircleWar/server/interest"
+	"CircleWar/server/metrics"
+	"CircleWar/server/sim"
 	wstate "CircleWar/server/world_state"
 	"errors"
+	"flag"
 	"fmt"
-	"log"
-	"math"
+	"io/fs"
+	"maps"
 	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 )
 
-const (
-	port        = config.Port
-	bulletSpeed = config.BulletSpeed
-	playerSpeed = config.PlayerSpeed
+var (
+	netLog   = logging.For(logging.Net)
+	matchLog = logging.For(logging.Match)
+	// for what could come with every packet
+	packetLog = logging.Limited(netLog, 5*time.Second)
 )
 
-const (
-	ticksPerSecond = 60
-)
+func fatal(msg string, err error) {
+	matchLog.Error(msg, logging.Err(err))
+	os.Exit(1)
+}
 
 type clientInput struct {
 	addr    net.UDPAddr
 	gameMsg stypes.GameMessage
 }
 
-type TickResults struct {
-	playersDied []uint
-}
-
-func moveDelta(inputs map[stypes.Direction]bool, delta float32) geom.Vector2 {
-	dx, dy := 0.0, 0.0
-	for act := range inputs {
-		switch act {
-		case stypes.LEFT:
-			dx = -playerSpeed
-		case stypes.RIGHT:
-			dx = playerSpeed
-		case stypes.UP:
-			dy = -playerSpeed
-		case stypes.DOWN:
-			dy = playerSpeed
+// inputs and view updates that arrive while inputChan is full are dropped, a
+// slow loop shouldn't back up the socket and the next one replaces them anyway.
+// connects, pings and the like wait for room, they only come once
+func clientInputHandler(conn *gameConn.ServerConn, inputChan chan clientInput, dropped *metrics.Counter) {
+	for {
+		clientMsg, clientAddr, err := conn.Recieve()
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			packetLog.Debug("bad packet", logging.Err(err))
+			continue
+		}
+		switch clientMsg.(type) {
+		case *stypes.PlayerInput, *stypes.ViewUpdate:
 		default:
+			inputChan <- clientInput{clientAddr, clientMsg}
 			continue
 		}
+		select {
+		case inputChan <- clientInput{clientAddr, clientMsg}:
+		default:
+			dropped.Inc()
+			packetLog.Warn("dropped client message, the loop is behind", "type", stypes.Name(clientMsg))
+		}
 	}
+}
 
-	if dx != 0 && dy != 0 {
-		norm := math.Sqrt(2)
-		dx /= norm
-		dy /= norm
+// spectators don't count towards maxPlayers, they have no address in the world
+func handlePlayerConnect(sw *wstate.ServerWorld, maxPlayers int, req *stypes.ConnectRequest, addr net.UDPAddr) (*stypes.ConnectAck, error) {
+	if len(sw.AddressSnapshots()) >= maxPlayers {
+		return nil, errors.New("game is full")
 	}
-
-	return geom.NewVector(float32(dx)*delta, float32(dy)*delta)
+	newPlayer := sim.ConnectPlayer(sw, sw.NewPlayerId(), addr)
+	matchLog.Info("player joined", logging.Player(uint32(newPlayer.Id)), "addr", addr.String())
+	return stypes.NewConnectAck(uint32(newPlayer.Id), config.Current()), nil
 }
 
-func clientInputHandler(conn *gameConn.ServerConn, inputChan chan clientInput) {
-	for {
-		clientMsg, clientAddr, err := conn.Recieve()
-		if err != nil {
-			continue
-		}
-		inputChan <- clientInput{clientAddr, clientMsg}
+func handlePlayerReconnect(sw *wstate.ServerWorld, mode gameMode, req *stypes.ReconnectRequest, addr net.UDPAddr) (*stypes.ConnectAck, error) {
+	if mode == elimination {
+		return nil, errors.New("eliminated players can't respawn")
 	}
+	oldAddr := sw.GetAddress(uint(req.OldPlayerId))
+	if oldAddr.String() != addr.String() {
+		return nil, errors.New("didn't find player")
+	}
+	sw.RevivePlayer(uint(req.OldPlayerId))
+	matchLog.Info("player respawned", logging.Player(req.OldPlayerId))
+	return stypes.NewConnectAck(req.OldPlayerId, config.Current()), nil
 }
 
-func calculateHits(serverWorld *wstate.ServerWorld) []uint {
-	deadPlayers := []uint{}
-	for _, player := range serverWorld.PlayerSnapshots() {
-		for bulletId, bullet := range serverWorld.BulletSnapshots() {
-			if bullet.OwnerId == player.Id {
-				continue
-			}
-			playerRad := hitboxes.PlayerSize(player.Health())
-			bulletRad := hitboxes.BulletSize(player.Health())
-			playerPos := player.Pos
-			bulletPos := bullet.Pos
-
-			if playerPos.DistTo(bulletPos) < (playerRad+bulletRad)*0.9 {
-				player.ChangeHealth(-1)
-				if int(player.Health()) <= 0 {
-					fmt.Println("removing player")
-					deadPlayers = append(deadPlayers, player.Id)
-					serverWorld.RemovePlayerState(player.Id)
-				} else {
-					serverWorld.AddPlayerState(player)
-				}
-				serverWorld.RemoveBullet(bulletId)
-			}
+// in elimination the dead become spectators, they keep getting broadcasts
+// but leave the world's addresses so they don't hold a player slot
+func notifyDeadPlayers(sw *wstate.ServerWorld, conn *gameConn.ServerConn, mode gameMode, playerIds []uint) {
+	spectating := mode == elimination
+	for _, id := range playerIds {
+		matchLog.Info("player died", logging.Player(uint32(id)), logging.Tick(sw.Tick()), "spectating", spectating)
+		conn.SendTo(stypes.NewDeathNote(uint32(id), spectating), sw.GetAddress(id))
+		if spectating {
+			sw.RemovePlayerAddress(id)
 		}
 	}
-	return deadPlayers
 }
 
-func movePlayer(serverWorld *wstate.ServerWorld, id uint, delta geom.Vector2) {
-	player := serverWorld.Player(id)
-	playerSize := hitboxes.PlayerSize(player.Health())
-	player.Pos = player.Pos.Add(delta).Limited(
-		playerSize,
-		playerSize,
-		serverWorld.Width()-playerSize,
-		serverWorld.Height()-playerSize,
-	)
+// steps the world once, sends the result to every client and publishes it
+// to the readers of feed
+func runTick(sw *wstate.ServerWorld, conn *gameConn.ServerConn, recorder *replay.Recorder, feed *wstate.SnapshotFeed, m *match, views map[string]geom.Vector2, playerInputs map[uint]stypes.PlayerInput, dt time.Duration) {
+	tickResults := sim.Step(sw, playerInputs, dt)
+	netWorld := sim.NetworkWorldState(sw)
+	recorder.RecordTick(netWorld.TickNum, playerInputs, replay.Checksum(netWorld))
+	sw.NextTick()
+	notifyDeadPlayers(sw, conn, m.mode, tickResults.PlayersDied)
+	sendSnapshots(sw, conn, netWorld, views, m.cfg)
+	feed.Publish(sw)
 }
 
-func handleClientInputs(serverWorld *wstate.ServerWorld, clientInput *stypes.PlayerInput) {
-	playerId := uint(clientInput.PlayerId)
-	for _, action := range clientInput.Actions {
-		switch act := action.(type) {
-		case *stypes.MoveAction:
-			serverWorld.PlayerWants(playerId).MoveDirs[act.Dir] = true
-			break
-		case *stypes.ShootAction:
-			if serverWorld.DurSinceLastBullet(playerId) > time.Duration(config.BulletCooldownMS)*time.Millisecond {
-				playerState := serverWorld.Player(playerId)
-				serverWorld.StartPlayerBulletCD(playerId)
-				serverWorld.AddBulletState(wstate.NewBulletState(*playerState, act.Target))
-			}
-			break
-		default:
-			fmt.Println("unrecognized player action!")
-		}
+// sends every listener the part of the world around its player, spectators
+// around where their camera looks and anyone else around the world's center.
+// views of kicked or departed listeners are forgotten
+func sendSnapshots(sw *wstate.ServerWorld, conn *gameConn.ServerConn, netWorld *stypes.WorldState, views map[string]geom.Vector2, cfg config.GameConfig) {
+	playerIds := make(map[string]uint)
+	for id, addr := range sw.AddressSnapshots() {
+		playerIds[addr.String()] = id
 	}
-}
 
-func changeEntityStates(serverWorld *wstate.ServerWorld) {
-	for _, player := range serverWorld.PlayerSnapshots() {
-		playerId := player.Id
-		delta := moveDelta(serverWorld.PlayerWants(playerId).MoveDirs, 1.0/ticksPerSecond)
-		movePlayer(serverWorld, playerId, delta)
+	listeners := conn.Listeners()
+	listening := make(map[string]bool, len(listeners))
+	for _, addr := range listeners {
+		listening[addr.String()] = true
 	}
-}
+	maps.DeleteFunc(views, func(addr string, _ geom.Vector2) bool { return !listening[addr] })
 
-func updateWorldState(serverWorld *wstate.ServerWorld, playerInputs map[uint]stypes.PlayerInput) []uint {
-	for i, bullet := range serverWorld.BulletSnapshots() {
-		if time.Since(bullet.Born) > time.Duration(config.BulletTimeToLiveSec*float64(time.Second)) {
-			serverWorld.RemoveBullet(i)
+	for _, addr := range listeners {
+		center, ok := views[addr.String()]
+		if !ok {
+			center = geom.NewVector(sw.Width()/2, sw.Height()/2)
 		}
-
-		bullet.Pos = bullet.Pos.Add(
-			bullet.MoveDir.ScalarMult(bulletSpeed / ticksPerSecond),
-		)
-		if !bullet.Pos.InsideSquare(0, 0, serverWorld.Width(), serverWorld.Height(), config.InitialBulletSize) {
-			serverWorld.RemoveBullet(i)
+		id := playerIds[addr.String()]
+		if player, ok := sw.LookupPlayer(id); ok {
+			center = *player.Pos
 		}
+		view := interest.CameraView(center, float32(cfg.CameraWidth), float32(cfg.CameraHeight), sw.Width(), sw.Height(), cfg.InterestMargin)
+		conn.SendTo(interest.Cull(netWorld, view, uint32(id), cfg.SnapshotBudget), addr)
 	}
+}
 
-	for _, ci := range playerInputs {
-		// fmt.Println("input from pid:", ci.PlayerId)
-		clear(serverWorld.PlayerWants(uint(ci.PlayerId)).MoveDirs)
-
-		if serverWorld.HasPlayer(uint(ci.PlayerId)) {
-			handleClientInputs(serverWorld, &ci)
+// serves handler on addr until the server is shut down
+func serveHTTP(name, addr string, handler http.Handler) *http.Server {
+	server := &http.Server{Addr: addr, Handler: handler}
+	go func() {
+		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			fatal(name+" stopped", err)
 		}
-	}
-
-	changeEntityStates(serverWorld)
-
-	return calculateHits(serverWorld)
+	}()
+	matchLog.Info("serving "+name, "addr", addr)
+	return server
 }
 
-func buildNetworkWorldState(serverWorld *wstate.ServerWorld) *stypes.WorldState {
-	netWorld := &stypes.WorldState{}
-
-	players := serverWorld.PlayerSnapshots()
-	for _, player := range players {
-		netWorld.Players = append(netWorld.Players, stypes.NewPlayerState(
-			uint32(player.Id),
-			player.Pos,
-			float32(player.Health()),
-		))
+// serves the admin api on ADMIN_ADDR when it is set, requests need ADMIN_TOKEN.
+// the commands it returns are nil without the api, a nil channel never delivers
+func startAdmin(feed *wstate.SnapshotFeed) (*admin.Server, <-chan admin.Command, *http.Server, error) {
+	addr := envloader.GetEnv("ADMIN_ADDR", "")
+	if addr == "" {
+		return nil, nil, nil, nil
 	}
-
-	for _, bullet := range serverWorld.BulletSnapshots() {
-		netWorld.Bullets = append(netWorld.Bullets, stypes.NewBulletState(
-			uint32(bullet.OwnerId),
-			bullet.Pos,
-			bullet.Size,
-		))
+	token := envloader.GetEnv("ADMIN_TOKEN", "")
+	if token == "" {
+		return nil, nil, nil, errors.New("ADMIN_ADDR is set without ADMIN_TOKEN")
 	}
-
-	netWorld.TickNum = serverWorld.Tick()
-
-	return netWorld
+	srv := admin.New(token, feed)
+	return srv, srv.Commands(), serveHTTP("admin api", addr, srv.Handler()), nil
 }
 
-func handleWorldTick(sw *wstate.ServerWorld, playerInputs map[uint]stypes.PlayerInput) *TickResults {
-	playersDied := updateWorldState(sw, playerInputs)
-	return &TickResults{
-		playersDied: playersDied,
+// serves metrics on METRICS_ADDR when it is set
+func startMetrics(sm *serverMetrics) *http.Server {
+	addr := envloader.GetEnv("METRICS_ADDR", "")
+	if addr == "" {
+		return nil
 	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", sm.registry.Handler())
+	return serveHTTP("metrics", addr, mux)
 }
 
-func handlePlayerConnect(sw *wstate.ServerWorld, req *stypes.ConnectRequest, addr net.UDPAddr) *stypes.ConnectAck {
-	newPlayer := wstate.NewPlayerState(geom.NewVector(500, 500), addr)
-	sw.AddAddress(newPlayer.Id, addr)
-	fmt.Println("new player:", newPlayer)
-	sw.AddPlayerState(newPlayer)
-	return &stypes.ConnectAck{PlayerId: uint32(newPlayer.Id)}
-}
-
-func handlePlayerReconnect(sw *wstate.ServerWorld, req *stypes.ReconnectRequest, addr net.UDPAddr) (*stypes.ConnectAck, error) {
-	oldAddr := sw.GetAddress(uint(req.OldPlayerId))
-	if oldAddr.String() != addr.String() {
-		return nil, errors.New("didn't find player")
-	}
-	sw.RevivePlayer(uint(req.OldPlayerId))
-	connectAck := &stypes.ConnectAck{PlayerId: uint32(req.OldPlayerId)}
-	return connectAck, nil
-}
-
-func notifyDeadPlayers(sw *wstate.ServerWorld, conn *gameConn.ServerConn, playerIds []uint) {
-	for _, id := range playerIds {
-		conn.SendTo(stypes.NewDeathNote(uint32(id)), sw.GetAddress(id))
+// records to REPLAY_FILE when it is set
+func startRecorder(sw *wstate.ServerWorld, ticksPerSecond int) (*replay.Recorder, error) {
+	path := envloader.GetEnv("REPLAY_FILE", "")
+	if path == "" {
+		return nil, nil
 	}
+	matchLog.Info("recording replay", "path", path)
+	return replay.NewRecorder(path, replay.Header{
+		TicksPerSecond: uint32(ticksPerSecond),
+		Width:          sw.Width(),
+		Height:         sw.Height(),
+		Started:        time.Now(),
+		InitialWorld:   sim.NetworkWorldState(sw),
+		PickupSpawns:   sw.PickupSpawns(),
+		Gameplay:       config.Current(),
+	})
 }
 
 func main() {
-	envloader.LoadFile(envdata.EnvfilePath())
+	envOverride := flag.Bool("env-override", false, ".env values replace variables already set in the environment")
+	configFlags := config.AddFlags(flag.CommandLine)
+	flag.Parse()
+	envErr := envloader.Load(envdata.EnvfilePath(), envloader.Options{Override: *envOverride})
+	if err := logging.Setup(logging.ConfigFromEnv(envloader.GetEnv)); err != nil {
+		fatal("bad log config", err)
+	}
+	if errors.Is(envErr, fs.ErrNotExist) {
+		matchLog.Info("no .env file, using the defaults", "path", envdata.EnvfilePath())
+	} else if envErr != nil {
+		fatal("bad .env file", envErr)
+	}
+	cfg, err := configFlags.Load(envdata.ConfigPath(), envloader.GetEnv)
+	if err != nil {
+		fatal("bad config", err)
+	}
+	config.SetCurrent(cfg.Gameplay)
 	serverIp := envloader.GetEnv("SERVER_IP", "0.0.0.0")
+	mode, err := parseGameMode(envloader.GetEnv("GAME_MODE", "deathmatch"))
+	if err != nil {
+		fatal("bad GAME_MODE", err)
+	}
 
-	conn, err := gameConn.NewServerConn(net.ParseIP(serverIp), port)
+	conn, err := gameConn.NewServerConn(net.ParseIP(serverIp), cfg.Port)
 	if err != nil {
-		log.Fatal("whoops:", err)
+		fatal("can't listen", err)
 	}
-	defer conn.Close()
-	fmt.Printf("Listening on udp %s:%d\n", serverIp, port)
+	matchLog.Info("listening", "addr", fmt.Sprintf("%s:%d", serverIp, cfg.Port), "mode", mode.String(), logging.Room(admin.RoomName))
 
-	serverWorld := wstate.NewServerWorld()
-	clock := time.Tick(time.Second / ticksPerSecond)
+	serverWorld := wstate.NewServerWorld(cfg.WorldWidth, cfg.WorldHeight, sim.NewStepClock(0))
+	mapSpec := envloader.GetEnv("PICKUP_SPAWNS", "")
+	spawns, err := parsePickupSpawns(mapSpec, serverWorld.Width(), serverWorld.Height())
+	if err != nil {
+		fatal("bad PICKUP_SPAWNS", err)
+	}
+	serverWorld.SetPickupSpawns(spawns)
+	recorder, err := startRecorder(&serverWorld, cfg.TicksPerSecond)
+	if err != nil {
+		fatal("can't record replay", err)
+	}
+	// the world is only touched here, other goroutines read the feed
+	feed := &wstate.SnapshotFeed{}
+	feed.Publish(&serverWorld)
+	m := newMatch(mode, mapSpec, cfg)
+	adminSrv, adminCmds, adminHTTP, err := startAdmin(feed)
+	if err != nil {
+		fatal("can't serve the admin api", err)
+	}
+	if adminSrv != nil {
+		adminSrv.SetStatus(m.status())
+	}
+	sm := newServerMetrics(conn, feed)
+	metricsHTTP := startMetrics(sm)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	runner := sim.NewRunner(time.Second/time.Duration(cfg.TicksPerSecond), time.Now())
+	ticker := time.NewTicker(runner.Dt())
+	defer ticker.Stop()
+	pingTicker := time.NewTicker(time.Second)
+	defer pingTicker.Stop()
+	configPath, _ := configFlags.File(envdata.ConfigPath(), envloader.GetEnv)
+	watch := newConfigWatch(configPath)
+	watchTicker := time.NewTicker(time.Second)
+	defer watchTicker.Stop()
 	playerInputs := make(map[uint]stypes.PlayerInput)
+	spectatorViews := make(map[string]geom.Vector2) // by address
 
 	inputChan := make(chan clientInput, 10)
-	go clientInputHandler(conn, inputChan)
+	go clientInputHandler(conn, inputChan, sm.droppedInputs)
 
 	for {
 		select {
-		case <-clock:
-			tickResults := handleWorldTick(&serverWorld, playerInputs)
-			// fmt.Println("server world:", serverWorld)
-			netWorld := buildNetworkWorldState(&serverWorld)
-			serverWorld.NextTick()
-			notifyDeadPlayers(&serverWorld, conn, tickResults.playersDied)
-			// fmt.Println("sending tick#", netWorld.TickNum)
-			conn.Broadcast(netWorld)
-			playerInputs = make(map[uint]stypes.PlayerInput) // reset inputs for next tick
+		case sig := <-signals:
+			// a second signal kills the server the usual way
+			signal.Stop(signals)
+			shutdown(&serverWorld, conn, recorder, "server is shutting down ("+sig.String()+")",
+				envloader.GetEnv("SHUTDOWN_RECONNECT_HINT", ""), adminHTTP, metricsHTTP)
+			return
+		case now := <-ticker.C:
+			skipped := runner.Skipped
+			for range runner.Due(now) {
+				m.applyConfig(conn, recorder, serverWorld.Tick())
+				// a paused world stands still, inputs sent meanwhile are dropped
+				if !m.paused {
+					start := time.Now()
+					runTick(&serverWorld, conn, recorder, feed, m, spectatorViews, playerInputs, runner.Dt())
+					sm.observeTick(time.Since(start), runner.Dt())
+				}
+				playerInputs = make(map[uint]stypes.PlayerInput) // reset inputs for next tick
+			}
+			if runner.Skipped != skipped {
+				matchLog.Warn("server is behind", "skipped_ticks", runner.Skipped-skipped, logging.Tick(serverWorld.Tick()))
+			}
+		case now := <-pingTicker.C:
+			m.pingPlayers(&serverWorld, conn, now)
+		case <-watchTicker.C:
+			if !watch.changed() {
+				break
+			}
+			m.reloadConfigFile(func() (config.GameConfig, error) {
+				return configFlags.Load(envdata.ConfigPath(), envloader.GetEnv)
+			})
+			if adminSrv != nil {
+				adminSrv.SetStatus(m.status())
+			}
+		case cmd := <-adminCmds:
+			err := runAdminCommand(&serverWorld, conn, recorder, m, cmd)
+			matchLog.Info("admin command", "kind", cmd.Kind, logging.Player(uint32(cmd.PlayerId)), "arg", cmd.Arg, logging.Err(err))
+			cmd.Done(err)
+			adminSrv.SetStatus(m.status())
+			feed.Publish(&serverWorld)
 		case input := <-inputChan:
+			if m.isBanned(input.addr) {
+				switch input.gameMsg.(type) {
+				case *stypes.ConnectRequest, *stypes.SpectateRequest:
+					conn.SendTo(stypes.NewConnectReject("banned from the server"), input.addr)
+				}
+				break
+			}
 			switch in := input.gameMsg.(type) {
 			case *stypes.PlayerInput:
-				// fmt.Println("player input gotten:", *in)
 				playerInputs[uint(in.PlayerId)] = *in
 			case *stypes.ConnectRequest:
+				ackMsg, err := handlePlayerConnect(&serverWorld, m.cfg.MaxPlayers, in, input.addr)
+				if err != nil {
+					matchLog.Info("rejected player", "addr", input.addr.String(), logging.Err(err))
+					conn.SendTo(stypes.NewConnectReject(err.Error()), input.addr)
+					break
+				}
 				conn.AddListener(input.addr)
-				ackMsg := handlePlayerConnect(&serverWorld, in, input.addr)
+				// a spectator that joins is seen from its player now
+				delete(spectatorViews, input.addr.String())
+				recorder.RecordEvent(replay.PlayerEvent{Kind: replay.Connect, PlayerId: ackMsg.PlayerId, Addr: input.addr.String()})
 				conn.SendTo(ackMsg, input.addr)
 			case *stypes.ReconnectRequest:
-				fmt.Println("sending ack msg")
-				ackMsg, err := handlePlayerReconnect(&serverWorld, in, input.addr)
+				ackMsg, err := handlePlayerReconnect(&serverWorld, m.mode, in, input.addr)
 				if err != nil {
+					matchLog.Info("refused respawn", logging.Player(in.OldPlayerId), logging.Err(err))
 					break
 				}
+				recorder.RecordEvent(replay.PlayerEvent{Kind: replay.Reconnect, PlayerId: ackMsg.PlayerId, Addr: input.addr.String()})
 				conn.SendTo(ackMsg, input.addr)
+			case *stypes.SpectateRequest:
+				matchLog.Info("spectator joined", "addr", input.addr.String())
+				conn.AddListener(input.addr)
+				conn.SendTo(stypes.NewSpectateAck(config.Current()), input.addr)
+			case *stypes.ViewUpdate:
+				if conn.IsListener(input.addr) {
+					spectatorViews[input.addr.String()] = in.Center
+				}
+			case *stypes.GameplayRequest:
+				// only to clients we send to anyway, the answer is bigger than the ask
+				if conn.IsListener(input.addr) {
+					conn.SendTo(stypes.NewGameplayUpdate(config.Current()), input.addr)
+				}
+			case *stypes.Ping:
+				conn.SendTo(stypes.NewPong(in.Seq, in.SentNano), input.addr)
+			case *stypes.Pong:
+				if m.gotPong(&serverWorld, in, input.addr, time.Now()) && adminSrv != nil {
+					adminSrv.SetStatus(m.status())
+				}
 			default:
-				fmt.Println("player input didn't match any case", input)
+				packetLog.Warn("unexpected message", "type", stypes.Name(input.gameMsg), "addr", input.addr.String())
 			}
 		}
 	}
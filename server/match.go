@@ -0,0 +1,150 @@
+package main
+
+import (
+	"CircleWar/config"
+	stypes "CircleWar/core/netmsg"
+	"CircleWar/core/network/gameConn"
+	"CircleWar/core/replay"
+	"CircleWar/server/admin"
+	wstate "CircleWar/server/world_state"
+	"fmt"
+	"maps"
+	"net"
+	"slices"
+	"time"
+)
+
+// what the loop keeps besides the world, admins can change most of it
+type match struct {
+	cfg config.GameConfig
+	// what the config file, env vars and flags said when last loaded
+	loadedCfg config.GameConfig
+	// waiting for the next tick, see applyConfig
+	queuedCfg    *config.GameConfig
+	queuedSource string
+
+	mode gameMode
+	// PICKUP_SPAWNS of the map being played
+	mapSpec string
+	paused  bool
+	banned  map[string]bool // by ip
+	pings   map[uint]time.Duration
+	pingSeq uint32
+}
+
+func newMatch(mode gameMode, mapSpec string, cfg config.GameConfig) *match {
+	return &match{
+		cfg:       cfg,
+		loadedCfg: cfg,
+		mode:      mode,
+		mapSpec:   mapSpec,
+		banned:    make(map[string]bool),
+		pings:     make(map[uint]time.Duration),
+	}
+}
+
+func (m *match) isBanned(addr net.UDPAddr) bool {
+	return m.banned[addr.IP.String()]
+}
+
+func (m *match) status() admin.Status {
+	return admin.Status{
+		Mode:   m.mode.String(),
+		Map:    m.mapSpec,
+		Paused: m.paused,
+		Banned: slices.Sorted(maps.Keys(m.banned)),
+		Pings:  maps.Clone(m.pings),
+		// what the next tick plays by
+		Gameplay: m.nextConfig().Gameplay,
+	}
+}
+
+// pings every player, they answer with a Pong carrying our send time back
+func (m *match) pingPlayers(sw *wstate.ServerWorld, conn *gameConn.ServerConn, now time.Time) {
+	m.pingSeq++
+	for _, addr := range sw.AddressSnapshots() {
+		conn.SendTo(stypes.NewPing(m.pingSeq, now.UnixNano()), addr)
+	}
+}
+
+// round trip of a ping we sent, false when the pong isn't from a player
+func (m *match) gotPong(sw *wstate.ServerWorld, pong *stypes.Pong, from net.UDPAddr, now time.Time) bool {
+	for id, addr := range sw.AddressSnapshots() {
+		if addr.String() == from.String() {
+			m.pings[id] = now.Sub(time.Unix(0, pong.SentNano))
+			return true
+		}
+	}
+	return false
+}
+
+// tells the player why and takes it out of the world and the broadcasts.
+// dead players waiting to respawn still have an address and can be kicked
+func kickPlayer(sw *wstate.ServerWorld, conn *gameConn.ServerConn, recorder *replay.Recorder, m *match, id uint, reason string) error {
+	addr, ok := sw.LookupAddress(id)
+	if !ok {
+		return admin.InvalidError{Reason: "no such player"}
+	}
+	conn.SendTo(stypes.NewConnectReject(reason), addr)
+	sw.RemovePlayer(id)
+	sw.RemovePlayerAddress(id)
+	conn.RemoveListener(addr)
+	delete(m.pings, id)
+	recorder.RecordEvent(replay.PlayerEvent{Kind: replay.Disconnect, PlayerId: uint32(id), Addr: addr.String()})
+	return nil
+}
+
+// runs an admin command between ticks
+func runAdminCommand(sw *wstate.ServerWorld, conn *gameConn.ServerConn, recorder *replay.Recorder, m *match, cmd admin.Command) error {
+	switch cmd.Kind {
+	case admin.Kick:
+		return kickPlayer(sw, conn, recorder, m, cmd.PlayerId, "kicked from the server")
+	case admin.Ban:
+		// the player can leave between the request and the loop getting to it
+		addr, ok := sw.LookupAddress(cmd.PlayerId)
+		if !ok {
+			return admin.InvalidError{Reason: "no such player"}
+		}
+		m.banned[addr.IP.String()] = true
+		return kickPlayer(sw, conn, recorder, m, cmd.PlayerId, "banned from the server")
+	case admin.SetMode:
+		mode, err := parseGameMode(cmd.Arg)
+		if err != nil {
+			return admin.InvalidError{Reason: err.Error()}
+		}
+		m.mode = mode
+	case admin.SetMap:
+		// replays only know the spawns the match started with
+		if recorder != nil {
+			return admin.InvalidError{Reason: "can't change the map while recording a replay"}
+		}
+		spawns, err := parsePickupSpawns(cmd.Arg, sw.Width(), sw.Height())
+		if err != nil {
+			return admin.InvalidError{Reason: err.Error()}
+		}
+		sw.SetPickupSpawns(spawns)
+		for _, pickup := range sw.Pickups() {
+			sw.Remove(pickup.Handle)
+		}
+		m.mapSpec = cmd.Arg
+	case admin.Pause, admin.Resume:
+		m.paused = cmd.Kind == admin.Pause
+		text := "match resumed"
+		if m.paused {
+			text = "match paused"
+		}
+		conn.Broadcast(stypes.NewServerMessage(text))
+	case admin.SetConfig:
+		if err := m.setConfig(cmd.Arg); err != nil {
+			return admin.InvalidError{Reason: err.Error()}
+		}
+	case admin.Broadcast:
+		if cmd.Arg == "" {
+			return admin.InvalidError{Reason: "empty message"}
+		}
+		return conn.Broadcast(stypes.NewServerMessage(cmd.Arg))
+	default:
+		return fmt.Errorf("unknown admin command %d", int(cmd.Kind))
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"CircleWar/core/network/gameConn"
+	"CircleWar/server/metrics"
+	wstate "CircleWar/server/world_state"
+	"time"
+)
+
+// the loop's own metrics, everything else is read when scraped
+type serverMetrics struct {
+	registry        *metrics.Registry
+	tickDuration    *metrics.Histogram
+	ticksOverBudget *metrics.Counter
+	droppedInputs   *metrics.Counter
+}
+
+func newServerMetrics(conn *gameConn.ServerConn, feed *wstate.SnapshotFeed) *serverMetrics {
+	reg := metrics.NewRegistry()
+	sm := &serverMetrics{
+		registry: reg,
+		tickDuration: reg.Histogram("circlewar_tick_duration_seconds", "time it took to step the world and send it",
+			[]float64{0.0005, 0.001, 0.002, 0.004, 0.008, 0.016, 0.033, 0.066}),
+		ticksOverBudget: reg.Counter("circlewar_ticks_over_budget_total", "ticks that took longer than the fixed step"),
+		droppedInputs:   reg.Counter("circlewar_dropped_inputs_total", "inputs and view updates dropped because the loop was behind"),
+	}
+
+	reg.GaugeFunc("circlewar_connected_clients", "players and spectators getting snapshots", func() []metrics.Sample {
+		return []metrics.Sample{{Value: float64(len(conn.Listeners()))}}
+	})
+	reg.GaugeFunc("circlewar_active_bullets", "bullets in flight at the end of the last tick", func() []metrics.Sample {
+		snap, _ := feed.Latest()
+		return []metrics.Sample{{Value: float64(len(snap.Bullets))}}
+	})
+	reg.CounterFunc("circlewar_packets_total", "packets by direction and message type", func() []metrics.Sample {
+		return trafficSamples(conn, func(stats gameConn.ConnStats) (uint64, uint64) {
+			return stats.PacketsIn, stats.PacketsOut
+		})
+	})
+	reg.CounterFunc("circlewar_bytes_total", "bytes by direction and message type", func() []metrics.Sample {
+		return trafficSamples(conn, func(stats gameConn.ConnStats) (uint64, uint64) {
+			return stats.BytesIn, stats.BytesOut
+		})
+	})
+	reg.CounterFunc("circlewar_deserialize_errors_total", "packets that weren't game messages", func() []metrics.Sample {
+		return []metrics.Sample{{Value: float64(conn.DeserializeErrors())}}
+	})
+	return sm
+}
+
+// an in and an out sample of every message type that was seen
+func trafficSamples(conn *gameConn.ServerConn, inOut func(gameConn.ConnStats) (uint64, uint64)) []metrics.Sample {
+	samples := []metrics.Sample{}
+	for msgType, stats := range conn.TypeStats() {
+		in, out := inOut(stats)
+		samples = append(samples,
+			metrics.Sample{Labels: metrics.Labels{"direction": "in", "type": msgType}, Value: float64(in)},
+			metrics.Sample{Labels: metrics.Labels{"direction": "out", "type": msgType}, Value: float64(out)},
+		)
+	}
+	return samples
+}
+
+func (sm *serverMetrics) observeTick(took, budget time.Duration) {
+	sm.tickDuration.Observe(took.Seconds())
+	if took > budget {
+		sm.ticksOverBudget.Inc()
+	}
+}
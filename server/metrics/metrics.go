@@ -0,0 +1,189 @@
+// metrics of the server in the prometheus text format, written out by hand
+// so scraping needs nothing but net/http
+package metrics
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+type kind string
+
+const (
+	counter   kind = "counter"
+	gauge     kind = "gauge"
+	histogram kind = "histogram"
+)
+
+type Labels map[string]string
+
+// a value with its labels, for metrics read when they are scraped
+type Sample struct {
+	Labels Labels
+	Value  float64
+}
+
+type metric struct {
+	name, help string
+	kind       kind
+	samples    func() []Sample // counters and gauges
+	hist       *Histogram
+}
+
+type Registry struct {
+	mu      sync.Mutex
+	metrics []metric
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) add(m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics = append(r.metrics, m)
+}
+
+// counts up from 0, safe to use from any goroutine
+type Counter struct {
+	n atomic.Uint64
+}
+
+func (c *Counter) Inc() {
+	c.n.Add(1)
+}
+
+func (c *Counter) Add(n uint64) {
+	c.n.Add(n)
+}
+
+func (c *Counter) Value() uint64 {
+	return c.n.Load()
+}
+
+func (r *Registry) Counter(name, help string) *Counter {
+	c := &Counter{}
+	r.CounterFunc(name, help, func() []Sample {
+		return []Sample{{Value: float64(c.Value())}}
+	})
+	return c
+}
+
+// a counter whose values are read when it is scraped, like traffic totals
+// something else already keeps
+func (r *Registry) CounterFunc(name, help string, samples func() []Sample) {
+	r.add(metric{name: name, help: help, kind: counter, samples: samples})
+}
+
+func (r *Registry) GaugeFunc(name, help string, samples func() []Sample) {
+	r.add(metric{name: name, help: help, kind: gauge, samples: samples})
+}
+
+// counts observations into buckets by upper bound, safe to use from any goroutine
+type Histogram struct {
+	mu     sync.Mutex
+	bounds []float64
+	counts []uint64 // per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+func (h *Histogram) Observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	i, _ := slices.BinarySearch(h.bounds, v)
+	if i < len(h.counts) {
+		h.counts[i]++
+	}
+	h.count++
+	h.sum += v
+}
+
+// bounds are the buckets' upper bounds, the +Inf bucket is added on its own
+func (r *Registry) Histogram(name, help string, bounds []float64) *Histogram {
+	bounds = slices.Sorted(slices.Values(bounds))
+	h := &Histogram{bounds: bounds, counts: make([]uint64, len(bounds))}
+	r.add(metric{name: name, help: help, kind: histogram, hist: h})
+	return h
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// {a="1",b="2"} sorted by name, nothing for no labels
+func formatLabels(labels Labels) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = fmt.Sprintf(`%s="%s"`, name, labelEscaper.Replace(labels[name]))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func (h *Histogram) write(w io.Writer, name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var cumulative uint64
+	for i, bound := range h.bounds {
+		cumulative += h.counts[i]
+		fmt.Fprintf(w, "%s_bucket{le=\"%s\"} %d\n", name, formatValue(bound), cumulative)
+	}
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", name, h.count)
+	fmt.Fprintf(w, "%s_sum %s\n", name, formatValue(h.sum))
+	fmt.Fprintf(w, "%s_count %d\n", name, h.count)
+}
+
+// writes every metric in the order they were registered
+func (r *Registry) Write(w io.Writer) {
+	r.mu.Lock()
+	metrics := slices.Clone(r.metrics)
+	r.mu.Unlock()
+
+	for _, m := range metrics {
+		fmt.Fprintf(w, "# HELP %s %s\n", m.name, m.help)
+		fmt.Fprintf(w, "# TYPE %s %s\n", m.name, m.kind)
+		if m.hist != nil {
+			m.hist.write(w, m.name)
+			continue
+		}
+		samples := m.samples()
+		slices.SortFunc(samples, func(a, b Sample) int {
+			return cmp.Compare(formatLabels(a.Labels), formatLabels(b.Labels))
+		})
+		for _, s := range samples {
+			fmt.Fprintf(w, "%s%s %s\n", m.name, formatLabels(s.Labels), formatValue(s.Value))
+		}
+	}
+}
+
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.Write(w)
+	})
+}
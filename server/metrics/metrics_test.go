@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	reg := NewRegistry()
+	c := reg.Counter("drops_total", "dropped things")
+	c.Inc()
+	c.Add(2)
+	reg.GaugeFunc("queue", "things waiting", func() []Sample {
+		return []Sample{
+			{Labels{"type": "pong", "direction": "out"}, 2},
+			{Labels{"type": `say "hi"`, "direction": "in"}, 0.5},
+		}
+	})
+	h := reg.Histogram("took_seconds", "how long", []float64{0.1, 0.01})
+	for _, v := range []float64{0.005, 0.01, 0.05, 3} {
+		h.Observe(v)
+	}
+
+	want := `# HELP drops_total dropped things
+# TYPE drops_total counter
+drops_total 3
+# HELP queue things waiting
+# TYPE queue gauge
+queue{direction="in",type="say \"hi\""} 0.5
+queue{direction="out",type="pong"} 2
+# HELP took_seconds how long
+# TYPE took_seconds histogram
+took_seconds_bucket{le="0.01"} 2
+took_seconds_bucket{le="0.1"} 3
+took_seconds_bucket{le="+Inf"} 4
+took_seconds_sum 3.065
+took_seconds_count 4
+`
+	var got strings.Builder
+	reg.Write(&got)
+	if got.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", got.String(), want)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	reg := NewRegistry()
+	reg.Counter("hits_total", "hits").Inc()
+	rec := httptest.NewRecorder()
+	reg.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("got content type %q", ct)
+	}
+	if !strings.Contains(rec.Body.String(), "hits_total 1\n") {
+		t.Errorf("got body %q", rec.Body.String())
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import "fmt"
+
+type gameMode int
+
+const (
+	// dead players reconnect and respawn
+	deathmatch gameMode = iota
+	// dead players spectate for the rest of the match
+	elimination
+)
+
+var gameModeNames = map[gameMode]string{
+	deathmatch:  "deathmatch",
+	elimination: "elimination",
+}
+
+func (m gameMode) String() string {
+	return gameModeNames[m]
+}
+
+func parseGameMode(name string) (gameMode, error) {
+	for mode, modeName := range gameModeNames {
+		if modeName == name {
+			return mode, nil
+		}
+	}
+	return deathmatch, fmt.Errorf("unknown game mode '%s'", name)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"CircleWar/config"
+	"CircleWar/core/logging"
+	stypes "CircleWar/core/netmsg"
+	"CircleWar/core/network/gameConn"
+	"CircleWar/core/replay"
+	"fmt"
+	"os"
+	"time"
+)
+
+// the config file, polled by the loop for edits
+type configWatch struct {
+	path    string
+	modTime time.Time
+	size    int64
+}
+
+func newConfigWatch(path string) *configWatch {
+	w := &configWatch{path: path}
+	w.changed()
+	return w
+}
+
+// whether the file was written since the last call, a missing file doesn't
+// count as a change
+func (w *configWatch) changed() bool {
+	info, err := os.Stat(w.path)
+	if err != nil || info.ModTime().Equal(w.modTime) && info.Size() == w.size {
+		return false
+	}
+	w.modTime, w.size = info.ModTime(), info.Size()
+	return true
+}
+
+// the config the next tick plays by
+func (m *match) nextConfig() config.GameConfig {
+	if m.queuedCfg != nil {
+		return *m.queuedCfg
+	}
+	return m.cfg
+}
+
+// queues next for the next tick boundary, changes that come before it
+// build on it
+func (m *match) queueConfig(next config.GameConfig, source string) error {
+	if err := next.Validate(); err != nil {
+		return err
+	}
+	if next == m.nextConfig() {
+		return nil
+	}
+	m.queuedCfg, m.queuedSource = &next, source
+	return nil
+}
+
+// takes the gameplay settings that changed in the config file since it was
+// last loaded, so admin changes to others stay. what else changed in it
+// waits for a restart
+func (m *match) reloadConfigFile(load func() (config.GameConfig, error)) {
+	next, err := load()
+	if err != nil {
+		matchLog.Warn("ignoring config file change", logging.Err(err))
+		return
+	}
+	changes := config.Diff(m.loadedCfg, next)
+	reloaded, fixed := m.nextConfig().Reload(m.nextConfig().Apply(changes))
+	if err := m.queueConfig(reloaded, "file"); err != nil {
+		// the next edit is compared to what was loaded before this one
+		matchLog.Warn("ignoring config file change", logging.Err(err))
+		return
+	}
+	m.loadedCfg = next
+	for _, change := range fixed {
+		matchLog.Warn("config change needs a restart", "change", change.String())
+	}
+}
+
+// settings changed by an admin, only the gameplay can change on a running server
+func (m *match) setConfig(settings string) error {
+	next, err := m.nextConfig().WithJSON([]byte(settings))
+	if err != nil {
+		return fmt.Errorf("bad settings: %w", err)
+	}
+	reloaded, fixed := m.nextConfig().Reload(next)
+	if len(fixed) > 0 {
+		return fmt.Errorf("%s can't change while the server runs", fixed[0].Name)
+	}
+	return m.queueConfig(reloaded, "admin")
+}
+
+// makes the queued config current between two ticks. clients get the new
+// gameplay, the replay records it and the log shows what changed
+func (m *match) applyConfig(conn *gameConn.ServerConn, recorder *replay.Recorder, tick uint32) {
+	if m.queuedCfg == nil {
+		return
+	}
+	changes := config.Diff(m.cfg, *m.queuedCfg)
+	m.cfg, m.queuedCfg = *m.queuedCfg, nil
+	config.SetCurrent(m.cfg.Gameplay)
+	recorder.RecordGameplay(m.cfg.Gameplay)
+	conn.Broadcast(stypes.NewGameplayUpdate(m.cfg.Gameplay))
+
+	attrs := []any{"source", m.queuedSource, logging.Tick(tick)}
+	for _, change := range changes {
+		attrs = append(attrs, change.Name, fmt.Sprintf("%v -> %v", change.Old, change.New))
+	}
+	matchLog.Info("gameplay changed", attrs...)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"CircleWar/core/logging"
+	stypes "CircleWar/core/netmsg"
+	"CircleWar/core/network/gameConn"
+	"CircleWar/core/replay"
+	wstate "CircleWar/server/world_state"
+	"context"
+	"net/http"
+	"time"
+)
+
+// how long the http servers get to finish their requests
+const httpDrainTime = 2 * time.Second
+
+// called once the loop stopped taking messages: tells every client why the
+// server goes away and where to go instead, finishes the replay and closes
+// the connections. servers can be nil
+func shutdown(sw *wstate.ServerWorld, conn *gameConn.ServerConn, recorder *replay.Recorder, reason, reconnectHint string, servers ...*http.Server) {
+	matchLog.Info("shutting down", "reason", reason, logging.Tick(sw.Tick()), "players", len(sw.AddressSnapshots()))
+
+	note := stypes.NewServerShutdown(reason, reconnectHint)
+	for _, addr := range conn.Listeners() {
+		if err := conn.SendTo(note, addr); err != nil {
+			netLog.Warn("can't tell client about the shutdown", "addr", addr.String(), logging.Err(err))
+		}
+	}
+
+	if err := recorder.Close(); err != nil {
+		matchLog.Error("can't finish the replay", logging.Err(err))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), httpDrainTime)
+	defer cancel()
+	for _, server := range servers {
+		if server == nil {
+			continue
+		}
+		if err := server.Shutdown(ctx); err != nil {
+			matchLog.Warn("http server didn't stop cleanly", "addr", server.Addr, logging.Err(err))
+		}
+	}
+
+	if err := conn.Close(); err != nil {
+		netLog.Warn("can't close the connection", logging.Err(err))
+	}
+	matchLog.Info("server stopped")
+}
@@ -0,0 +1,25 @@
+package sim
+
+import (
+	wstate "CircleWar/server/world_state"
+	"time"
+)
+
+type Clock = wstate.Clock
+
+// simulation time that only moves when a Step advances it
+type StepClock struct {
+	now time.Duration
+}
+
+func NewStepClock(start time.Duration) *StepClock {
+	return &StepClock{start}
+}
+
+func (c *StepClock) Now() time.Duration {
+	return c.now
+}
+
+func (c *StepClock) Advance(dt time.Duration) {
+	c.now += dt
+}
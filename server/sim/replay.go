@@ -0,0 +1,133 @@
+package sim
+
+import (
+	"CircleWar/config"
+	stypes "CircleWar/core/netmsg"
+	"CircleWar/core/pickups"
+	"CircleWar/core/replay"
+	wstate "CircleWar/server/world_state"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// re-simulates a recorded match through the same Step the server runs
+type Replayer struct {
+	World wstate.ServerWorld
+	dt    time.Duration
+	// what the recorded match played by at the current tick, replayers at
+	// other ticks can play by something else
+	gameplay config.Gameplay
+}
+
+// builds the world the replay starts from, and makes the replay's gameplay
+// the current one since the simulation plays by it. Apply makes it current
+// again before every tick
+func NewReplayer(header replay.Header) (*Replayer, error) {
+	if header.TicksPerSecond == 0 {
+		return nil, errors.New("replay has no tick rate")
+	}
+	if err := header.Gameplay.Validate(); err != nil {
+		return nil, fmt.Errorf("replay has a bad gameplay config: %w", err)
+	}
+	config.SetCurrent(header.Gameplay)
+	dt := time.Second / time.Duration(header.TicksPerSecond)
+	initial := header.InitialWorld
+	clock := NewStepClock(time.Duration(initial.TickNum) * dt)
+	serverWorld := wstate.NewServerWorld(header.Width, header.Height, clock)
+	serverWorld.SetTick(initial.TickNum)
+	serverWorld.SetPickupSpawns(header.PickupSpawns)
+
+	if len(initial.Bullets) > 0 {
+		return nil, errors.New("replays starting with bullets in flight are not supported")
+	}
+	if len(initial.Pickups) > 0 {
+		return nil, errors.New("replays starting with pickups on the ground are not supported")
+	}
+	for _, player := range initial.Players {
+		ps := serverWorld.SpawnPlayer(uint(player.Id), player.Pos, net.UDPAddr{})
+		ps.ChangeHealth(int(stypes.PlayerHealth(player.Health) - ps.Health()))
+		ps.Aim = player.Aim
+		ps.LastDash = serverWorld.Now() + player.DashCooldown - config.Current().DashCooldown()
+		for _, effect := range player.Effects {
+			if effect.Kind < 0 || effect.Kind >= pickups.NumKinds {
+				return nil, fmt.Errorf("player %d has an effect of unknown kind %d", player.Id, effect.Kind)
+			}
+			ps.EffectEnds[effect.Kind] = serverWorld.Now() + effect.Remaining
+		}
+	}
+	return &Replayer{serverWorld, dt, header.Gameplay}, nil
+}
+
+// independent copy of the replayer at the same tick
+func (r *Replayer) Clone() *Replayer {
+	return &Replayer{r.World.Clone(NewStepClock(r.World.Now())), r.dt, r.gameplay}
+}
+
+func applyEvent(serverWorld *wstate.ServerWorld, ev replay.PlayerEvent) error {
+	addr, err := net.ResolveUDPAddr("udp", ev.Addr)
+	if err != nil {
+		return err
+	}
+	id := uint(ev.PlayerId)
+
+	switch ev.Kind {
+	case replay.Connect:
+		ConnectPlayer(serverWorld, id, *addr)
+	case replay.Reconnect:
+		serverWorld.RevivePlayer(id)
+	case replay.Disconnect:
+		serverWorld.RemovePlayer(id)
+		serverWorld.RemovePlayerAddress(id)
+	default:
+		return fmt.Errorf("unknown replay event %d", ev.Kind)
+	}
+	return nil
+}
+
+// applies a recorded tick the way the server loop did: events and gameplay
+// changes in the order they happened, then the step. returns the world the
+// tick resulted in and moves on to the next tick
+func (r *Replayer) Apply(tick replay.Tick) (TickResults, *stypes.WorldState, error) {
+	serverWorld := &r.World
+	if tick.TickNum != serverWorld.Tick() {
+		return TickResults{}, nil, fmt.Errorf("recorded tick %d but the world is on tick %d", tick.TickNum, serverWorld.Tick())
+	}
+	after := 0
+	for _, change := range tick.GameplayChanges {
+		if change.AfterEvents < after || change.AfterEvents > len(tick.Events) {
+			return TickResults{}, nil, fmt.Errorf("tick %d changes gameplay after event %d of %d", tick.TickNum, change.AfterEvents, len(tick.Events))
+		}
+		if err := change.Gameplay.Validate(); err != nil {
+			return TickResults{}, nil, fmt.Errorf("tick %d has a bad gameplay config: %w", tick.TickNum, err)
+		}
+		after = change.AfterEvents
+	}
+
+	config.SetCurrent(r.gameplay)
+	changes := tick.GameplayChanges
+	for i := 0; ; i++ {
+		for len(changes) > 0 && changes[0].AfterEvents == i {
+			r.gameplay = changes[0].Gameplay
+			config.SetCurrent(r.gameplay)
+			changes = changes[1:]
+		}
+		if i == len(tick.Events) {
+			break
+		}
+		if err := applyEvent(serverWorld, tick.Events[i]); err != nil {
+			return TickResults{}, nil, err
+		}
+	}
+
+	inputs := make(map[uint]stypes.PlayerInput)
+	for _, input := range tick.Inputs {
+		inputs[uint(input.PlayerId)] = *input
+	}
+	results := Step(serverWorld, inputs, r.dt)
+	netWorld := NetworkWorldState(serverWorld)
+	serverWorld.NextTick()
+
+	return results, netWorld, nil
+}
@@ -0,0 +1,39 @@
+package sim
+
+import "time"
+
+// most steps a single Due call hands out, anything later than that is dropped
+// so a long stall does not turn into a burst of fast forwarded ticks
+const maxCatchUpSteps = 5
+
+// turns wall clock time into fixed steps, the caller runs Step once for
+// every step that is due
+type Runner struct {
+	dt      time.Duration
+	next    time.Time
+	Skipped uint64
+}
+
+func NewRunner(dt time.Duration, start time.Time) *Runner {
+	return &Runner{dt: dt, next: start.Add(dt)}
+}
+
+func (r *Runner) Dt() time.Duration {
+	return r.dt
+}
+
+// number of steps that came due since the last call. when ticks ran late
+// it is more than one, so the simulation catches up with the wall clock
+func (r *Runner) Due(now time.Time) int {
+	steps := 0
+	for !now.Before(r.next) && steps < maxCatchUpSteps {
+		steps++
+		r.next = r.next.Add(r.dt)
+	}
+	if !now.Before(r.next) {
+		behind := now.Sub(r.next)/r.dt + 1
+		r.Skipped += uint64(behind)
+		r.next = r.next.Add(behind * r.dt)
+	}
+	return steps
+}
@@ -0,0 +1,397 @@
+package sim
+
+import (
+	"CircleWar/config"
+	"CircleWar/core/damage"
+	"CircleWar/core/geom"
+	"CircleWar/core/hitboxes"
+	"CircleWar/core/logging"
+	stypes "CircleWar/core/netmsg"
+	"CircleWar/core/pickups"
+	"CircleWar/core/weapons"
+	wstate "CircleWar/server/world_state"
+	"fmt"
+	"maps"
+	"math"
+	"net"
+	"slices"
+	"time"
+)
+
+// the simulation is deterministic: it only reads time through the world's
+// Clock, walks players, bullets, pickups and inputs in id order and rolls
+// pickups from the tick number, so a replay of the same events and inputs
+// ends up in the same world
+
+var log = logging.For(logging.Sim)
+
+// for actions that could come with every input
+var inputLog = logging.Limited(log, 5*time.Second)
+
+var spawnPos = geom.NewVector(500, 500)
+
+type TickResults struct {
+	PlayersDied []uint
+	Hits        []damage.Hit
+}
+
+func finite(f float32) bool {
+	return !math.IsNaN(float64(f)) && !math.IsInf(float64(f), 0)
+}
+
+// wanted movement of length at most 1, from the enum directions and the
+// analog vector together
+func moveDir(wants *wstate.PlayerWants) geom.Vector2 {
+	dx, dy := float64(wants.Move.X), float64(wants.Move.Y)
+	if !finite(wants.Move.X) || !finite(wants.Move.Y) {
+		dx, dy = 0, 0
+	}
+
+	// diagonals of the enum path come out as length 1 below
+	if wants.MoveDirs[stypes.LEFT] {
+		dx -= 1
+	}
+	if wants.MoveDirs[stypes.RIGHT] {
+		dx += 1
+	}
+	if wants.MoveDirs[stypes.UP] {
+		dy -= 1
+	}
+	if wants.MoveDirs[stypes.DOWN] {
+		dy += 1
+	}
+
+	if length := math.Hypot(dx, dy); length > 1 {
+		dx /= length
+		dy /= length
+	}
+	return geom.NewVector(float32(dx), float32(dy))
+}
+
+func moveDelta(wants *wstate.PlayerWants, speed, delta float32) geom.Vector2 {
+	dir := moveDir(wants)
+	return geom.NewVector(dir.X*speed*delta, dir.Y*speed*delta)
+}
+
+// damages players with the bullets that reached them, returns who died and
+// every hit
+func calculateHits(serverWorld *wstate.ServerWorld) ([]uint, []damage.Hit) {
+	deadPlayers := []uint{}
+	hits := []damage.Hit{}
+	now := serverWorld.Now()
+	for _, player := range serverWorld.Players() {
+		// bullets fly through dashing players
+		if player.Invulnerable(now) {
+			continue
+		}
+		for _, bullet := range serverWorld.Bullets() {
+			if bullet.Owner == player.Handle || !serverWorld.Alive(bullet.Handle) {
+				continue
+			}
+			weapon := weapons.Get(bullet.Weapon)
+			result := damage.Resolve(
+				damage.Shot{
+					Weapon:    weapon,
+					Size:      bullet.Size,
+					Travelled: weapon.Speed * float32((now - bullet.Born).Seconds()),
+				},
+				damage.Target{
+					Radius:   hitboxes.PlayerSize(player.Health()),
+					Shielded: player.HasEffect(pickups.Shield, now),
+					Armor:    player.Armor(now),
+				},
+			)
+			if player.Pos.DistTo(*bullet.Pos) >= result.Radius {
+				continue
+			}
+
+			hits = append(hits, result.Hit(bullet.OwnerId, player.Id, bullet.Weapon))
+			serverWorld.Remove(bullet.Handle)
+			if result.Blocked {
+				continue
+			}
+			player.ChangeHealth(-result.Damage)
+			if int(player.Health()) <= 0 {
+				log.Debug("player killed", logging.Player(uint32(player.Id)), "by", bullet.OwnerId, logging.Tick(serverWorld.Tick()))
+				deadPlayers = append(deadPlayers, player.Id)
+				// bullets of a life that's over don't score for the next one
+				if _, ok := serverWorld.PlayerByHandle(bullet.Owner); ok {
+					serverWorld.AddKill(bullet.OwnerId)
+				}
+				serverWorld.RemovePlayer(player.Id)
+				break
+			}
+		}
+	}
+	return deadPlayers, hits
+}
+
+func movePlayer(serverWorld *wstate.ServerWorld, player wstate.Player, delta geom.Vector2) {
+	playerSize := hitboxes.PlayerSize(player.Health())
+	*player.Pos = player.Pos.Add(delta).Limited(
+		playerSize,
+		playerSize,
+		serverWorld.Width()-playerSize,
+		serverWorld.Height()-playerSize,
+	)
+}
+
+func handleClientInputs(serverWorld *wstate.ServerWorld, clientInput *stypes.PlayerInput) {
+	playerId := uint(clientInput.PlayerId)
+	player, ok := serverWorld.LookupPlayer(playerId)
+	if !ok {
+		return
+	}
+	wantsDash := false
+	for _, action := range clientInput.Actions {
+		switch act := action.(type) {
+		case *stypes.MoveAction:
+			serverWorld.PlayerWants(playerId).MoveDirs[act.Dir] = true
+		case *stypes.AnalogAction:
+			serverWorld.PlayerWants(playerId).Move = act.Move
+			if finite(act.Aim) {
+				player.Aim = act.Aim
+			}
+		case *stypes.ShootAction:
+			shoot(serverWorld, player, act)
+		case *stypes.DashAction:
+			wantsDash = true
+		default:
+			inputLog.Warn("unrecognized player action", logging.Player(clientInput.PlayerId), "action", fmt.Sprintf("%T", action))
+		}
+	}
+	// after the loop, the dash goes where the movement of the whole input points
+	if wantsDash {
+		dash(serverWorld, player)
+	}
+}
+
+// starts a dash the way the player moves, or aims when standing still, once
+// the last dash has cooled down
+func dash(serverWorld *wstate.ServerWorld, player wstate.Player) {
+	if player.DashCooldownLeft(serverWorld.Now()) > 0 {
+		return
+	}
+	dir := moveDir(serverWorld.PlayerWants(player.Id))
+	if dir == (geom.Vector2{}) {
+		aim := float64(player.Aim)
+		dir = geom.NewVector(float32(math.Cos(aim)), float32(math.Sin(aim)))
+	}
+	serverWorld.StartPlayerDash(player, geom.NewDir(dir))
+}
+
+// fires the weapon in the action's slot once the last shot has cooled down
+func shoot(serverWorld *wstate.ServerWorld, playerState wstate.Player, act *stypes.ShootAction) {
+	weapon, ok := weapons.InSlot(act.Slot)
+	if !ok || serverWorld.DurSinceLastBullet(playerState) <= playerState.ShotCooldown {
+		return
+	}
+	now := serverWorld.Now()
+	cooldown := weapon.Cooldown
+	if playerState.HasEffect(pickups.RapidFire, now) {
+		cooldown = time.Duration(float64(cooldown) * pickups.CooldownFactor)
+	}
+	serverWorld.StartPlayerBulletCD(playerState, cooldown)
+
+	aim := geom.NewDir(act.Target.Sub(*playerState.Pos))
+	seed := uint64(serverWorld.Tick())<<32 | uint64(playerState.Id)
+	for _, angle := range weapon.PelletAngles(seed) {
+		bullet := wstate.NewBulletState(playerState, aim.Rotated(angle), weapon)
+		if playerState.HasEffect(pickups.BigBullets, now) {
+			bullet.Size *= pickups.BulletSizeFactor
+		}
+		serverWorld.AddBullet(playerState, *playerState.Pos, bullet)
+	}
+}
+
+func changeEntityStates(serverWorld *wstate.ServerWorld, dt time.Duration) {
+	gameplay := config.Current()
+	for _, player := range serverWorld.Players() {
+		if player.Dashing(serverWorld.Now()) {
+			movePlayer(serverWorld, player, player.DashDir.ScalarMult(gameplay.DashSpeed*float32(dt.Seconds())))
+			continue
+		}
+		speed := gameplay.PlayerSpeed
+		if player.HasEffect(pickups.SpeedBoost, serverWorld.Now()) {
+			speed *= pickups.SpeedFactor
+		}
+		delta := moveDelta(serverWorld.PlayerWants(player.Id), speed, float32(dt.Seconds()))
+		movePlayer(serverWorld, player, delta)
+	}
+}
+
+// pushes overlapping players apart until they just touch. every push is
+// worked out from where the players stood before any of them was pushed, so
+// the order they are walked in doesn't matter
+func separatePlayers(serverWorld *wstate.ServerWorld) {
+	players := serverWorld.Players()
+	pushes := make([]geom.Vector2, len(players))
+	for i := range players {
+		for j := i + 1; j < len(players); j++ {
+			a, b := players[i], players[j]
+			radA, radB := hitboxes.PlayerSize(a.Health()), hitboxes.PlayerSize(b.Health())
+			overlap := radA + radB - a.Pos.DistTo(*b.Pos)
+			if overlap <= 0 {
+				continue
+			}
+			// players on the same spot split along x, the lower id to the left
+			dir := geom.NewDir(b.Pos.Sub(*a.Pos))
+			if dir == (geom.Direction{}) {
+				dir = geom.Direction(geom.NewVector(1, 0))
+			}
+			shareA := float32(0.5)
+			if config.Current().BodyBlock {
+				shareA = radB / (radA + radB)
+			}
+			pushes[i] = pushes[i].Sub(dir.ScalarMult(overlap * shareA))
+			pushes[j] = pushes[j].Add(dir.ScalarMult(overlap * (1 - shareA)))
+		}
+	}
+	for i, player := range players {
+		movePlayer(serverWorld, player, pushes[i])
+	}
+}
+
+// a spot for the next pickup: a free spawn point of the map, or anywhere
+// inside the world when the map has none. false when every spawn point is taken
+func pickupPos(serverWorld *wstate.ServerWorld, seed uint64) (geom.Vector2, bool) {
+	spawns := serverWorld.PickupSpawns()
+	if len(spawns) == 0 {
+		margin := config.Current().InitialPlayerSize
+		return geom.NewVector(
+			margin+pickups.Roll(seed)*(serverWorld.Width()-2*margin),
+			margin+pickups.Roll(^seed)*(serverWorld.Height()-2*margin),
+		), true
+	}
+
+	free := slices.DeleteFunc(slices.Clone(spawns), func(spawn geom.Vector2) bool {
+		for _, pickup := range serverWorld.Pickups() {
+			if *pickup.Pos == spawn {
+				return true
+			}
+		}
+		return false
+	})
+	if len(free) == 0 {
+		return geom.Vector2{}, false
+	}
+	return free[int(pickups.Roll(seed)*float32(len(free)))], true
+}
+
+// spawns a pickup every pickups.SpawnInterval while there is room for one
+func spawnPickups(serverWorld *wstate.ServerWorld) {
+	now := serverWorld.Now()
+	if now-serverWorld.LastPickupSpawn() < pickups.SpawnInterval {
+		return
+	}
+	serverWorld.SetLastPickupSpawn(now)
+	if len(serverWorld.Pickups()) >= pickups.MaxOnGround {
+		return
+	}
+
+	seed := uint64(serverWorld.Tick())<<32 | uint64(serverWorld.NextPickupId())
+	pos, ok := pickupPos(serverWorld, seed+1)
+	if !ok {
+		return
+	}
+	kind := pickups.Kinds[int(pickups.Roll(seed)*float32(len(pickups.Kinds)))]
+	serverWorld.AddPickup(pos, kind)
+}
+
+// players pick up what they touch, lower ids first
+func collectPickups(serverWorld *wstate.ServerWorld) {
+	for _, player := range serverWorld.Players() {
+		for _, pickup := range serverWorld.Pickups() {
+			if !serverWorld.Alive(pickup.Handle) {
+				continue
+			}
+			if player.Pos.DistTo(*pickup.Pos) < hitboxes.PlayerSize(player.Health())+pickups.Radius {
+				player.Pickup(pickup.Kind, serverWorld.Now())
+				serverWorld.Remove(pickup.Handle)
+			}
+		}
+	}
+}
+
+// simulates dt worth of the world and advances its clock by dt. the tick
+// number is left alone, the caller moves on with NextTick
+func Step(serverWorld *wstate.ServerWorld, playerInputs map[uint]stypes.PlayerInput, dt time.Duration) TickResults {
+	for _, bullet := range serverWorld.Bullets() {
+		weapon := weapons.Get(bullet.Weapon)
+		if serverWorld.Now()-bullet.Born > weapon.Lifetime() {
+			serverWorld.Remove(bullet.Handle)
+		}
+
+		*bullet.Pos = bullet.Pos.Add(
+			bullet.MoveDir.ScalarMult(weapon.Speed * float32(dt.Seconds())),
+		)
+		if !bullet.Pos.InsideSquare(0, 0, serverWorld.Width(), serverWorld.Height(), config.Current().InitialBulletSize) {
+			serverWorld.Remove(bullet.Handle)
+		}
+	}
+
+	for _, pid := range slices.Sorted(maps.Keys(playerInputs)) {
+		ci := playerInputs[pid]
+		if !serverWorld.HasPlayer(uint(ci.PlayerId)) {
+			continue
+		}
+		wants := serverWorld.PlayerWants(uint(ci.PlayerId))
+		clear(wants.MoveDirs)
+		wants.Move = geom.Vector2{}
+		handleClientInputs(serverWorld, &ci)
+	}
+
+	changeEntityStates(serverWorld, dt)
+	separatePlayers(serverWorld)
+	spawnPickups(serverWorld)
+	collectPickups(serverWorld)
+	var results TickResults
+	results.PlayersDied, results.Hits = calculateHits(serverWorld)
+	serverWorld.AdvanceTime(dt)
+
+	return results
+}
+
+func ConnectPlayer(serverWorld *wstate.ServerWorld, id uint, addr net.UDPAddr) wstate.Player {
+	serverWorld.AddAddress(id, addr)
+	return serverWorld.SpawnPlayer(id, spawnPos, addr)
+}
+
+// players, bullets and pickups are sorted, equal worlds always build equal
+// messages
+func NetworkWorldState(serverWorld *wstate.ServerWorld) *stypes.WorldState {
+	netWorld := &stypes.WorldState{GameplayChecksum: config.Current().Checksum()}
+
+	for _, player := range serverWorld.Players() {
+		netPlayer := stypes.NewPlayerState(
+			uint32(player.Id),
+			*player.Pos,
+			float32(player.Health()),
+			player.Aim,
+		)
+		netPlayer.DashCooldown = player.DashCooldownLeft(serverWorld.Now())
+		for _, kind := range pickups.Kinds {
+			if left := player.EffectLeft(kind, serverWorld.Now()); left > 0 {
+				netPlayer.Effects = append(netPlayer.Effects, stypes.Effect{Kind: kind, Remaining: left})
+			}
+		}
+		netWorld.Players = append(netWorld.Players, netPlayer)
+	}
+
+	for _, bullet := range serverWorld.Bullets() {
+		netWorld.Bullets = append(netWorld.Bullets, stypes.NewBulletState(
+			uint32(bullet.OwnerId),
+			*bullet.Pos,
+			bullet.Size,
+			bullet.Weapon,
+		))
+	}
+
+	for _, pickup := range serverWorld.Pickups() {
+		netWorld.Pickups = append(netWorld.Pickups, stypes.NewPickupState(pickup.Id, *pickup.Pos, pickup.Kind))
+	}
+
+	netWorld.TickNum = serverWorld.Tick()
+
+	return netWorld
+}
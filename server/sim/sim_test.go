@@ -0,0 +1,801 @@
+package sim
+
+import (
+	"CircleWar/config"
+	"CircleWar/core/damage"
+	"CircleWar/core/geom"
+	"CircleWar/core/hitboxes"
+	stypes "CircleWar/core/netmsg"
+	"CircleWar/core/pickups"
+	"CircleWar/core/replay"
+	"CircleWar/core/weapons"
+	wstate "CircleWar/server/world_state"
+	"math"
+	"net"
+	"slices"
+	"testing"
+	"time"
+)
+
+// the tests play by the defaults
+var gameplay = config.Default().Gameplay
+
+// the step at the default tick rate
+var fixedStep = time.Second / time.Duration(config.Default().TicksPerSecond)
+
+var (
+	cooldown      = gameplay.BulletCooldown()
+	initialHealth = stypes.PlayerHealth(gameplay.InitialPlayerHealth)
+)
+
+func shootInput(id uint, target geom.Vector2) map[uint]stypes.PlayerInput {
+	return map[uint]stypes.PlayerInput{id: {
+		PlayerId: uint32(id),
+		Actions:  []stypes.PlayerAction{&stypes.ShootAction{Target: target}},
+	}}
+}
+
+// the current life of player id, which the test needs alive
+func livePlayer(t *testing.T, sw *wstate.ServerWorld, id uint) wstate.Player {
+	t.Helper()
+	player, ok := sw.LookupPlayer(id)
+	if !ok {
+		t.Fatalf("player %d isn't alive", id)
+	}
+	return player
+}
+
+func newTestWorld(clock Clock) wstate.ServerWorld {
+	sw := wstate.NewServerWorld(4000, 4000, clock)
+	ConnectPlayer(&sw, 1, net.UDPAddr{})
+	return sw
+}
+
+func TestStepCooldownAndLifetime(t *testing.T) {
+	tests := []struct {
+		name        string
+		advance     time.Duration // clock jump before the step
+		input       map[uint]stypes.PlayerInput
+		wantBullets int
+	}{
+		{"cooldown after spawn", 0, shootInput(1, geom.NewVector(0, 500)), 0},
+		{"shoot once cooled down", cooldown, shootInput(1, geom.NewVector(0, 500)), 1},
+		{"still cooling down", 0, shootInput(1, geom.NewVector(0, 500)), 1},
+		{"bullet flies", fixedStep, nil, 1},
+		{"bullet expires", time.Duration(float64(gameplay.BulletTimeToLiveSec) * float64(time.Second)), nil, 0},
+	}
+
+	clock := NewStepClock(0)
+	sw := newTestWorld(clock)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clock.Advance(test.advance)
+			Step(&sw, test.input, fixedStep)
+			if got := len(sw.Bullets()); got != test.wantBullets {
+				t.Errorf("got %d bullets want %d", got, test.wantBullets)
+			}
+		})
+	}
+}
+
+// the simulation plays by the current gameplay, not the defaults
+func TestGameplayConfig(t *testing.T) {
+	changed := gameplay
+	changed.PlayerSpeed = 500
+	changed.BulletSpeed = 600
+	changed.InitialPlayerSize = 30
+	config.SetCurrent(changed)
+	t.Cleanup(func() { config.SetCurrent(gameplay) })
+
+	clock := NewStepClock(0)
+	sw := newTestWorld(clock)
+	clock.Advance(2 * changed.BulletCooldown())
+	start := *livePlayer(t, &sw, 1).Pos
+	input := shootInput(1, start.Add(geom.NewVector(0, 100)))
+	input[1] = stypes.PlayerInput{PlayerId: 1, Actions: append(input[1].Actions, &stypes.MoveAction{Dir: stypes.RIGHT})}
+	Step(&sw, input, time.Second/10)
+
+	if moved := livePlayer(t, &sw, 1).Pos.X - start.X; math.Abs(float64(moved-50)) > 0.01 {
+		t.Errorf("moved %f want 50", moved)
+	}
+	bullets := sw.Bullets()
+	if len(bullets) != 1 {
+		t.Fatalf("got %d bullets want 1", len(bullets))
+	}
+	if got := weapons.Get(bullets[0].Weapon).Speed; got != 600 {
+		t.Errorf("bullet speed %f want 600", got)
+	}
+	if got := hitboxes.PlayerSize(livePlayer(t, &sw, 1).Health()); got != 30 {
+		t.Errorf("player size %f want 30", got)
+	}
+}
+
+func TestMoveInput(t *testing.T) {
+	diag := float32(1 / math.Sqrt2)
+	tests := []struct {
+		name    string
+		actions []stypes.PlayerAction
+		wantDir geom.Vector2
+	}{
+		{"enum", []stypes.PlayerAction{&stypes.MoveAction{Dir: stypes.LEFT}}, geom.NewVector(-1, 0)},
+		{"enum diagonal", []stypes.PlayerAction{
+			&stypes.MoveAction{Dir: stypes.RIGHT}, &stypes.MoveAction{Dir: stypes.DOWN},
+		}, geom.NewVector(diag, diag)},
+		{"analog half", []stypes.PlayerAction{&stypes.AnalogAction{Move: geom.NewVector(0, -0.5)}}, geom.NewVector(0, -0.5)},
+		{"analog clamped", []stypes.PlayerAction{&stypes.AnalogAction{Move: geom.NewVector(30, 40)}}, geom.NewVector(0.6, 0.8)},
+		{"analog and enum clamped", []stypes.PlayerAction{
+			&stypes.AnalogAction{Move: geom.NewVector(1, 0)}, &stypes.MoveAction{Dir: stypes.UP},
+		}, geom.NewVector(diag, -diag)},
+		{"analog nan ignored", []stypes.PlayerAction{
+			&stypes.AnalogAction{Move: geom.NewVector(float32(math.NaN()), 1)},
+		}, geom.NewVector(0, 0)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sw := newTestWorld(NewStepClock(0))
+			start := *livePlayer(t, &sw, 1).Pos
+			Step(&sw, map[uint]stypes.PlayerInput{1: {PlayerId: 1, Actions: test.actions}}, time.Second/10)
+
+			moved := livePlayer(t, &sw, 1).Pos.Sub(start)
+			want := geom.NewVector(test.wantDir.X*gameplay.PlayerSpeed/10, test.wantDir.Y*gameplay.PlayerSpeed/10)
+			if moved.DistTo(want) > 0.01 {
+				t.Errorf("moved %s want %s", moved, want)
+			}
+		})
+	}
+}
+
+func TestAnalogAim(t *testing.T) {
+	sw := newTestWorld(NewStepClock(0))
+	for _, aim := range []float32{1.5, float32(math.Inf(1))} {
+		input := map[uint]stypes.PlayerInput{1: {PlayerId: 1, Actions: []stypes.PlayerAction{&stypes.AnalogAction{Aim: aim}}}}
+		Step(&sw, input, fixedStep)
+	}
+	if got := NetworkWorldState(&sw).Players[0].Aim; got != 1.5 {
+		t.Errorf("got aim %f want 1.5, infinite aim should be ignored", got)
+	}
+}
+
+func TestWeaponShots(t *testing.T) {
+	tests := []struct {
+		name        string
+		slot        uint32
+		wantBullets int
+		wantWeapon  weapons.Type
+	}{
+		{"pistol", 0, 1, weapons.Pistol},
+		{"shotgun", 1, 6, weapons.Shotgun},
+		{"sniper", 2, 1, weapons.Sniper},
+		{"smg", 3, 1, weapons.SMG},
+		{"no such slot", 9, 0, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clock := NewStepClock(0)
+			sw := newTestWorld(clock)
+			clock.Advance(time.Minute)
+			input := map[uint]stypes.PlayerInput{1: {PlayerId: 1, Actions: []stypes.PlayerAction{
+				&stypes.ShootAction{Target: geom.NewVector(1000, 500), Slot: test.slot},
+			}}}
+			Step(&sw, input, fixedStep)
+
+			bullets := NetworkWorldState(&sw).Bullets
+			if len(bullets) != test.wantBullets {
+				t.Fatalf("got %d bullets want %d", len(bullets), test.wantBullets)
+			}
+			for _, bullet := range bullets {
+				if bullet.Weapon != test.wantWeapon {
+					t.Errorf("got weapon %d want %d", bullet.Weapon, test.wantWeapon)
+				}
+			}
+		})
+	}
+}
+
+// switching weapons doesn't skip the cooldown of the last shot
+func TestWeaponCooldownCarriesOver(t *testing.T) {
+	clock := NewStepClock(0)
+	sw := newTestWorld(clock)
+	clock.Advance(time.Minute)
+	sniper := weapons.Get(weapons.Sniper)
+
+	shots := []struct {
+		advance time.Duration
+		slot    uint32
+		wantSMG int
+	}{
+		{0, 2, 0},
+		{weapons.Get(weapons.SMG).Cooldown * 2, 3, 0},
+		{sniper.Cooldown, 3, 1},
+	}
+	for _, shot := range shots {
+		clock.Advance(shot.advance)
+		Step(&sw, map[uint]stypes.PlayerInput{1: {PlayerId: 1, Actions: []stypes.PlayerAction{
+			&stypes.ShootAction{Target: geom.NewVector(600, 500), Slot: shot.slot},
+		}}}, fixedStep)
+		smg := 0
+		for _, bullet := range sw.Bullets() {
+			if bullet.Weapon == weapons.SMG {
+				smg++
+			}
+		}
+		if smg != shot.wantSMG {
+			t.Errorf("slot %d after %s: got %d smg bullets want %d", shot.slot, shot.advance, smg, shot.wantSMG)
+		}
+	}
+}
+
+func TestStepAdvancesClock(t *testing.T) {
+	clock := NewStepClock(time.Second)
+	sw := newTestWorld(clock)
+	Step(&sw, nil, fixedStep)
+	if clock.Now() != time.Second+fixedStep {
+		t.Errorf("got %s want %s", clock.Now(), time.Second+fixedStep)
+	}
+}
+
+func TestStepDeterministic(t *testing.T) {
+	run := func() uint64 {
+		sw := newTestWorld(NewStepClock(0))
+		ConnectPlayer(&sw, 2, net.UDPAddr{})
+		for i := range 120 {
+			inputs := shootInput(1, geom.NewVector(float32(i*10), 0))
+			inputs[2] = stypes.PlayerInput{PlayerId: 2, Actions: []stypes.PlayerAction{
+				&stypes.MoveAction{Dir: stypes.LEFT},
+				&stypes.MoveAction{Dir: stypes.RIGHT},
+				&stypes.MoveAction{Dir: stypes.UP},
+				&stypes.ShootAction{Target: geom.NewVector(500, 0)},
+			}}
+			Step(&sw, inputs, fixedStep)
+			sw.NextTick()
+		}
+		return replay.Checksum(NetworkWorldState(&sw))
+	}
+
+	first := run()
+	for range 5 {
+		if got := run(); got != first {
+			t.Fatalf("got checksum %x want %x", got, first)
+		}
+	}
+}
+
+func TestRunnerDue(t *testing.T) {
+	start := time.Unix(0, 0)
+	tests := []struct {
+		name        string
+		at          time.Duration
+		wantSteps   int
+		wantSkipped uint64
+	}{
+		{"too early", fixedStep / 2, 0, 0},
+		{"on time", fixedStep, 1, 0},
+		{"same tick again", fixedStep + fixedStep/2, 0, 0},
+		{"two late", 3 * fixedStep, 2, 0},
+		{"catch up is capped", 20 * fixedStep, maxCatchUpSteps, 12},
+		{"back on schedule", 21 * fixedStep, 1, 12},
+	}
+
+	runner := NewRunner(fixedStep, start)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := runner.Due(start.Add(test.at))
+			if got != test.wantSteps || runner.Skipped != test.wantSkipped {
+				t.Errorf("got %d steps %d skipped - want %d steps %d skipped",
+					got, runner.Skipped, test.wantSteps, test.wantSkipped)
+			}
+		})
+	}
+}
+
+func TestPickupSpawning(t *testing.T) {
+	spawns := []geom.Vector2{geom.NewVector(100, 100), geom.NewVector(3000, 3000)}
+	tests := []struct {
+		name        string
+		advance     time.Duration
+		wantPickups int
+	}{
+		{"nothing at the start", 0, 0},
+		{"first spawn", pickups.SpawnInterval, 1},
+		{"waits for the interval", pickups.SpawnInterval / 2, 1},
+		{"second spawn", pickups.SpawnInterval / 2, 2},
+		{"every spawn point is taken", pickups.SpawnInterval, 2},
+	}
+
+	clock := NewStepClock(0)
+	sw := newTestWorld(clock)
+	sw.SetPickupSpawns(spawns)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clock.Advance(test.advance)
+			Step(&sw, nil, fixedStep)
+			sw.NextTick()
+			netPickups := NetworkWorldState(&sw).Pickups
+			if len(netPickups) != test.wantPickups {
+				t.Fatalf("got %d pickups want %d", len(netPickups), test.wantPickups)
+			}
+			for _, pickup := range netPickups {
+				if !slices.Contains(spawns, pickup.Pos) {
+					t.Errorf("pickup at %s isn't on a spawn point", pickup.Pos)
+				}
+			}
+		})
+	}
+}
+
+// a world with player 1 standing on a pickup of kind
+func pickupWorld(clock Clock, kind pickups.Kind) wstate.ServerWorld {
+	sw := newTestWorld(clock)
+	player, _ := sw.LookupPlayer(1)
+	sw.AddPickup(*player.Pos, kind)
+	return sw
+}
+
+func TestPickupEffects(t *testing.T) {
+	t.Run("health is capped", func(t *testing.T) {
+		sw := pickupWorld(NewStepClock(0), pickups.Health)
+		livePlayer(t, &sw, 1).ChangeHealth(-2)
+		Step(&sw, nil, fixedStep)
+		if got := livePlayer(t, &sw, 1).Health(); got != initialHealth {
+			t.Errorf("got health %f want %f", got, initialHealth)
+		}
+		if len(sw.Pickups()) != 0 {
+			t.Error("pickup is still on the ground")
+		}
+	})
+
+	t.Run("speed", func(t *testing.T) {
+		sw := pickupWorld(NewStepClock(0), pickups.SpeedBoost)
+		Step(&sw, nil, fixedStep)
+		start := *livePlayer(t, &sw, 1).Pos
+		input := map[uint]stypes.PlayerInput{1: {PlayerId: 1, Actions: []stypes.PlayerAction{&stypes.MoveAction{Dir: stypes.RIGHT}}}}
+		Step(&sw, input, time.Second/10)
+		want := float32(gameplay.PlayerSpeed * pickups.SpeedFactor / 10)
+		if moved := livePlayer(t, &sw, 1).Pos.X - start.X; math.Abs(float64(moved-want)) > 0.01 {
+			t.Errorf("moved %f want %f", moved, want)
+		}
+	})
+
+	t.Run("shield blocks bullets", func(t *testing.T) {
+		clock := NewStepClock(0)
+		sw := pickupWorld(clock, pickups.Shield)
+		ConnectPlayer(&sw, 2, net.UDPAddr{})
+		*livePlayer(t, &sw, 2).Pos = geom.NewVector(500, 700)
+		Step(&sw, nil, fixedStep)
+		clock.Advance(cooldown)
+		Step(&sw, shootInput(2, spawnPos), fixedStep)
+		for range 20 {
+			Step(&sw, nil, fixedStep)
+		}
+		if got := livePlayer(t, &sw, 1).Health(); got != initialHealth {
+			t.Errorf("got health %f, the shield should block the bullet", got)
+		}
+		if len(sw.Bullets()) != 0 {
+			t.Error("the shield didn't stop the bullet")
+		}
+	})
+
+	t.Run("effects are sent", func(t *testing.T) {
+		sw := pickupWorld(NewStepClock(0), pickups.RapidFire)
+		Step(&sw, nil, fixedStep)
+		effects := NetworkWorldState(&sw).Players[0].Effects
+		want := pickups.Get(pickups.RapidFire).Duration - fixedStep
+		if len(effects) != 1 || effects[0].Kind != pickups.RapidFire || effects[0].Remaining != want {
+			t.Errorf("got effects %+v want rapid fire with %s left", effects, want)
+		}
+	})
+}
+
+func dashInput(id uint, actions ...stypes.PlayerAction) map[uint]stypes.PlayerInput {
+	return map[uint]stypes.PlayerInput{id: {PlayerId: uint32(id), Actions: append(actions, &stypes.DashAction{})}}
+}
+
+func TestDash(t *testing.T) {
+	dashDuration := gameplay.DashDuration()
+	dashCooldown := gameplay.DashCooldown()
+	right := &stypes.MoveAction{Dir: stypes.RIGHT}
+	tests := []struct {
+		name     string
+		advance  time.Duration // clock jump before the step
+		input    map[uint]stypes.PlayerInput
+		wantMove geom.Vector2
+	}{
+		{"dash right", 0, dashInput(1, right), geom.NewVector(gameplay.DashSpeed*float32(fixedStep.Seconds()), 0)},
+		{"dash goes on without input", 0, nil, geom.NewVector(gameplay.DashSpeed*float32(fixedStep.Seconds()), 0)},
+		{"back to walking", dashDuration, map[uint]stypes.PlayerInput{1: {PlayerId: 1, Actions: []stypes.PlayerAction{right}}},
+			geom.NewVector(gameplay.PlayerSpeed*float32(fixedStep.Seconds()), 0)},
+		{"still cooling down", 0, dashInput(1, right), geom.NewVector(gameplay.PlayerSpeed*float32(fixedStep.Seconds()), 0)},
+		{"dash where it aims", dashCooldown, dashInput(1, &stypes.AnalogAction{Aim: math.Pi / 2}),
+			geom.NewVector(0, gameplay.DashSpeed*float32(fixedStep.Seconds()))},
+	}
+
+	clock := NewStepClock(0)
+	sw := newTestWorld(clock)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clock.Advance(test.advance)
+			start := *livePlayer(t, &sw, 1).Pos
+			Step(&sw, test.input, fixedStep)
+			if moved := livePlayer(t, &sw, 1).Pos.Sub(start); moved.DistTo(test.wantMove) > 0.01 {
+				t.Errorf("moved %s want %s", moved, test.wantMove)
+			}
+		})
+	}
+}
+
+func TestDashCooldownIsSent(t *testing.T) {
+	sw := newTestWorld(NewStepClock(0))
+	if got := NetworkWorldState(&sw).Players[0].DashCooldown; got != 0 {
+		t.Errorf("got cooldown %s at spawn, players can dash right away", got)
+	}
+	Step(&sw, dashInput(1), fixedStep)
+	want := gameplay.DashCooldown() - fixedStep
+	if got := NetworkWorldState(&sw).Players[0].DashCooldown; got != want {
+		t.Errorf("got cooldown %s want %s", got, want)
+	}
+}
+
+func TestDashDodgesBullets(t *testing.T) {
+	clock := NewStepClock(0)
+	sw := newTestWorld(clock)
+	ConnectPlayer(&sw, 2, net.UDPAddr{})
+	*livePlayer(t, &sw, 2).Pos = geom.NewVector(500, 700)
+	clock.Advance(time.Minute)
+
+	// player 1 dashes through the bullet flying at it
+	Step(&sw, shootInput(2, spawnPos), fixedStep)
+	Step(&sw, dashInput(1, &stypes.MoveAction{Dir: stypes.DOWN}), fixedStep)
+	for range 10 {
+		Step(&sw, nil, fixedStep)
+	}
+	if got := livePlayer(t, &sw, 1).Health(); got != initialHealth {
+		t.Errorf("got health %f, the dash should dodge the bullet", got)
+	}
+}
+
+func TestSeparatePlayers(t *testing.T) {
+	size := hitboxes.PlayerSize(initialHealth)
+	tests := []struct {
+		name         string
+		pos1, pos2   geom.Vector2
+		health2      int // taken off player 2
+		want1, want2 geom.Vector2
+	}{
+		{"apart stay put", geom.NewVector(500, 500), geom.NewVector(700, 500), 0,
+			geom.NewVector(500, 500), geom.NewVector(700, 500)},
+		{"overlap split evenly", geom.NewVector(500, 500), geom.NewVector(500, 500+size), 0,
+			geom.NewVector(500, 500-size/2), geom.NewVector(500, 500+size*3/2)},
+		{"same spot splits along x", geom.NewVector(500, 500), geom.NewVector(500, 500), 0,
+			geom.NewVector(500-size, 500), geom.NewVector(500+size, 500)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sw := newTestWorld(NewStepClock(0))
+			ConnectPlayer(&sw, 2, net.UDPAddr{})
+			*livePlayer(t, &sw, 1).Pos = test.pos1
+			*livePlayer(t, &sw, 2).Pos = test.pos2
+			separatePlayers(&sw)
+			if got := *livePlayer(t, &sw, 1).Pos; got.DistTo(test.want1) > 0.01 {
+				t.Errorf("player 1 at %s want %s", got, test.want1)
+			}
+			if got := *livePlayer(t, &sw, 2).Pos; got.DistTo(test.want2) > 0.01 {
+				t.Errorf("player 2 at %s want %s", got, test.want2)
+			}
+		})
+	}
+}
+
+func TestBodyBlock(t *testing.T) {
+	sw := newTestWorld(NewStepClock(0))
+	ConnectPlayer(&sw, 2, net.UDPAddr{})
+	livePlayer(t, &sw, 2).ChangeHealth(-10)
+	*livePlayer(t, &sw, 2).Pos = geom.NewVector(510, 500)
+	separatePlayers(&sw)
+
+	moved1 := 500 - livePlayer(t, &sw, 1).Pos.X
+	moved2 := livePlayer(t, &sw, 2).Pos.X - 510
+	if moved1 >= moved2 {
+		t.Errorf("the healthier player moved %f, the smaller one %f", moved1, moved2)
+	}
+	touching := hitboxes.PlayerSize(livePlayer(t, &sw, 1).Health()) + hitboxes.PlayerSize(livePlayer(t, &sw, 2).Health())
+	if dist := livePlayer(t, &sw, 1).Pos.DistTo(*livePlayer(t, &sw, 2).Pos); math.Abs(float64(dist-touching)) > 0.01 {
+		t.Errorf("players are %f apart want %f", dist, touching)
+	}
+}
+
+// a crowd ends up the same whichever ids its players have
+func TestSeparateOrderIndependent(t *testing.T) {
+	spots := []geom.Vector2{geom.NewVector(500, 500), geom.NewVector(530, 510), geom.NewVector(480, 540)}
+	separated := func(ids []uint) map[geom.Vector2]bool {
+		sw := wstate.NewServerWorld(4000, 4000, NewStepClock(0))
+		for i, id := range ids {
+			ConnectPlayer(&sw, id, net.UDPAddr{})
+			*livePlayer(t, &sw, id).Pos = spots[i]
+		}
+		separatePlayers(&sw)
+		got := map[geom.Vector2]bool{}
+		for _, player := range sw.Players() {
+			got[*player.Pos] = true
+		}
+		return got
+	}
+
+	want := separated([]uint{1, 2, 3})
+	for _, ids := range [][]uint{{3, 2, 1}, {2, 3, 1}} {
+		got := separated(ids)
+		for pos := range want {
+			if !got[pos] {
+				t.Errorf("ids %v: no player at %s", ids, pos)
+			}
+		}
+	}
+}
+
+func TestHitsReported(t *testing.T) {
+	tests := []struct {
+		name       string
+		slot       uint32
+		effect     pickups.Kind
+		wantDamage int
+		wantBlock  bool
+	}{
+		{"pistol", 0, pickups.Health, 1, false},
+		{"sniper", 2, pickups.Health, 5, false},
+		{"shielded", 2, pickups.Shield, 0, true},
+		{"armored", 2, pickups.Armor, 5 - pickups.ArmorReduction, false},
+		{"armored pistol", 0, pickups.Armor, 1, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clock := NewStepClock(0)
+			sw := newTestWorld(clock)
+			ConnectPlayer(&sw, 2, net.UDPAddr{})
+			*livePlayer(t, &sw, 2).Pos = geom.NewVector(500, 800)
+			clock.Advance(time.Minute)
+			livePlayer(t, &sw, 1).EffectEnds[test.effect] = clock.Now() + time.Minute
+
+			hits := []damage.Hit{}
+			Step(&sw, map[uint]stypes.PlayerInput{2: {PlayerId: 2, Actions: []stypes.PlayerAction{
+				&stypes.ShootAction{Target: spawnPos, Slot: test.slot},
+			}}}, fixedStep)
+			for range 20 {
+				hits = append(hits, Step(&sw, nil, fixedStep).Hits...)
+			}
+
+			if len(hits) != 1 {
+				t.Fatalf("got %d hits want 1", len(hits))
+			}
+			hit := hits[0]
+			if hit.ShooterId != 2 || hit.TargetId != 1 || hit.Damage != test.wantDamage || hit.Blocked != test.wantBlock {
+				t.Errorf("got hit %+v", hit)
+			}
+			if got := initialHealth - livePlayer(t, &sw, 1).Health(); got != stypes.PlayerHealth(test.wantDamage) {
+				t.Errorf("lost %f health want %d", got, test.wantDamage)
+			}
+		})
+	}
+}
+
+// shotgun pellets hurt up close and are spent far away
+func TestShotgunFalloff(t *testing.T) {
+	tests := []struct {
+		name       string
+		shooter    geom.Vector2
+		wantDamage int
+	}{
+		{"close", geom.NewVector(500, 800), 1},
+		{"far", geom.NewVector(500, 1150), 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clock := NewStepClock(0)
+			sw := newTestWorld(clock)
+			ConnectPlayer(&sw, 2, net.UDPAddr{})
+			*livePlayer(t, &sw, 2).Pos = test.shooter
+			clock.Advance(time.Minute)
+
+			hits := []damage.Hit{}
+			Step(&sw, map[uint]stypes.PlayerInput{2: {PlayerId: 2, Actions: []stypes.PlayerAction{
+				&stypes.ShootAction{Target: spawnPos, Slot: 1},
+			}}}, fixedStep)
+			for range 40 {
+				hits = append(hits, Step(&sw, nil, fixedStep).Hits...)
+			}
+
+			if len(hits) == 0 {
+				t.Fatal("no pellet hit")
+			}
+			for _, hit := range hits {
+				if hit.Damage != test.wantDamage {
+					t.Errorf("got hit %+v want damage %d", hit, test.wantDamage)
+				}
+			}
+			if got := initialHealth - livePlayer(t, &sw, 1).Health(); got != stypes.PlayerHealth(len(hits)*test.wantDamage) {
+				t.Errorf("lost %f health from %d hits", got, len(hits))
+			}
+		})
+	}
+}
+
+func TestHitKeepsWants(t *testing.T) {
+	clock := NewStepClock(0)
+	sw := newTestWorld(clock)
+	ConnectPlayer(&sw, 2, net.UDPAddr{})
+	*livePlayer(t, &sw, 2).Pos = geom.NewVector(800, 500)
+	clock.Advance(time.Minute)
+	Step(&sw, map[uint]stypes.PlayerInput{
+		1: {PlayerId: 1, Actions: []stypes.PlayerAction{&stypes.MoveAction{Dir: stypes.RIGHT}}},
+		2: {PlayerId: 2, Actions: []stypes.PlayerAction{&stypes.ShootAction{Target: spawnPos, Slot: 0}}},
+	}, fixedStep)
+
+	hits := 0
+	for range 20 {
+		hits += len(Step(&sw, nil, fixedStep).Hits)
+	}
+	if hits != 1 {
+		t.Fatalf("got %d hits want 1", hits)
+	}
+	if !sw.PlayerWants(1).MoveDirs[stypes.RIGHT] {
+		t.Error("getting hit dropped the player's movement")
+	}
+}
+
+func TestRevivedPlayerIsNewEntity(t *testing.T) {
+	sw := newTestWorld(NewStepClock(0))
+	old := livePlayer(t, &sw, 1)
+	old.ChangeHealth(-3)
+	sw.RemovePlayer(1)
+	sw.RevivePlayer(1)
+
+	if _, ok := sw.PlayerByHandle(old.Handle); ok {
+		t.Error("handle of the dead player resolves to the revived one")
+	}
+	if got := livePlayer(t, &sw, 1).Health(); got != initialHealth {
+		t.Errorf("revived with health %f want %f", got, initialHealth)
+	}
+}
+
+// gameplay recorded in the middle of a match applies from its tick on, a
+// replayer cloned before it still plays by the old one
+func TestReplayerGameplayChange(t *testing.T) {
+	t.Cleanup(func() { config.SetCurrent(gameplay) })
+	sw := wstate.NewServerWorld(4000, 4000, NewStepClock(0))
+	r, err := NewReplayer(replay.Header{
+		TicksPerSecond: 60, Width: 4000, Height: 4000,
+		InitialWorld: NetworkWorldState(&sw),
+		Gameplay:     gameplay,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	connect := replay.PlayerEvent{Kind: replay.Connect, PlayerId: 1, Addr: "127.0.0.1:4000"}
+	if _, _, err := r.Apply(replay.Tick{TickNum: 0, Events: []replay.PlayerEvent{connect}}); err != nil {
+		t.Fatal(err)
+	}
+	before := r.Clone()
+
+	changed := gameplay
+	changed.PlayerSpeed = 600
+	moveRight := func(changes ...replay.GameplayChange) replay.Tick {
+		return replay.Tick{TickNum: 1, GameplayChanges: changes, Inputs: []*stypes.PlayerInput{
+			{PlayerId: 1, Actions: []stypes.PlayerAction{&stypes.MoveAction{Dir: stypes.RIGHT}}},
+		}}
+	}
+	tests := []struct {
+		name      string
+		replayer  *Replayer
+		tick      replay.Tick
+		wantSpeed float32
+	}{
+		{"changed", r, moveRight(replay.GameplayChange{Gameplay: changed}), 600},
+		{"cloned before the change", before, moveRight(), gameplay.PlayerSpeed},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			start := *livePlayer(t, &test.replayer.World, 1).Pos
+			if _, _, err := test.replayer.Apply(test.tick); err != nil {
+				t.Fatal(err)
+			}
+			want := test.wantSpeed * float32(fixedStep.Seconds())
+			if moved := livePlayer(t, &test.replayer.World, 1).Pos.X - start.X; math.Abs(float64(moved-want)) > 0.01 {
+				t.Errorf("moved %f want %f", moved, want)
+			}
+		})
+	}
+}
+
+// a player that connected before a reload in the same tick spawned by the
+// old gameplay, like it did live
+func TestReplayerGameplayAfterEvents(t *testing.T) {
+	t.Cleanup(func() { config.SetCurrent(gameplay) })
+	reloaded := gameplay
+	reloaded.InitialPlayerHealth = gameplay.InitialPlayerHealth + 10
+	connect := replay.PlayerEvent{Kind: replay.Connect, PlayerId: 1, Addr: "127.0.0.1:4000"}
+	tests := []struct {
+		name        string
+		afterEvents int
+		wantHealth  float32
+	}{
+		{"reload after the connect", 1, gameplay.InitialPlayerHealth},
+		{"reload before the connect", 0, reloaded.InitialPlayerHealth},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sw := wstate.NewServerWorld(4000, 4000, NewStepClock(0))
+			r, err := NewReplayer(replay.Header{
+				TicksPerSecond: 60, Width: 4000, Height: 4000,
+				InitialWorld: NetworkWorldState(&sw),
+				Gameplay:     gameplay,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			tick := replay.Tick{
+				TickNum:         0,
+				Events:          []replay.PlayerEvent{connect},
+				GameplayChanges: []replay.GameplayChange{{AfterEvents: test.afterEvents, Gameplay: reloaded}},
+			}
+			if _, _, err := r.Apply(tick); err != nil {
+				t.Fatal(err)
+			}
+			if got := livePlayer(t, &r.World, 1).Health(); got != stypes.PlayerHealth(test.wantHealth) {
+				t.Errorf("spawned with health %f want %f", got, test.wantHealth)
+			}
+			if config.Current() != reloaded {
+				t.Error("the reload isn't current after the tick")
+			}
+		})
+	}
+}
+
+func TestKillScores(t *testing.T) {
+	clock := NewStepClock(0)
+	sw := newTestWorld(clock)
+	ConnectPlayer(&sw, 2, net.UDPAddr{})
+	*livePlayer(t, &sw, 2).Pos = geom.NewVector(500, 800)
+	livePlayer(t, &sw, 1).ChangeHealth(1 - int(initialHealth))
+	clock.Advance(time.Minute)
+
+	Step(&sw, map[uint]stypes.PlayerInput{2: {PlayerId: 2, Actions: []stypes.PlayerAction{
+		&stypes.ShootAction{Target: spawnPos},
+	}}}, fixedStep)
+	died := []uint{}
+	for range 20 {
+		died = append(died, Step(&sw, nil, fixedStep).PlayersDied...)
+	}
+
+	if !slices.Equal(died, []uint{1}) {
+		t.Fatalf("got %v dead want [1]", died)
+	}
+	if sw.Score(2) != 1 || sw.Score(1) != 0 {
+		t.Errorf("got scores %d and %d want 0 and 1", sw.Score(1), sw.Score(2))
+	}
+}
+
+// a bullet shot before its owner died hits the owner's next life, and the
+// kill doesn't score for it
+func TestBulletsOfAnEarlierLife(t *testing.T) {
+	clock := NewStepClock(0)
+	sw := newTestWorld(clock)
+	*livePlayer(t, &sw, 1).Pos = geom.NewVector(500, 800)
+	clock.Advance(time.Minute)
+	Step(&sw, shootInput(1, spawnPos), fixedStep)
+	if len(sw.Bullets()) != 1 {
+		t.Fatalf("got %d bullets want 1", len(sw.Bullets()))
+	}
+
+	sw.RemovePlayer(1)
+	sw.RevivePlayer(1)
+	livePlayer(t, &sw, 1).ChangeHealth(1 - int(initialHealth))
+	died := []uint{}
+	for range 20 {
+		died = append(died, Step(&sw, nil, fixedStep).PlayersDied...)
+	}
+
+	if !slices.Equal(died, []uint{1}) {
+		t.Fatalf("got %v dead want [1]", died)
+	}
+	if sw.Score(1) != 0 {
+		t.Errorf("got score %d want 0", sw.Score(1))
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"CircleWar/core/geom"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parses spawn points written as "x,y" pairs separated by spaces,
+// like "500,500 1500,1000". every point must be inside the world
+func parsePickupSpawns(spec string, width, height float32) ([]geom.Vector2, error) {
+	spawns := []geom.Vector2{}
+	for _, pair := range strings.Fields(spec) {
+		xs, ys, ok := strings.Cut(pair, ",")
+		if !ok {
+			return nil, fmt.Errorf("pickup spawn '%s' isn't an x,y pair", pair)
+		}
+		x, errX := strconv.ParseFloat(xs, 32)
+		y, errY := strconv.ParseFloat(ys, 32)
+		if errX != nil || errY != nil {
+			return nil, fmt.Errorf("pickup spawn '%s' isn't an x,y pair", pair)
+		}
+		spawn := geom.NewVector(float32(x), float32(y))
+		if !spawn.InsideSquare(0, 0, width, height, 0) {
+			return nil, fmt.Errorf("pickup spawn '%s' is outside the world", pair)
+		}
+		spawns = append(spawns, spawn)
+	}
+	return spawns, nil
+}
@@ -0,0 +1,132 @@
+package worldstate
+
+import (
+	"CircleWar/core/geom"
+	stypes "CircleWar/core/netmsg"
+	"CircleWar/core/pickups"
+	"CircleWar/core/weapons"
+	"maps"
+	"net"
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+// copies of the world's state at the end of a tick. nothing in it points
+// into the world, so it can be read while the world keeps going
+type Snapshot struct {
+	Tick          uint32
+	Now           time.Duration
+	Width, Height float32
+	Players       []PlayerSnapshot // sorted by id
+	Bullets       []BulletSnapshot // in the order they were shot
+	Pickups       []PickupSnapshot // in the order they spawned
+	// every connected player, dead ones included
+	Addresses map[uint]net.UDPAddr
+	// kills by player id
+	Scores map[uint]int
+}
+
+type PlayerSnapshot struct {
+	Id     uint
+	Addr   net.UDPAddr
+	Pos    geom.Vector2
+	Health stypes.PlayerHealth
+	Aim    float32
+	Score  int
+	// time left of each kind's effect, indexed by pickups.Kind
+	EffectsLeft [pickups.NumKinds]time.Duration
+}
+
+type BulletSnapshot struct {
+	OwnerId uint
+	Pos     geom.Vector2
+	Size    float32
+	Weapon  weapons.Type
+}
+
+type PickupSnapshot struct {
+	Id   uint32
+	Pos  geom.Vector2
+	Kind pickups.Kind
+}
+
+// copies the world's state, only call it where the world is stepped
+func (sw *ServerWorld) Snapshot() Snapshot {
+	now := sw.Now()
+	snap := Snapshot{
+		Tick:      sw.Tick(),
+		Now:       now,
+		Width:     sw.width,
+		Height:    sw.height,
+		Players:   []PlayerSnapshot{},
+		Bullets:   []BulletSnapshot{},
+		Pickups:   []PickupSnapshot{},
+		Addresses: maps.Clone(sw.addresses),
+		Scores:    maps.Clone(sw.scores),
+	}
+	for _, player := range sw.Players() {
+		ps := PlayerSnapshot{
+			Id:     player.Id,
+			Addr:   sw.addresses[player.Id],
+			Pos:    *player.Pos,
+			Health: player.Health(),
+			Aim:    player.Aim,
+			Score:  sw.scores[player.Id],
+		}
+		for _, kind := range pickups.Kinds {
+			ps.EffectsLeft[kind] = player.EffectLeft(kind, now)
+		}
+		snap.Players = append(snap.Players, ps)
+	}
+	for _, bullet := range sw.Bullets() {
+		snap.Bullets = append(snap.Bullets, BulletSnapshot{bullet.OwnerId, *bullet.Pos, bullet.Size, bullet.Weapon})
+	}
+	for _, pickup := range sw.Pickups() {
+		snap.Pickups = append(snap.Pickups, PickupSnapshot{pickup.Id, *pickup.Pos, pickup.Kind})
+	}
+	return snap
+}
+
+// the player with id, false when it isn't alive
+func (s Snapshot) Player(id uint) (PlayerSnapshot, bool) {
+	i, ok := slices.BinarySearchFunc(s.Players, id, func(ps PlayerSnapshot, id uint) int {
+		return int(ps.Id) - int(id)
+	})
+	if !ok {
+		return PlayerSnapshot{}, false
+	}
+	return s.Players[i], true
+}
+
+// hands the last published snapshot to readers on other goroutines, like the
+// admin api or metrics. the loop that steps the world publishes, anyone reads
+type SnapshotFeed struct {
+	latest atomic.Pointer[Snapshot]
+}
+
+// publishes the world's state as it is now
+func (f *SnapshotFeed) Publish(sw *ServerWorld) {
+	snap := sw.Snapshot()
+	f.latest.Store(&snap)
+}
+
+// the last published snapshot, a copy of its own for every call. false
+// before anything was published
+func (f *SnapshotFeed) Latest() (Snapshot, bool) {
+	snap := f.latest.Load()
+	if snap == nil {
+		return Snapshot{}, false
+	}
+	return Snapshot{
+		Tick:      snap.Tick,
+		Now:       snap.Now,
+		Width:     snap.Width,
+		Height:    snap.Height,
+		Players:   slices.Clone(snap.Players),
+		Bullets:   slices.Clone(snap.Bullets),
+		Pickups:   slices.Clone(snap.Pickups),
+		Addresses: maps.Clone(snap.Addresses),
+		Scores:    maps.Clone(snap.Scores),
+	}, true
+}
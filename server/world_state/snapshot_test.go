@@ -0,0 +1,135 @@
+package worldstate
+
+import (
+	"CircleWar/config"
+	"CircleWar/core/geom"
+	stypes "CircleWar/core/netmsg"
+	"CircleWar/core/pickups"
+	"net"
+	"testing"
+	"time"
+)
+
+var initialHealth = stypes.PlayerHealth(config.Default().Gameplay.InitialPlayerHealth)
+
+type testClock struct {
+	now time.Duration
+}
+
+func (c *testClock) Now() time.Duration {
+	return c.now
+}
+
+func (c *testClock) Advance(dt time.Duration) {
+	c.now += dt
+}
+
+// world with player 1 connected at 500,500
+func newTestWorld() ServerWorld {
+	sw := NewServerWorld(4000, 4000, &testClock{})
+	addr := net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 4000}
+	sw.AddAddress(1, addr)
+	sw.SpawnPlayer(1, geom.NewVector(500, 500), addr)
+	return sw
+}
+
+func TestSnapshot(t *testing.T) {
+	sw := newTestWorld()
+	sw.SpawnPlayer(3, geom.NewVector(700, 500), net.UDPAddr{})
+	sw.SpawnPlayer(2, geom.NewVector(600, 500), net.UDPAddr{})
+	sw.AddKill(2)
+	sw.AddPickup(geom.NewVector(10, 20), pickups.Shield)
+	player, _ := sw.LookupPlayer(2)
+	player.Pickup(pickups.SpeedBoost, sw.Now())
+	sw.AdvanceTime(time.Second)
+	sw.NextTick()
+
+	snap := sw.Snapshot()
+	if snap.Tick != 1 || snap.Now != time.Second || snap.Width != 4000 {
+		t.Errorf("got tick %d at %s", snap.Tick, snap.Now)
+	}
+	for i, id := range []uint{1, 2, 3} {
+		if snap.Players[i].Id != id {
+			t.Fatalf("players aren't sorted by id: %+v", snap.Players)
+		}
+	}
+	got, ok := snap.Player(2)
+	if !ok || got.Pos != geom.NewVector(600, 500) || got.Score != 1 || got.Health != initialHealth ||
+		got.EffectsLeft[pickups.SpeedBoost] != pickups.Get(pickups.SpeedBoost).Duration-time.Second {
+		t.Errorf("got player %+v", got)
+	}
+	if _, ok := snap.Player(4); ok {
+		t.Error("got a player that isn't there")
+	}
+	if len(snap.Pickups) != 1 || snap.Pickups[0].Kind != pickups.Shield || len(snap.Addresses) != 1 {
+		t.Errorf("got pickups %+v and addresses %v", snap.Pickups, snap.Addresses)
+	}
+
+	// nothing in the snapshot points into the world
+	*player.Pos = geom.NewVector(0, 0)
+	sw.AddKill(2)
+	sw.AddAddress(2, net.UDPAddr{})
+	if again, _ := snap.Player(2); again.Pos != got.Pos || snap.Scores[2] != 1 || len(snap.Addresses) != 1 {
+		t.Error("snapshot changed with the world")
+	}
+}
+
+// readers on other goroutines only ever see whole ticks, run with -race
+func TestSnapshotFeed(t *testing.T) {
+	sw := newTestWorld()
+	feed := &SnapshotFeed{}
+	if _, ok := feed.Latest(); ok {
+		t.Fatal("got a snapshot before anything was published")
+	}
+	feed.Publish(&sw)
+
+	done := make(chan struct{})
+	errs := make(chan string, 4)
+	for range 4 {
+		go func() {
+			for {
+				select {
+				case <-done:
+					errs <- ""
+					return
+				default:
+				}
+				snap, _ := feed.Latest()
+				if len(snap.Players) != 1 || len(snap.Addresses) != 1 {
+					errs <- "snapshot of a half stepped world"
+					return
+				}
+				// a copy of its own, changing it can't reach the other readers
+				snap.Players[0].Health = 0
+				delete(snap.Addresses, 1)
+			}
+		}()
+	}
+
+	player, _ := sw.LookupPlayer(1)
+	for range 200 {
+		player.Pos.X++
+		sw.AdvanceTime(time.Second / 60)
+		sw.NextTick()
+		feed.Publish(&sw)
+	}
+	close(done)
+	for range 4 {
+		if err := <-errs; err != "" {
+			t.Fatal(err)
+		}
+	}
+
+	snap, _ := feed.Latest()
+	if snap.Tick != 200 {
+		t.Errorf("got tick %d want 200", snap.Tick)
+	}
+	got, ok := snap.Player(1)
+	if !ok || got.Pos != *player.Pos || got.Health != initialHealth {
+		t.Errorf("got player %+v, the world has %s", got, *player.Pos)
+	}
+	*player.Pos = geom.NewVector(0, 0)
+	if again, _ := feed.Latest(); again.Players[0].Pos != got.Pos {
+		t.Error("snapshot changed with the world")
+	}
+}
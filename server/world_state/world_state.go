@@ -6,93 +6,230 @@ import (
 	"CircleWar/core/hitboxes"
 	"CircleWar/core/netmsg"
 	stypes "CircleWar/core/netmsg"
+	"CircleWar/core/pickups"
+	"CircleWar/core/weapons"
+	"CircleWar/server/entity"
+	"maps"
 	"net"
+	"slices"
+	"sort"
 	"time"
 )
 
+// the world reads time only through its clock, so it can be simulated
+// faster or slower than real time
+type Clock interface {
+	Now() time.Duration
+	Advance(dt time.Duration)
+}
+
+// what a player has besides its position and health
 type PlayerState struct {
-	LastBulletShot time.Time
-	Pos            geom.Vector2
-	health         stypes.PlayerHealth
-	Addr           net.UDPAddr
-	Id             uint
+	LastBulletShot time.Duration
+	// cooldown of the weapon that shot last
+	ShotCooldown time.Duration
+	LastDash     time.Duration
+	DashDir      geom.Direction
+	Aim          float32 // radians
+	Addr         net.UDPAddr
+	Id           uint
+	// when each kind's effect runs out, indexed by pickups.Kind
+	EffectEnds [pickups.NumKinds]time.Duration
+}
+
+func NewPlayerState(id uint, addr net.UDPAddr, now time.Duration) PlayerState {
+	return PlayerState{
+		LastBulletShot: now,
+		ShotCooldown:   weapons.Get(weapons.Pistol).Cooldown,
+		// players can dash right away
+		LastDash: now - config.Current().DashCooldown(),
+		Addr:     addr,
+		Id:       id,
+	}
+}
+
+// time until the player can dash again
+func (ps PlayerState) DashCooldownLeft(now time.Duration) time.Duration {
+	return max(0, ps.LastDash+config.Current().DashCooldown()-now)
+}
+
+func (ps PlayerState) Dashing(now time.Duration) bool {
+	return now-ps.LastDash < config.Current().DashDuration()
 }
 
-func (ps PlayerState) Health() stypes.PlayerHealth {
-	return ps.health
+// bullets pass through players at the start of a dash
+func (ps PlayerState) Invulnerable(now time.Duration) bool {
+	return now-ps.LastDash < config.Current().DashInvulnerable()
 }
 
-func (ps *PlayerState) ChangeHealth(by int) {
-	ps.health += stypes.PlayerHealth(by)
+// how long the effect of kind still runs, 0 when it isn't running
+func (ps PlayerState) EffectLeft(kind pickups.Kind, now time.Duration) time.Duration {
+	return max(0, ps.EffectEnds[kind]-now)
 }
 
-var nextPlayerId uint = uint(1)
+func (ps PlayerState) HasEffect(kind pickups.Kind, now time.Duration) bool {
+	return ps.EffectLeft(kind, now) > 0
+}
 
-func NewPlayerState(pos geom.Vector2, addr net.UDPAddr) PlayerState {
-	ps := PlayerState{time.Now(), pos, config.InitialPlayerHealth, addr, nextPlayerId}
-	nextPlayerId += 1
-	return ps
+// damage taken off every hit the player takes
+func (ps PlayerState) Armor(now time.Duration) int {
+	if ps.HasEffect(pickups.Armor, now) {
+		return pickups.ArmorReduction
+	}
+	return 0
 }
 
+// a player's components. the pointers go straight to the world's storage,
+// writes through them change the world
+type Player struct {
+	*PlayerState
+	Handle entity.Handle
+	Pos    *geom.Vector2
+	health *stypes.PlayerHealth
+}
+
+func (p Player) Health() stypes.PlayerHealth {
+	return *p.health
+}
+
+func (p Player) ChangeHealth(by int) {
+	*p.health += stypes.PlayerHealth(by)
+}
+
+// applies a picked up kind by its stacking rule
+func (p Player) Pickup(kind pickups.Kind, now time.Duration) {
+	if kind == pickups.Health {
+		*p.health = min(*p.health+pickups.HealthRestore, stypes.PlayerHealth(config.Current().InitialPlayerHealth))
+		return
+	}
+	if kind < 0 || kind >= pickups.NumKinds {
+		return
+	}
+	p.EffectEnds[kind] = now + pickups.Get(kind).Extend(p.EffectLeft(kind, now))
+}
+
+// what a bullet has besides its position, owner and lifetime
 type BulletState struct {
-	OwnerId uint
-	Born    time.Time
-	Pos     geom.Vector2
 	MoveDir geom.Direction
 	Size    float32
+	Weapon  weapons.Type
 }
 
-func NewBulletState(player PlayerState, target geom.Vector2) BulletState {
+func NewBulletState(shooter Player, dir geom.Direction, weapon weapons.Weapon) BulletState {
 	return BulletState{
-		OwnerId: player.Id,
-		Born:    time.Now(),
-		Pos:     player.Pos,
-		MoveDir: geom.NewDir(target.Sub(player.Pos)),
-		Size:    hitboxes.BulletSize(player.Health()),
+		MoveDir: dir,
+		Size:    hitboxes.BulletSize(shooter.Health()) * weapon.SizeScale,
+		Weapon:  weapon.Type,
 	}
 }
 
-type PlayerWants struct {
-	// TODO: think of a better way to do player inputs
-	MoveDirs map[netmsg.Direction]bool //toggle
+type Bullet struct {
+	*BulletState
+	Handle entity.Handle
+	Pos    *geom.Vector2
+	// the life of the player that shot it, OwnerId stays after it's over
+	Owner   entity.Handle
+	OwnerId uint
+	Born    time.Duration
 }
 
-type ServerWorld struct {
-	nextBulletId  int
-	players       map[uint]*PlayerState
-	playerWants   map[uint]*PlayerWants
-	bullets       map[int]*BulletState
-	addresses     map[uint]net.UDPAddr
-	height, width float32
-	tickNum       uint32
+type bulletOwner struct {
+	handle entity.Handle
+	id     uint
 }
 
-func (sw *ServerWorld) RevivePlayer(pid uint) {
-	ps := PlayerState{time.Now(), geom.NewVector(500, 500), config.InitialPlayerHealth, sw.GetAddress(pid), pid}
-	sw.AddPlayerState(ps)
+type PickupState struct {
+	Id   uint32
+	Kind pickups.Kind
 }
 
-func (sw *ServerWorld) InitPlayerWants(pid uint) {
-	sw.playerWants[pid] = &PlayerWants{make(map[stypes.Direction]bool)}
+type Pickup struct {
+	*PickupState
+	Handle entity.Handle
+	Pos    *geom.Vector2
 }
 
-func (sw *ServerWorld) PlayerWants(pid uint) *PlayerWants {
-	return sw.playerWants[pid]
+type PlayerWants struct {
+	// TODO: think of a better way to do player inputs
+	MoveDirs map[netmsg.Direction]bool //toggle
+	// analog movement, added to MoveDirs
+	Move geom.Vector2
 }
 
-func NewServerWorld() ServerWorld {
+func (w PlayerWants) clone() PlayerWants {
+	return PlayerWants{maps.Clone(w.MoveDirs), w.Move}
+}
+
+// players, bullets and pickups are entities of one store, what they have is
+// kept in typed components. players are looked up by their network id, which
+// maps to the handle of the player's current life
+type ServerWorld struct {
+	entities *entity.Store
+	pos      *entity.Component[geom.Vector2]
+	health   *entity.Component[stypes.PlayerHealth]
+	owners   *entity.Component[bulletOwner]
+	born     *entity.Component[time.Duration]
+	players  *entity.Component[PlayerState]
+	wants    *entity.Component[PlayerWants]
+	bullets  *entity.Component[BulletState]
+	pickups  *entity.Component[PickupState]
+
+	playerHandles map[uint]entity.Handle
+	nextPlayerId  uint
+	nextPickupId  uint32
+	// fixed spots pickups spawn at, random ones when empty
+	pickupSpawns []geom.Vector2
+	lastSpawn    time.Duration
+	addresses    map[uint]net.UDPAddr
+	// kills of every player that joined, they outlive the player's lives
+	scores        map[uint]int
+	height, width float32
+	tickNum       uint32
+	clock         Clock
+}
+
+func NewServerWorld(width, height float32, clock Clock) ServerWorld {
+	entities := entity.NewStore()
 	return ServerWorld{
-		nextBulletId: 0,
-		tickNum:      0,
-		players:      make(map[uint]*PlayerState),
-		playerWants:  make(map[uint]*PlayerWants),
-		bullets:      make(map[int]*BulletState),
-		addresses:    make(map[uint]net.UDPAddr),
-		height:       config.WorldHeight,
-		width:        config.WorldWidth,
+		entities:      entities,
+		pos:           entity.NewComponent[geom.Vector2](entities),
+		health:        entity.NewComponent[stypes.PlayerHealth](entities),
+		owners:        entity.NewComponent[bulletOwner](entities),
+		born:          entity.NewComponent[time.Duration](entities),
+		players:       entity.NewComponent[PlayerState](entities),
+		wants:         entity.NewComponent[PlayerWants](entities),
+		bullets:       entity.NewComponent[BulletState](entities),
+		pickups:       entity.NewComponent[PickupState](entities),
+		playerHandles: make(map[uint]entity.Handle),
+		nextPlayerId:  1,
+		addresses:     make(map[uint]net.UDPAddr),
+		scores:        make(map[uint]int),
+		height:        height,
+		width:         width,
+		clock:         clock,
 	}
 }
 
+// deep copy of the world that runs on clock from now on
+func (sw *ServerWorld) Clone(clock Clock) ServerWorld {
+	clone := *sw
+	clone.clock = clock
+	clone.entities = sw.entities.Clone()
+	clone.pos = sw.pos.Clone(clone.entities, nil)
+	clone.health = sw.health.Clone(clone.entities, nil)
+	clone.owners = sw.owners.Clone(clone.entities, nil)
+	clone.born = sw.born.Clone(clone.entities, nil)
+	clone.players = sw.players.Clone(clone.entities, nil)
+	clone.wants = sw.wants.Clone(clone.entities, PlayerWants.clone)
+	clone.bullets = sw.bullets.Clone(clone.entities, nil)
+	clone.pickups = sw.pickups.Clone(clone.entities, nil)
+	clone.playerHandles = maps.Clone(sw.playerHandles)
+	clone.pickupSpawns = slices.Clone(sw.pickupSpawns)
+	clone.addresses = maps.Clone(sw.addresses)
+	clone.scores = maps.Clone(sw.scores)
+	return clone
+}
+
 func (sw *ServerWorld) Width() float32 {
 	return sw.width
 }
@@ -109,73 +246,208 @@ func (sw *ServerWorld) Tick() uint32 {
 	return sw.tickNum
 }
 
+func (sw *ServerWorld) SetTick(tick uint32) {
+	sw.tickNum = tick
+}
+
+func (sw *ServerWorld) Now() time.Duration {
+	return sw.clock.Now()
+}
+
+func (sw *ServerWorld) AdvanceTime(dt time.Duration) {
+	sw.clock.Advance(dt)
+}
+
+func (sw *ServerWorld) NewPlayerId() uint {
+	return sw.nextPlayerId
+}
+
 func (sw *ServerWorld) AddAddress(playerId uint, addr net.UDPAddr) {
 	sw.addresses[playerId] = addr
 }
 
+// a copy, changing it doesn't change the world
 func (sw *ServerWorld) AddressSnapshots() map[uint]net.UDPAddr {
-	return sw.addresses
+	return maps.Clone(sw.addresses)
 }
 
 func (sw *ServerWorld) GetAddress(playerId uint) net.UDPAddr {
 	return sw.addresses[playerId]
 }
 
+// false when the player left, or stays only as a spectator
+func (sw *ServerWorld) LookupAddress(playerId uint) (net.UDPAddr, bool) {
+	addr, ok := sw.addresses[playerId]
+	return addr, ok
+}
+
+func (sw *ServerWorld) AddKill(playerId uint) {
+	sw.scores[playerId]++
+}
+
+func (sw *ServerWorld) Score(playerId uint) int {
+	return sw.scores[playerId]
+}
+
 func (sw *ServerWorld) RemovePlayerAddress(playerId uint) {
 	delete(sw.addresses, playerId)
 }
 
-func (sw *ServerWorld) StartPlayerBulletCD(id uint) {
-	playerState := sw.players[id]
-	playerState.LastBulletShot = time.Now()
-	sw.players[id] = playerState
+// a new life of player id at pos, a player that is still alive is replaced
+func (sw *ServerWorld) SpawnPlayer(id uint, pos geom.Vector2, addr net.UDPAddr) Player {
+	sw.RemovePlayer(id)
+	h := sw.entities.New()
+	sw.pos.Set(h, pos)
+	sw.health.Set(h, stypes.PlayerHealth(config.Current().InitialPlayerHealth))
+	sw.players.Set(h, NewPlayerState(id, addr, sw.Now()))
+	sw.wants.Set(h, PlayerWants{make(map[stypes.Direction]bool), geom.Vector2{}})
+	sw.playerHandles[id] = h
+	sw.nextPlayerId = max(sw.nextPlayerId, id+1)
+	player, _ := sw.player(h)
+	return player
+}
+
+func (sw *ServerWorld) RevivePlayer(pid uint) {
+	sw.SpawnPlayer(pid, geom.NewVector(500, 500), sw.GetAddress(pid))
 }
 
-func (sw *ServerWorld) DurSinceLastBullet(id uint) time.Duration {
-	now := time.Now()
-	return now.Sub(sw.players[id].LastBulletShot)
+func (sw *ServerWorld) player(h entity.Handle) (Player, bool) {
+	state, ok := sw.players.Get(h)
+	if !ok {
+		return Player{}, false
+	}
+	pos, _ := sw.pos.Get(h)
+	health, _ := sw.health.Get(h)
+	return Player{state, h, pos, health}, true
 }
 
-func (sw *ServerWorld) PlayerSnapshots() []PlayerState {
-	snapshot := []PlayerState{}
-	for _, state := range sw.players {
-		snapshot = append(snapshot, *state)
+// the current life of player id, false when it's dead or gone
+func (sw *ServerWorld) LookupPlayer(id uint) (Player, bool) {
+	h, ok := sw.playerHandles[id]
+	if !ok {
+		return Player{}, false
 	}
-	return snapshot
+	return sw.player(h)
 }
 
-func (sw *ServerWorld) AddPlayerState(player PlayerState) {
-	sw.players[player.Id] = &player
-	sw.InitPlayerWants(player.Id)
+// the player of a handle, false once that life of the player is over
+func (sw *ServerWorld) PlayerByHandle(h entity.Handle) (Player, bool) {
+	return sw.player(h)
 }
 
 func (sw *ServerWorld) HasPlayer(id uint) bool {
-	_, ok := sw.players[id]
+	_, ok := sw.LookupPlayer(id)
 	return ok
 }
 
-func (sw *ServerWorld) RemovePlayerState(id uint) {
-	delete(sw.players, id)
+func (sw *ServerWorld) RemovePlayer(id uint) {
+	if h, ok := sw.playerHandles[id]; ok {
+		sw.entities.Remove(h)
+		delete(sw.playerHandles, id)
+	}
 }
 
-func (sw *ServerWorld) Player(id uint) *PlayerState {
-	return sw.players[id]
+// living players sorted by id
+func (sw *ServerWorld) Players() []Player {
+	players := []Player{}
+	for _, h := range sw.playerHandles {
+		if player, ok := sw.player(h); ok {
+			players = append(players, player)
+		}
+	}
+	sort.Slice(players, func(i, j int) bool {
+		return players[i].Id < players[j].Id
+	})
+	return players
 }
 
-func (sw *ServerWorld) HasPlayerState(id uint) bool {
-	_, ok := sw.players[id]
-	return ok
+func (sw *ServerWorld) PlayerWants(pid uint) *PlayerWants {
+	wants, _ := sw.wants.Get(sw.playerHandles[pid])
+	return wants
+}
+
+func (sw *ServerWorld) StartPlayerBulletCD(player Player, cooldown time.Duration) {
+	player.LastBulletShot = sw.Now()
+	player.ShotCooldown = cooldown
+}
+
+func (sw *ServerWorld) DurSinceLastBullet(player Player) time.Duration {
+	return sw.Now() - player.LastBulletShot
+}
+
+func (sw *ServerWorld) StartPlayerDash(player Player, dir geom.Direction) {
+	player.LastDash = sw.Now()
+	player.DashDir = dir
+}
+
+// a bullet of shooter's current life at pos, born now
+func (sw *ServerWorld) AddBullet(shooter Player, pos geom.Vector2, bullet BulletState) {
+	h := sw.entities.New()
+	sw.pos.Set(h, pos)
+	sw.owners.Set(h, bulletOwner{shooter.Handle, shooter.Id})
+	sw.born.Set(h, sw.Now())
+	sw.bullets.Set(h, bullet)
+}
+
+// bullets in the order they were shot
+func (sw *ServerWorld) Bullets() []Bullet {
+	bullets := []Bullet{}
+	for _, h := range sw.bullets.Handles() {
+		state, _ := sw.bullets.Get(h)
+		pos, _ := sw.pos.Get(h)
+		owner, _ := sw.owners.Get(h)
+		born, _ := sw.born.Get(h)
+		bullets = append(bullets, Bullet{state, h, pos, owner.handle, owner.id, *born})
+	}
+	return bullets
+}
+
+// removes a bullet, a pickup or a player's life. false when it's already gone
+func (sw *ServerWorld) Remove(h entity.Handle) bool {
+	return sw.entities.Remove(h)
+}
+
+// whether the entity of h still exists
+func (sw *ServerWorld) Alive(h entity.Handle) bool {
+	return sw.entities.Alive(h)
+}
+
+func (sw *ServerWorld) AddPickup(pos geom.Vector2, kind pickups.Kind) {
+	h := sw.entities.New()
+	sw.pos.Set(h, pos)
+	sw.pickups.Set(h, PickupState{sw.nextPickupId, kind})
+	sw.nextPickupId++
+}
+
+// pickups in the order they spawned
+func (sw *ServerWorld) Pickups() []Pickup {
+	pickups := []Pickup{}
+	for _, h := range sw.pickups.Handles() {
+		state, _ := sw.pickups.Get(h)
+		pos, _ := sw.pos.Get(h)
+		pickups = append(pickups, Pickup{state, h, pos})
+	}
+	return pickups
+}
+
+// id the next pickup gets
+func (sw *ServerWorld) NextPickupId() uint32 {
+	return sw.nextPickupId
+}
+
+func (sw *ServerWorld) SetPickupSpawns(spawns []geom.Vector2) {
+	sw.pickupSpawns = spawns
 }
 
-func (sw *ServerWorld) AddBulletState(bullet BulletState) {
-	sw.bullets[sw.nextBulletId] = &bullet
-	sw.nextBulletId++
+func (sw *ServerWorld) PickupSpawns() []geom.Vector2 {
+	return sw.pickupSpawns
 }
 
-func (sw *ServerWorld) BulletSnapshots() map[int]*BulletState {
-	return sw.bullets
+// the last time a pickup spawned
+func (sw *ServerWorld) LastPickupSpawn() time.Duration {
+	return sw.lastSpawn
 }
 
-func (sw *ServerWorld) RemoveBullet(id int) {
-	delete(sw.bullets, id)
+func (sw *ServerWorld) SetLastPickupSpawn(at time.Duration) {
+	sw.lastSpawn = at
 }